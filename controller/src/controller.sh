@@ -37,6 +37,9 @@ env | sort
 # - STORING_ON_SUCCESS: uploading results; no errors so far; expect to transition to COMPLETED state after
 # - STORING_ON_FAILURE: uploading results; had errors already; expect to transition to FAILED state after
 # - STORING_ON_HALTED: uploading results; triggerd by halt command; expect to transition to HALTED state after
+# - LC_WAIT_ON_SUSPEND: job suspended, wait for log-collector to finish; expect to transition to STORING_ON_SUSPENDED
+# - STORING_ON_SUSPENDED: uploading checkpoints and logs; triggered by suspend command; expect to transition to SUSPENDED
+# - SUSPENDED: learners and helper are scaled to zero by LCM; expect to transition to PROCESSING on resume
 # - COMPLETED: final successful state
 # - FAILED: final error state
 state_file="$JOB_STATE_DIR/current_state"
@@ -52,6 +55,9 @@ stateContainers[LC_WAIT_ON_HALTED]=""
 stateContainers[STORING_ON_SUCCESS]="store-results store-logs"
 stateContainers[STORING_ON_FAILURE]="store-results store-logs"
 stateContainers[STORING_ON_HALTED]="store-results store-logs"
+stateContainers[LC_WAIT_ON_SUSPEND]=""
+stateContainers[STORING_ON_SUSPENDED]="store-results store-logs"
+stateContainers[SUSPENDED]=""
 stateContainers[COMPLETED]=""
 stateContainers[FAILED]=""
 stateContainers[FINAL]=""
@@ -59,13 +65,17 @@ stateContainers[FINAL]=""
 # The presence of this file indicates a halt has been requested.
 halt_file="$JOB_STATE_DIR/halt"
 
+# The presence of this file indicates a suspend has been requested.
+suspend_file="$JOB_STATE_DIR/suspend"
+
 lc_exit_file="$JOB_STATE_DIR/lc.exit"
 
 # Note that associative arrays are for bash 4 only
 declare -A lc_transitions
 lc_transitions[LC_WAIT_ON_SUCCESS]=STORING_ON_SUCCESS
 lc_transitions[LC_WAIT_ON_FAILURE]=STORING_ON_FAILURE
-lc_transitions[LC_WAIT_ON_HALT]=STORING_ON_HALT
+lc_transitions[LC_WAIT_ON_HALT]=STORING_ON_HALTED
+lc_transitions[LC_WAIT_ON_SUSPEND]=STORING_ON_SUSPENDED
 
 user_log_file="$JOB_STATE_DIR/logs/training-log.txt"
 
@@ -105,6 +115,22 @@ function checkForHaltZNode() {
     fi
 }
 
+# Check for creation of the /ZK_DIR/TRAINING_ID/suspend znode, a job can be suspended again after it was resumed
+function checkForSuspendZNode() {
+    until [[ -f "$suspend_file" ]]; do
+        infinite_exp_backoff runEtcdCommand watch "$JOB_BASE_PATH/suspend"
+        if suspendZNodeExists ; then
+            touch "$suspend_file"
+        fi
+    done
+}
+
+# Returns zero while the /ZK_DIR/TRAINING_ID/suspend znode exists, LCM deletes it to resume the job
+function suspendZNodeExists() {
+    ZNODE_PATH="$JOB_BASE_PATH/suspend"
+    runEtcdCommand get $ZNODE_PATH --keys-only | grep -qx "$ZNODE_PATH"
+}
+
 # Set $current_state variable to the current state
 function getState() {
     current_state=$(cat "$state_file")
@@ -195,6 +221,7 @@ echo "Initiating job" >> $user_log_file
 # State machine loop
 init
 checkForHaltZNode &
+checkForSuspendZNode &
 while true; do
     getState
     if [[ $current_state != $previous_state ]]; then
@@ -250,6 +277,7 @@ while true; do
             # PROCESSING -> STORING_ON_SUCCESS if learner succeeds
             # PROCESSING -> STORING_ON_FAILURE if learner fails
             # PROCESSING -> HALTED if halt triggered
+            # PROCESSING -> SUSPENDED if suspend triggered
 
             getExitCode learner; learner_exit_code=$exit_code
             [[ -z "$learner_exit_code" ]] || echo "learner exit: $learner_exit_code"
@@ -270,9 +298,13 @@ while true; do
                 echo "halt: learner_exit_code: $learner_exit_code" >> $user_log_file
                 start_lc_wait=`date +%s`
                 setState LC_WAIT_ON_HALT
+            elif [[ -f "$suspend_file" ]]; then
+                echo "Suspending: storing checkpoints and logs" >> $user_log_file
+                start_lc_wait=`date +%s`
+                setState LC_WAIT_ON_SUSPEND
             fi
             ;;
-        (LC_WAIT_ON_SUCCESS | LC_WAIT_ON_FAILURE | LC_WAIT_ON_HALT)
+        (LC_WAIT_ON_SUCCESS | LC_WAIT_ON_FAILURE | LC_WAIT_ON_HALT | LC_WAIT_ON_SUSPEND)
             end_lc_wait=`date +%s`
             duration_wait=$((end_lc_wait-start_lc_wait))
             if [[ -f "$lc_exit_file" ]]; then
@@ -338,6 +370,51 @@ while true; do
                 setState FAILED
             fi
             ;;
+        (STORING_ON_SUSPENDED)
+            # STORING_ON_SUSPENDED -> SUSPENDED once storing is done, a failed store does not fail the job
+            # STORING_ON_SUSPENDED -> PROCESSING if LCM gave up on the suspend and deleted the suspend znode
+            getExitCode store-results; store_results_exit_code=$exit_code
+            if [[ "$LEARNER_SYNCS_LOGS" == "true" ]]; then
+                # The learner only stores its logs after training ended, ask its log sync for a final sync instead.
+                startContainer suspend-sync
+                getExitCode suspend-sync; store_logs_exit_code=$exit_code
+            else
+                getExitCode store-logs; store_logs_exit_code=$exit_code
+            fi
+            [[ -z "$store_results_exit_code" ]] || echo "store-results exit: $store_results_exit_code"
+            [[ -z "$store_logs_exit_code" ]] || echo "store-logs exit: $store_logs_exit_code"
+
+            if ! suspendZNodeExists ; then
+                echo "Suspend abandoned: continuing training" >> $user_log_file
+                # Containers that did not store yet must not start now, the ones that did keep their exit code.
+                rm -f "$suspend_file" "$JOB_STATE_DIR"/store-results.start "$JOB_STATE_DIR"/store-logs.start "$JOB_STATE_DIR"/suspend-sync.*
+                checkForSuspendZNode &
+                setState PROCESSING
+            elif [[ ! -z "$store_results_exit_code" && ! -z "$store_logs_exit_code" ]]; then
+                echo "Suspended: store_results_exit_code: $store_results_exit_code, store_logs_exit_code: $store_logs_exit_code" >> $user_log_file
+                # Reset the control files of the storing phase, so results are stored again when the resumed job ends
+                # and the learner waits for the controller after the resume.
+                rm -f "$JOB_STATE_DIR"/store-results.* "$JOB_STATE_DIR"/store-logs.* "$JOB_STATE_DIR"/suspend-sync.* "$lc_exit_file" "$JOB_STATE_DIR/learner.start"
+                recordStatus PROCESSING "" "suspended"
+                setState SUSPENDED
+                # Tell LCM the learners and helper can be scaled to zero.
+                infinite_exp_backoff runEtcdCommand put "$JOB_BASE_PATH/suspended" "$(date +%s)"
+            fi
+            ;;
+        (SUSPENDED)
+            # SUSPENDED -> PROCESSING once LCM deleted the suspend znode
+            if ! suspendZNodeExists ; then
+                echo "Resumed: restarting from checkpoint" >> $user_log_file
+                rm -f "$suspend_file"
+                # A suspend that LCM gave up on must not leave the marker behind for the next suspend.
+                infinite_exp_backoff runEtcdCommand del "$JOB_BASE_PATH/suspended"
+                checkForSuspendZNode &
+                recordStatus PROCESSING "" "resumed"
+                setState PROCESSING
+            else
+                sleep 10
+            fi
+            ;;
         (COMPLETED)
             echo "Calling cleanup (COMPLETED)"
             cleanup
@@ -346,6 +346,10 @@ func (instance *coordinator) GrantExpiringLease(leaseTimeout int64, log *logger.
 func (instance *coordinator) RefreshLease(leaseID clientv3.LeaseID, log *logger.LocLoggingEntry) (*clientv3.LeaseKeepAliveResponse, error) {
 	//prod once to keep alive
 	leaseResponse, kaerr := instance.cli.KeepAliveOnce(context.TODO(), leaseID)
+	if kaerr != nil {
+		log.WithError(kaerr).Errorf("Failed to refresh lease %d", leaseID)
+		return nil, kaerr
+	}
 	log.Debugf("Refreshed lease for id %d and got the refreshed lease with id %d and TTL: %d ", leaseID, leaseResponse.ID, leaseResponse.TTL)
 	return leaseResponse, kaerr
 }
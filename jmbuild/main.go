@@ -49,6 +49,13 @@ func main() {
 	if err != nil {
 		logr.WithError(err).Errorf("failed to bring up job monitor for training %s, already must have signaled to kill the jm", trainingID)
 	} else {
+		replicaGroups, err := jobM.ParseReplicaGroups(os.Getenv("REPLICA_GROUPS"))
+		if err != nil {
+			logr.WithError(err).Errorf("ignoring the replica groups of training %s, waiting for all learners", trainingID)
+		}
+		jm.ReplicaGroups = replicaGroups
+		jm.CompletionRoles = jobM.ParseCompletionRoles(os.Getenv("COMPLETION_ROLES"))
+
 		logr.Infof("Job Monitor instantiated and ready to go. Starting to manage %s", jm.TrainingID)
 
 		go jm.ManageDistributedJob(logr)
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jobmonitor
+
+import (
+	"strconv"
+
+	"github.com/AISphere/ffdl-commons/logger"
+)
+
+const zkTotLearners = "total_learners"
+
+func totalLearnersPath(trainingID string) string {
+	return trainingID + "/" + zkLearners + "/" + zkTotLearners
+}
+
+//refreshNumLearners picks up the number of learners written by LCM when an elastic training is scaled
+func (jm *JobMonitor) refreshNumLearners(processed map[int]int, logr *logger.LocLoggingEntry) {
+	response, err := jm.EtcdClient.Get(totalLearnersPath(jm.TrainingID), logr)
+	if err != nil || len(response) == 0 {
+		jm.metrics.FailedETCDConnectivityCounter.Add(1)
+		logr.Errorf("Job Monitor could not get the number of learners from %s, keeping %d learners", totalLearnersPath(jm.TrainingID), jm.NumLearners)
+		return
+	}
+	numLearners, err := strconv.Atoi(response[0].Value)
+	if err != nil || numLearners < 1 {
+		logr.Warnf("Invalid number of learners %q at %s, keeping %d learners", response[0].Value, totalLearnersPath(jm.TrainingID), jm.NumLearners)
+		return
+	}
+	if numLearners != jm.NumLearners {
+		logr.Infof("Number of learners of training %s changed from %d to %d", jm.TrainingID, jm.NumLearners, numLearners)
+		jm.resizeLearners(numLearners, processed)
+	}
+}
+
+//resizeLearners changes the learners whose status is polled. The processed updates of removed learners are kept,
+//so the statuses a learner wrote before a scale down are not processed again when it comes back with a scale up
+func (jm *JobMonitor) resizeLearners(numLearners int, processed map[int]int) {
+	for i := 1; i <= numLearners; i++ {
+		if _, known := processed[i]; !known {
+			processed[i] = 0
+		}
+	}
+	jm.NumLearners = numLearners
+}
@@ -76,10 +76,13 @@ type JobMonitor struct {
 	UserID                string
 	JobName               string
 	NumLearners           int
-	trMap                 map[string]([]string)
-	numTerminalLearners   uint64
-	metrics               *jobMonitorMetrics
-	EtcdClient            coord.Coordinator
+	//ReplicaGroups and CompletionRoles are set for trainings with heterogeneous replica groups
+	ReplicaGroups       []ReplicaGroup
+	CompletionRoles     []string
+	trMap               map[string]([]string)
+	numTerminalLearners uint64
+	metrics             *jobMonitorMetrics
+	EtcdClient          coord.Coordinator
 }
 
 // count etcd progress notifications (arrive every 10 mins)
@@ -109,11 +112,13 @@ func NewJobMonitor(trainingID string, userID string, numLearners int, jobName st
 		jmMetrics.FailedK8sConnectivityCounter.Add(1)
 		logr.WithError(err).Errorf("Failed to connect to k8s while creating new lcm service for training %s", trainingID)
 
-		if err := updateJobStatusOnError(trainingID, userID, client.ErrCodeK8SConnection, service.StatusMessages_INTERNAL_ERROR.String(), logr, jmMetrics); err != nil {
-			logr.WithError(err).Errorf("Failed to write the status %s for training %s to trainer", grpc_trainer_v2.Status_FAILED, trainingID)
-		}
-		if err := KillDeployedJob(trainingID, userID, jobName, logr); err != nil {
-			logr.WithError(err).Errorf("Failed to kill the deployed job %s", trainingID)
+		if !requestRedeploy(trainingID, userID, jobName, client.ErrCodeK8SConnection, err.Error(), logr) {
+			if err := updateJobStatusOnError(trainingID, userID, client.ErrCodeK8SConnection, service.StatusMessages_INTERNAL_ERROR.String(), logr, jmMetrics); err != nil {
+				logr.WithError(err).Errorf("Failed to write the status %s for training %s to trainer", grpc_trainer_v2.Status_FAILED, trainingID)
+			}
+			if err := KillDeployedJob(trainingID, userID, jobName, logr); err != nil {
+				logr.WithError(err).Errorf("Failed to kill the deployed job %s", trainingID)
+			}
 		}
 		return nil, fmt.Errorf("Failed to connect to k8s")
 	}
@@ -214,6 +219,8 @@ func (jm *JobMonitor) monitorJob(logr *logger.LocLoggingEntry) {
 	ticker := time.NewTicker(1 * time.Minute)
 	for range ticker.C {
 
+		jm.refreshNumLearners(processed, logr)
+		jm.refreshStaticVolumeLease(logr)
 		for i := 1; i <= jm.NumLearners; i++ {
 			seqName := indvidualJobStatusPath(jm.TrainingID, i)
 			seq := jm.EtcdClient.NewValueSequence(seqName, logr)
@@ -262,12 +269,12 @@ func (jm *JobMonitor) processUpdateJobStatus(currStatus string, logr *logger.Loc
 			return markComplete
 		}
 		//Job has completed, now wait 1 minute for all learners to upload logs and clean themselves up
-		if atomic.LoadUint64(&jm.numTerminalLearners) < uint64(jm.NumLearners) {
+		if atomic.LoadUint64(&jm.numTerminalLearners) < uint64(jm.learnersToAwait()) {
 			logr.Debugf("(processUpdateJobStatus) Sleeping for 60s to allow all remaining learners to complete")
 			time.Sleep(60 * time.Second)
 		}
 		// check if they cleaned themselves up, and log it.  Teardown happens either way.
-		if atomic.LoadUint64(&jm.numTerminalLearners) < uint64(jm.NumLearners) {
+		if atomic.LoadUint64(&jm.numTerminalLearners) < uint64(jm.learnersToAwait()) {
 			logr.Debugf("(processUpdateJobStatus) Killing remaining learners in %s", jm.TrainingID)
 		} else {
 			logr.Debugf("(processUpdateJobStatus) All learners of %s have completed. It can now be safely killed", jm.TrainingID)
@@ -433,6 +440,9 @@ func coordinator(logr *logger.LocLoggingEntry) (coord.Coordinator, error) {
 func shutdownTrainingOnETCDFailure(trainingID, userID, jobName string, err error, logr *logger.LocLoggingEntry, jmMetrics *jobMonitorMetrics) {
 
 	logr.WithError(err).Error("failed to connect to etcd while monitoring training and shutting down the job")
+	if requestRedeploy(trainingID, userID, jobName, client.ErrCodeEtcdConnection, err.Error(), logr) {
+		return
+	}
 	if err := updateJobStatusOnError(trainingID, userID, client.ErrCodeEtcdConnection, service.StatusMessages_INTERNAL_ERROR.String(), logr, jmMetrics); err != nil {
 		logr.WithError(err).Errorf("Failed to write the status %s for training %s to trainer", grpc_trainer_v2.Status_FAILED, trainingID)
 	}
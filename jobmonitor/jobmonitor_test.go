@@ -105,3 +105,38 @@ func initJobMonitor() *JobMonitor {
 	}
 	return jm
 }
+
+func TestReplicaGroups(t *testing.T) {
+	groups, err := ParseReplicaGroups("chief:1,worker:3,ps:2")
+	assert.NoError(t, err)
+	assert.Equal(t, []ReplicaGroup{{"chief", 1}, {"worker", 3}, {"ps", 2}}, groups)
+
+	_, err = ParseReplicaGroups("chief")
+	assert.Error(t, err)
+
+	groups, err = ParseReplicaGroups("")
+	assert.NoError(t, err)
+	assert.Empty(t, groups)
+
+	jm := &JobMonitor{NumLearners: 6}
+	assert.Equal(t, 6, jm.learnersToAwait())
+	jm.ReplicaGroups, _ = ParseReplicaGroups("chief:1,worker:3,ps:2")
+	jm.CompletionRoles = ParseCompletionRoles("chief, worker")
+	assert.Equal(t, 4, jm.learnersToAwait())
+}
+
+func TestResizeLearners(t *testing.T) {
+	jm := &JobMonitor{NumLearners: 2}
+	processed := map[int]int{1: 3, 2: 1}
+
+	jm.resizeLearners(4, processed)
+	assert.Equal(t, 4, jm.NumLearners)
+	assert.Equal(t, map[int]int{1: 3, 2: 1, 3: 0, 4: 0}, processed)
+
+	processed[4] = 2
+	jm.resizeLearners(1, processed)
+	assert.Equal(t, 1, jm.NumLearners)
+
+	jm.resizeLearners(4, processed)
+	assert.Equal(t, 2, processed[4])
+}
@@ -17,6 +17,7 @@
 package jobmonitor
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/AISphere/ffdl-commons/config"
@@ -34,11 +35,17 @@ func (jm *JobMonitor) checkIfJobStarted(logr *logger.LocLoggingEntry) {
 	logr.Debugf("(Job Monitor checkIfJobStarted) Checking if there are kubernetes learner PODS associated with training job %s", jm.TrainingID)
 
 	for i := 1; i <= insuffResourcesRetries; i++ {
+		if jm.isSuspended(logr) {
+			logr.Infof("(Job Monitor checkIfJobStarted) Training job %s was suspended, no longer checking its pods", jm.TrainingID)
+			return
+		}
+
 		pods, err := jm.k8sClient.Core().Pods(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: selector})
 
 		numPending := 0
 		numRunning := 0
 		numFailed := 0
+		failedReason := ""
 
 		numPodsExpected := jm.NumLearners + 2 //1 helper plus 1 job monitor
 
@@ -78,6 +85,7 @@ func (jm *JobMonitor) checkIfJobStarted(logr *logger.LocLoggingEntry) {
 					logr.Debugf("(Job Monitor checkIfJobStarted) Job %s seems to have a failed pod %s", jm.TrainingID, pod.ObjectMeta.Name)
 					logr.Debugf("(Job Monitor checkIfJobStarted) Pod status message is %s Reason is %s", pod.Status.Message, pod.Status.Reason)
 					numFailed++
+					failedReason = fmt.Sprintf("pod %s failed: %s %s", pod.ObjectMeta.Name, pod.Status.Reason, pod.Status.Message)
 
 					containerStatuses := pod.Status.ContainerStatuses
 					for _, containerStatus := range containerStatuses {
@@ -111,6 +119,9 @@ func (jm *JobMonitor) checkIfJobStarted(logr *logger.LocLoggingEntry) {
 		}
 
 		if numFailed >= 1 && i == insuffResourcesRetries {
+			if requestRedeploy(jm.TrainingID, jm.UserID, jm.JobName, trainerClient.ErrFailedPodReasonUnknown, failedReason, logr) {
+				return
+			}
 			updateJobStatusOnError(jm.TrainingID, jm.UserID, trainerClient.ErrFailedPodReasonUnknown, service.StatusMessages_INTERNAL_ERROR.String(), logr, jm.metrics)
 			KillDeployedJob(jm.TrainingID, jm.UserID, jm.JobName, logr)
 		}
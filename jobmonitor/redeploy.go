@@ -0,0 +1,64 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jobmonitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+
+	"github.com/cenkalti/backoff"
+
+	lcmClient "github.com/AISphere/ffdl-lcm/service/client"
+)
+
+//requestRedeploy asks LCM to redeploy a training that failed with an infrastructure error instead of failing it.
+//False if LCM did not redeploy it, then the training has to be failed and killed as before
+func requestRedeploy(trainingID string, userID string, jobName string, errorCode string, reason string, logr *logger.LocLoggingEntry) bool {
+	logr.Infof("(requestRedeploy) Asking LCM to redeploy training %s after error %s: %s", trainingID, errorCode, reason)
+	redeployReq := &service.JobRedeployRequest{Name: jobName, TrainingId: trainingID, UserId: userID, ErrorCode: errorCode, Reason: reason}
+	lcm, err := lcmClient.NewLcm(nil)
+	if err != nil {
+		logr.Errorln("(requestRedeploy) Cannot create lcm service client: ", err.Error())
+		return false
+	}
+	defer lcm.Close()
+
+	defaultBackoff := backoff.NewExponentialBackOff()
+	defaultBackoff.MaxElapsedTime = 1 * time.Minute
+	defaultBackoff.MaxInterval = 5 * time.Second
+
+	var response *service.JobRedeployResponse
+	err = backoff.Retry(func() error {
+		response, err = lcm.Client().RedeployTrainingJob(context.Background(), redeployReq)
+		if err != nil {
+			logr.WithError(err).Errorf("Failed to send redeploy request for Training Job %s to LCM. Retrying", trainingID)
+		}
+		return err
+	}, defaultBackoff)
+	if err != nil {
+		logr.WithError(err).Errorf("(requestRedeploy) Failed to send redeploy request for Training Job %s to LCM. Already retried several times.", trainingID)
+		return false
+	}
+
+	if response.Redeployed {
+		logr.Infof("(requestRedeploy) LCM is redeploying training %s, attempt %d", trainingID, response.Attempt)
+	}
+	return response.Redeployed
+}
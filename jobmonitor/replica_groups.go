@@ -0,0 +1,77 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jobmonitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//ReplicaGroup ... a group of learners of the training with the same role, e.g. chief, worker or ps
+type ReplicaGroup struct {
+	Name     string
+	Replicas int
+}
+
+//ParseReplicaGroups parses the REPLICA_GROUPS env var set by LCM, e.g. "chief:1,worker:3,ps:2"
+func ParseReplicaGroups(value string) ([]ReplicaGroup, error) {
+	var groups []ReplicaGroup
+	for _, group := range strings.Split(value, ",") {
+		if strings.TrimSpace(group) == "" {
+			continue
+		}
+		parts := strings.SplitN(group, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("replica group %q is not of the form name:replicas", group)
+		}
+		replicas, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("replica group %q has an invalid number of replicas: %v", group, err)
+		}
+		groups = append(groups, ReplicaGroup{Name: strings.TrimSpace(parts[0]), Replicas: replicas})
+	}
+	return groups, nil
+}
+
+//ParseCompletionRoles parses the COMPLETION_ROLES env var set by LCM, e.g. "chief"
+func ParseCompletionRoles(value string) []string {
+	var roles []string
+	for _, role := range strings.Split(value, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+//learnersToAwait is the number of learners that have to terminate before the training is torn down.
+//Only the completion roles end a training with replica groups, the other groups (e.g. ps) run until they are killed
+func (jm *JobMonitor) learnersToAwait() int {
+	if len(jm.ReplicaGroups) == 0 || len(jm.CompletionRoles) == 0 {
+		return jm.NumLearners
+	}
+	learners := 0
+	for _, g := range jm.ReplicaGroups {
+		for _, role := range jm.CompletionRoles {
+			if g.Name == role {
+				learners += g.Replicas
+			}
+		}
+	}
+	return learners
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jobmonitor
+
+import (
+	"strconv"
+
+	"github.com/AISphere/ffdl-commons/logger"
+
+	"github.com/coreos/etcd/clientv3"
+)
+
+//lcm records the lease of the static volume of the training here, see leaseStaticVolume
+const zkStaticVolumeLease = "static_volume_lease"
+
+//refreshStaticVolumeLease keeps the static volume leased for as long as the job monitor of the training runs
+func (jm *JobMonitor) refreshStaticVolumeLease(logr *logger.LocLoggingEntry) {
+	response, err := jm.EtcdClient.Get(jm.TrainingID+"/"+zkStaticVolumeLease, logr)
+	if err != nil {
+		jm.metrics.FailedETCDConnectivityCounter.Add(1)
+		logr.WithError(err).Errorf("Job Monitor could not get the static volume lease of training %s", jm.TrainingID)
+		return
+	}
+	if len(response) == 0 {
+		return
+	}
+	leaseID, err := strconv.ParseInt(response[0].Value, 10, 64)
+	if err != nil {
+		logr.WithError(err).Errorf("Invalid static volume lease %s of training %s", response[0].Value, jm.TrainingID)
+		return
+	}
+	if _, err := jm.EtcdClient.RefreshLease(clientv3.LeaseID(leaseID), logr); err != nil {
+		jm.metrics.FailedETCDConnectivityCounter.Add(1)
+		logr.WithError(err).Errorf("Job Monitor could not refresh the static volume lease of training %s", jm.TrainingID)
+	}
+}
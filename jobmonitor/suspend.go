@@ -0,0 +1,39 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jobmonitor
+
+import (
+	"github.com/AISphere/ffdl-commons/logger"
+)
+
+const zkSuspend = "suspend"
+
+func suspendPath(trainingID string) string {
+	return trainingID + "/" + zkSuspend
+}
+
+//isSuspended is true from SuspendTrainingJob until ResumeTrainingJob, the learners and helper of a suspended
+//training are scaled to zero on purpose and must not be taken for pods that failed to start
+func (jm *JobMonitor) isSuspended(logr *logger.LocLoggingEntry) bool {
+	response, err := jm.EtcdClient.Get(suspendPath(jm.TrainingID), logr)
+	if err != nil {
+		jm.metrics.FailedETCDConnectivityCounter.Add(1)
+		logr.WithError(err).Errorf("Job Monitor could not check if training %s is suspended", jm.TrainingID)
+		return false
+	}
+	return len(response) > 0
+}
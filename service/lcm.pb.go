@@ -17,6 +17,18 @@ It has these top-level messages:
 	JobKillResponse
 	JobHaltRequest
 	JobHaltResponse
+	ReplicaGroup
+	JobScaleRequest
+	JobScaleResponse
+	JobSuspendRequest
+	JobSuspendResponse
+	JobResumeRequest
+	JobResumeResponse
+	JobRedeployRequest
+	JobRedeployResponse
+	DatasetVolume
+	InputDataset
+	HelperResources
 */
 package service
 
@@ -111,6 +123,8 @@ type ResourceRequirements struct {
 	Storage      float64                         `protobuf:"fixed64,9,opt,name=storage" json:"storage,omitempty"`
 	StorageUnit  ResourceRequirements_MemoryUnit `protobuf:"varint,10,opt,name=storage_unit,json=storageUnit,enum=service.ResourceRequirements_MemoryUnit" json:"storage_unit,omitempty"`
 	GpuType      string                          `protobuf:"bytes,11,opt,name=gpu_type,json=gpuType" json:"gpu_type,omitempty"`
+	MinLearners  int32                           `protobuf:"varint,12,opt,name=min_learners,json=minLearners" json:"min_learners,omitempty"`
+	MaxLearners  int32                           `protobuf:"varint,13,opt,name=max_learners,json=maxLearners" json:"max_learners,omitempty"`
 }
 
 func (m *ResourceRequirements) Reset()                    { *m = ResourceRequirements{} }
@@ -195,6 +209,20 @@ func (m *ResourceRequirements) GetGpuType() string {
 	return ""
 }
 
+func (m *ResourceRequirements) GetMinLearners() int32 {
+	if m != nil {
+		return m.MinLearners
+	}
+	return 0
+}
+
+func (m *ResourceRequirements) GetMaxLearners() int32 {
+	if m != nil {
+		return m.MaxLearners
+	}
+	return 0
+}
+
 type User struct {
 	Id        string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Roles     []string `protobuf:"bytes,2,rep,name=roles" json:"roles,omitempty"`
@@ -255,6 +283,14 @@ type JobDeploymentRequest struct {
 	EvaluationMetricsSpec string                `protobuf:"bytes,11,opt,name=evaluation_metrics_spec,json=evaluationMetricsSpec" json:"evaluation_metrics_spec,omitempty"`
 	ImageTag              string                `protobuf:"bytes,12,opt,name=image_tag,json=imageTag" json:"image_tag,omitempty"`
 	ImageLocation         *ImageLocation        `protobuf:"bytes,13,opt,name=image_location,json=imageLocation" json:"image_location,omitempty"`
+	ReplicaGroups         []*ReplicaGroup       `protobuf:"bytes,14,rep,name=replica_groups,json=replicaGroups" json:"replica_groups,omitempty"`
+	CompletionRoles       []string              `protobuf:"bytes,15,rep,name=completion_roles,json=completionRoles" json:"completion_roles,omitempty"`
+	DatasetVolumes        []*DatasetVolume      `protobuf:"bytes,16,rep,name=dataset_volumes,json=datasetVolumes" json:"dataset_volumes,omitempty"`
+	InputDatasets         []*InputDataset       `protobuf:"bytes,17,rep,name=input_datasets,json=inputDatasets" json:"input_datasets,omitempty"`
+	Registries            []*ImageLocation      `protobuf:"bytes,18,rep,name=registries" json:"registries,omitempty"`
+	Sidecars              []string              `protobuf:"bytes,19,rep,name=sidecars" json:"sidecars,omitempty"`
+	HelperResources       []*HelperResources    `protobuf:"bytes,20,rep,name=helper_resources,json=helperResources" json:"helper_resources,omitempty"`
+	CredentialsStripped   bool                  `protobuf:"varint,21,opt,name=credentials_stripped,json=credentialsStripped" json:"credentials_stripped,omitempty"`
 }
 
 func (m *JobDeploymentRequest) Reset()                    { *m = JobDeploymentRequest{} }
@@ -339,11 +375,70 @@ func (m *JobDeploymentRequest) GetImageLocation() *ImageLocation {
 	return nil
 }
 
+func (m *JobDeploymentRequest) GetReplicaGroups() []*ReplicaGroup {
+	if m != nil {
+		return m.ReplicaGroups
+	}
+	return nil
+}
+
+func (m *JobDeploymentRequest) GetCompletionRoles() []string {
+	if m != nil {
+		return m.CompletionRoles
+	}
+	return nil
+}
+
+func (m *JobDeploymentRequest) GetDatasetVolumes() []*DatasetVolume {
+	if m != nil {
+		return m.DatasetVolumes
+	}
+	return nil
+}
+
+func (m *JobDeploymentRequest) GetInputDatasets() []*InputDataset {
+	if m != nil {
+		return m.InputDatasets
+	}
+	return nil
+}
+
+func (m *JobDeploymentRequest) GetRegistries() []*ImageLocation {
+	if m != nil {
+		return m.Registries
+	}
+	return nil
+}
+
+func (m *JobDeploymentRequest) GetSidecars() []string {
+	if m != nil {
+		return m.Sidecars
+	}
+	return nil
+}
+
+func (m *JobDeploymentRequest) GetHelperResources() []*HelperResources {
+	if m != nil {
+		return m.HelperResources
+	}
+	return nil
+}
+
+func (m *JobDeploymentRequest) GetCredentialsStripped() bool {
+	if m != nil {
+		return m.CredentialsStripped
+	}
+	return false
+}
+
 type ImageLocation struct {
 	Registry    string `protobuf:"bytes,1,opt,name=registry" json:"registry,omitempty"`
 	Namespace   string `protobuf:"bytes,2,opt,name=namespace" json:"namespace,omitempty"`
 	AccessToken string `protobuf:"bytes,3,opt,name=access_token,json=accessToken" json:"access_token,omitempty"`
 	Email       string `protobuf:"bytes,4,opt,name=email" json:"email,omitempty"`
+	Username    string `protobuf:"bytes,5,opt,name=username" json:"username,omitempty"`
+	Password    string `protobuf:"bytes,6,opt,name=password" json:"password,omitempty"`
+	PullSecret  string `protobuf:"bytes,7,opt,name=pull_secret,json=pullSecret" json:"pull_secret,omitempty"`
 }
 
 func (m *ImageLocation) Reset()                    { *m = ImageLocation{} }
@@ -379,6 +474,27 @@ func (m *ImageLocation) GetEmail() string {
 	return ""
 }
 
+func (m *ImageLocation) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ImageLocation) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *ImageLocation) GetPullSecret() string {
+	if m != nil {
+		return m.PullSecret
+	}
+	return ""
+}
+
 type JobDeploymentResponse struct {
 	Name   string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Status string `protobuf:"bytes,2,opt,name=status" json:"status,omitempty"`
@@ -483,6 +599,446 @@ func (m *JobHaltResponse) String() string            { return proto.CompactTextS
 func (*JobHaltResponse) ProtoMessage()               {}
 func (*JobHaltResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
+type ReplicaGroup struct {
+	Name      string                `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Replicas  int32                 `protobuf:"varint,2,opt,name=replicas" json:"replicas,omitempty"`
+	Resources *ResourceRequirements `protobuf:"bytes,3,opt,name=resources" json:"resources,omitempty"`
+	Command   string                `protobuf:"bytes,4,opt,name=command" json:"command,omitempty"`
+}
+
+func (m *ReplicaGroup) Reset()                    { *m = ReplicaGroup{} }
+func (m *ReplicaGroup) String() string            { return proto.CompactTextString(m) }
+func (*ReplicaGroup) ProtoMessage()               {}
+func (*ReplicaGroup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *ReplicaGroup) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ReplicaGroup) GetReplicas() int32 {
+	if m != nil {
+		return m.Replicas
+	}
+	return 0
+}
+
+func (m *ReplicaGroup) GetResources() *ResourceRequirements {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func (m *ReplicaGroup) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+type JobScaleRequest struct {
+	Name       string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	TrainingId string `protobuf:"bytes,2,opt,name=training_id,json=trainingId" json:"training_id,omitempty"`
+	UserId     string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	Learners   int32  `protobuf:"varint,4,opt,name=learners" json:"learners,omitempty"`
+}
+
+func (m *JobScaleRequest) Reset()                    { *m = JobScaleRequest{} }
+func (m *JobScaleRequest) String() string            { return proto.CompactTextString(m) }
+func (*JobScaleRequest) ProtoMessage()               {}
+func (*JobScaleRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *JobScaleRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *JobScaleRequest) GetTrainingId() string {
+	if m != nil {
+		return m.TrainingId
+	}
+	return ""
+}
+
+func (m *JobScaleRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *JobScaleRequest) GetLearners() int32 {
+	if m != nil {
+		return m.Learners
+	}
+	return 0
+}
+
+type JobScaleResponse struct {
+	Learners int32 `protobuf:"varint,1,opt,name=learners" json:"learners,omitempty"`
+}
+
+func (m *JobScaleResponse) Reset()                    { *m = JobScaleResponse{} }
+func (m *JobScaleResponse) String() string            { return proto.CompactTextString(m) }
+func (*JobScaleResponse) ProtoMessage()               {}
+func (*JobScaleResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *JobScaleResponse) GetLearners() int32 {
+	if m != nil {
+		return m.Learners
+	}
+	return 0
+}
+
+type JobSuspendRequest struct {
+	Name       string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	TrainingId string `protobuf:"bytes,2,opt,name=training_id,json=trainingId" json:"training_id,omitempty"`
+	UserId     string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+}
+
+func (m *JobSuspendRequest) Reset()                    { *m = JobSuspendRequest{} }
+func (m *JobSuspendRequest) String() string            { return proto.CompactTextString(m) }
+func (*JobSuspendRequest) ProtoMessage()               {}
+func (*JobSuspendRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *JobSuspendRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *JobSuspendRequest) GetTrainingId() string {
+	if m != nil {
+		return m.TrainingId
+	}
+	return ""
+}
+
+func (m *JobSuspendRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+type JobSuspendResponse struct {
+}
+
+func (m *JobSuspendResponse) Reset()                    { *m = JobSuspendResponse{} }
+func (m *JobSuspendResponse) String() string            { return proto.CompactTextString(m) }
+func (*JobSuspendResponse) ProtoMessage()               {}
+func (*JobSuspendResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+type JobResumeRequest struct {
+	Name       string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	TrainingId string `protobuf:"bytes,2,opt,name=training_id,json=trainingId" json:"training_id,omitempty"`
+	UserId     string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+}
+
+func (m *JobResumeRequest) Reset()                    { *m = JobResumeRequest{} }
+func (m *JobResumeRequest) String() string            { return proto.CompactTextString(m) }
+func (*JobResumeRequest) ProtoMessage()               {}
+func (*JobResumeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *JobResumeRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *JobResumeRequest) GetTrainingId() string {
+	if m != nil {
+		return m.TrainingId
+	}
+	return ""
+}
+
+func (m *JobResumeRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+type JobResumeResponse struct {
+}
+
+func (m *JobResumeResponse) Reset()                    { *m = JobResumeResponse{} }
+func (m *JobResumeResponse) String() string            { return proto.CompactTextString(m) }
+func (*JobResumeResponse) ProtoMessage()               {}
+func (*JobResumeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+type JobRedeployRequest struct {
+	Name       string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	TrainingId string `protobuf:"bytes,2,opt,name=training_id,json=trainingId" json:"training_id,omitempty"`
+	UserId     string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	ErrorCode  string `protobuf:"bytes,4,opt,name=error_code,json=errorCode" json:"error_code,omitempty"`
+	Reason     string `protobuf:"bytes,5,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *JobRedeployRequest) Reset()                    { *m = JobRedeployRequest{} }
+func (m *JobRedeployRequest) String() string            { return proto.CompactTextString(m) }
+func (*JobRedeployRequest) ProtoMessage()               {}
+func (*JobRedeployRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *JobRedeployRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *JobRedeployRequest) GetTrainingId() string {
+	if m != nil {
+		return m.TrainingId
+	}
+	return ""
+}
+
+func (m *JobRedeployRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *JobRedeployRequest) GetErrorCode() string {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return ""
+}
+
+func (m *JobRedeployRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type JobRedeployResponse struct {
+	Redeployed bool  `protobuf:"varint,1,opt,name=redeployed" json:"redeployed,omitempty"`
+	Attempt    int32 `protobuf:"varint,2,opt,name=attempt" json:"attempt,omitempty"`
+}
+
+func (m *JobRedeployResponse) Reset()                    { *m = JobRedeployResponse{} }
+func (m *JobRedeployResponse) String() string            { return proto.CompactTextString(m) }
+func (*JobRedeployResponse) ProtoMessage()               {}
+func (*JobRedeployResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *JobRedeployResponse) GetRedeployed() bool {
+	if m != nil {
+		return m.Redeployed
+	}
+	return false
+}
+
+func (m *JobRedeployResponse) GetAttempt() int32 {
+	if m != nil {
+		return m.Attempt
+	}
+	return 0
+}
+
+type DatasetVolume struct {
+	ClaimName string `protobuf:"bytes,1,opt,name=claim_name,json=claimName" json:"claim_name,omitempty"`
+	NfsServer string `protobuf:"bytes,2,opt,name=nfs_server,json=nfsServer" json:"nfs_server,omitempty"`
+	NfsPath   string `protobuf:"bytes,3,opt,name=nfs_path,json=nfsPath" json:"nfs_path,omitempty"`
+	MountPath string `protobuf:"bytes,4,opt,name=mount_path,json=mountPath" json:"mount_path,omitempty"`
+	ReadOnly  bool   `protobuf:"varint,5,opt,name=read_only,json=readOnly" json:"read_only,omitempty"`
+	SubPath   string `protobuf:"bytes,6,opt,name=sub_path,json=subPath" json:"sub_path,omitempty"`
+	ReadWrite bool   `protobuf:"varint,7,opt,name=read_write,json=readWrite" json:"read_write,omitempty"`
+}
+
+func (m *DatasetVolume) Reset()                    { *m = DatasetVolume{} }
+func (m *DatasetVolume) String() string            { return proto.CompactTextString(m) }
+func (*DatasetVolume) ProtoMessage()               {}
+func (*DatasetVolume) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *DatasetVolume) GetClaimName() string {
+	if m != nil {
+		return m.ClaimName
+	}
+	return ""
+}
+
+func (m *DatasetVolume) GetNfsServer() string {
+	if m != nil {
+		return m.NfsServer
+	}
+	return ""
+}
+
+func (m *DatasetVolume) GetNfsPath() string {
+	if m != nil {
+		return m.NfsPath
+	}
+	return ""
+}
+
+func (m *DatasetVolume) GetMountPath() string {
+	if m != nil {
+		return m.MountPath
+	}
+	return ""
+}
+
+func (m *DatasetVolume) GetReadOnly() bool {
+	if m != nil {
+		return m.ReadOnly
+	}
+	return false
+}
+
+func (m *DatasetVolume) GetSubPath() string {
+	if m != nil {
+		return m.SubPath
+	}
+	return ""
+}
+
+func (m *DatasetVolume) GetReadWrite() bool {
+	if m != nil {
+		return m.ReadWrite
+	}
+	return false
+}
+
+type InputDataset struct {
+	Name              string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Type              string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	Endpoint          string `protobuf:"bytes,3,opt,name=endpoint" json:"endpoint,omitempty"`
+	Region            string `protobuf:"bytes,4,opt,name=region" json:"region,omitempty"`
+	Bucket            string `protobuf:"bytes,5,opt,name=bucket" json:"bucket,omitempty"`
+	Username          string `protobuf:"bytes,6,opt,name=username" json:"username,omitempty"`
+	Apikey            string `protobuf:"bytes,7,opt,name=apikey" json:"apikey,omitempty"`
+	Directory         string `protobuf:"bytes,8,opt,name=directory" json:"directory,omitempty"`
+	CacheSizeGb       int32  `protobuf:"varint,9,opt,name=cache_size_gb,json=cacheSizeGb" json:"cache_size_gb,omitempty"`
+	CredentialsSecret string `protobuf:"bytes,10,opt,name=credentials_secret,json=credentialsSecret" json:"credentials_secret,omitempty"`
+}
+
+func (m *InputDataset) Reset()                    { *m = InputDataset{} }
+func (m *InputDataset) String() string            { return proto.CompactTextString(m) }
+func (*InputDataset) ProtoMessage()               {}
+func (*InputDataset) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *InputDataset) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *InputDataset) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *InputDataset) GetEndpoint() string {
+	if m != nil {
+		return m.Endpoint
+	}
+	return ""
+}
+
+func (m *InputDataset) GetRegion() string {
+	if m != nil {
+		return m.Region
+	}
+	return ""
+}
+
+func (m *InputDataset) GetBucket() string {
+	if m != nil {
+		return m.Bucket
+	}
+	return ""
+}
+
+func (m *InputDataset) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *InputDataset) GetApikey() string {
+	if m != nil {
+		return m.Apikey
+	}
+	return ""
+}
+
+func (m *InputDataset) GetDirectory() string {
+	if m != nil {
+		return m.Directory
+	}
+	return ""
+}
+
+func (m *InputDataset) GetCacheSizeGb() int32 {
+	if m != nil {
+		return m.CacheSizeGb
+	}
+	return 0
+}
+
+func (m *InputDataset) GetCredentialsSecret() string {
+	if m != nil {
+		return m.CredentialsSecret
+	}
+	return ""
+}
+
+type HelperResources struct {
+	Container  string                          `protobuf:"bytes,1,opt,name=container" json:"container,omitempty"`
+	Cpus       float64                         `protobuf:"fixed64,2,opt,name=cpus" json:"cpus,omitempty"`
+	Memory     float64                         `protobuf:"fixed64,3,opt,name=memory" json:"memory,omitempty"`
+	MemoryUnit ResourceRequirements_MemoryUnit `protobuf:"varint,4,opt,name=memory_unit,json=memoryUnit,enum=service.ResourceRequirements_MemoryUnit" json:"memory_unit,omitempty"`
+}
+
+func (m *HelperResources) Reset()                    { *m = HelperResources{} }
+func (m *HelperResources) String() string            { return proto.CompactTextString(m) }
+func (*HelperResources) ProtoMessage()               {}
+func (*HelperResources) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *HelperResources) GetContainer() string {
+	if m != nil {
+		return m.Container
+	}
+	return ""
+}
+
+func (m *HelperResources) GetCpus() float64 {
+	if m != nil {
+		return m.Cpus
+	}
+	return 0
+}
+
+func (m *HelperResources) GetMemory() float64 {
+	if m != nil {
+		return m.Memory
+	}
+	return 0
+}
+
+func (m *HelperResources) GetMemoryUnit() ResourceRequirements_MemoryUnit {
+	if m != nil {
+		return m.MemoryUnit
+	}
+	return ResourceRequirements_MB
+}
+
 func init() {
 	proto.RegisterType((*ResourceRequirements)(nil), "service.ResourceRequirements")
 	proto.RegisterType((*User)(nil), "service.User")
@@ -493,6 +1049,18 @@ func init() {
 	proto.RegisterType((*JobKillResponse)(nil), "service.JobKillResponse")
 	proto.RegisterType((*JobHaltRequest)(nil), "service.JobHaltRequest")
 	proto.RegisterType((*JobHaltResponse)(nil), "service.JobHaltResponse")
+	proto.RegisterType((*ReplicaGroup)(nil), "service.ReplicaGroup")
+	proto.RegisterType((*JobScaleRequest)(nil), "service.JobScaleRequest")
+	proto.RegisterType((*JobScaleResponse)(nil), "service.JobScaleResponse")
+	proto.RegisterType((*JobSuspendRequest)(nil), "service.JobSuspendRequest")
+	proto.RegisterType((*JobSuspendResponse)(nil), "service.JobSuspendResponse")
+	proto.RegisterType((*JobResumeRequest)(nil), "service.JobResumeRequest")
+	proto.RegisterType((*JobResumeResponse)(nil), "service.JobResumeResponse")
+	proto.RegisterType((*JobRedeployRequest)(nil), "service.JobRedeployRequest")
+	proto.RegisterType((*JobRedeployResponse)(nil), "service.JobRedeployResponse")
+	proto.RegisterType((*DatasetVolume)(nil), "service.DatasetVolume")
+	proto.RegisterType((*InputDataset)(nil), "service.InputDataset")
+	proto.RegisterType((*HelperResources)(nil), "service.HelperResources")
 	proto.RegisterEnum("service.StatusMessages", StatusMessages_name, StatusMessages_value)
 	proto.RegisterEnum("service.ResourceRequirements_MemoryUnit", ResourceRequirements_MemoryUnit_name, ResourceRequirements_MemoryUnit_value)
 }
@@ -511,6 +1079,10 @@ type LifecycleManagerClient interface {
 	DeployTrainingJob(ctx context.Context, in *JobDeploymentRequest, opts ...grpc.CallOption) (*JobDeploymentResponse, error)
 	KillTrainingJob(ctx context.Context, in *JobKillRequest, opts ...grpc.CallOption) (*JobKillResponse, error)
 	HaltTrainingJob(ctx context.Context, in *JobHaltRequest, opts ...grpc.CallOption) (*JobHaltResponse, error)
+	ScaleTrainingJob(ctx context.Context, in *JobScaleRequest, opts ...grpc.CallOption) (*JobScaleResponse, error)
+	SuspendTrainingJob(ctx context.Context, in *JobSuspendRequest, opts ...grpc.CallOption) (*JobSuspendResponse, error)
+	ResumeTrainingJob(ctx context.Context, in *JobResumeRequest, opts ...grpc.CallOption) (*JobResumeResponse, error)
+	RedeployTrainingJob(ctx context.Context, in *JobRedeployRequest, opts ...grpc.CallOption) (*JobRedeployResponse, error)
 }
 
 type lifecycleManagerClient struct {
@@ -548,12 +1120,52 @@ func (c *lifecycleManagerClient) HaltTrainingJob(ctx context.Context, in *JobHal
 	return out, nil
 }
 
+func (c *lifecycleManagerClient) ScaleTrainingJob(ctx context.Context, in *JobScaleRequest, opts ...grpc.CallOption) (*JobScaleResponse, error) {
+	out := new(JobScaleResponse)
+	err := grpc.Invoke(ctx, "/service.LifecycleManager/ScaleTrainingJob", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) SuspendTrainingJob(ctx context.Context, in *JobSuspendRequest, opts ...grpc.CallOption) (*JobSuspendResponse, error) {
+	out := new(JobSuspendResponse)
+	err := grpc.Invoke(ctx, "/service.LifecycleManager/SuspendTrainingJob", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) ResumeTrainingJob(ctx context.Context, in *JobResumeRequest, opts ...grpc.CallOption) (*JobResumeResponse, error) {
+	out := new(JobResumeResponse)
+	err := grpc.Invoke(ctx, "/service.LifecycleManager/ResumeTrainingJob", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) RedeployTrainingJob(ctx context.Context, in *JobRedeployRequest, opts ...grpc.CallOption) (*JobRedeployResponse, error) {
+	out := new(JobRedeployResponse)
+	err := grpc.Invoke(ctx, "/service.LifecycleManager/RedeployTrainingJob", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for LifecycleManager service
 
 type LifecycleManagerServer interface {
 	DeployTrainingJob(context.Context, *JobDeploymentRequest) (*JobDeploymentResponse, error)
 	KillTrainingJob(context.Context, *JobKillRequest) (*JobKillResponse, error)
 	HaltTrainingJob(context.Context, *JobHaltRequest) (*JobHaltResponse, error)
+	ScaleTrainingJob(context.Context, *JobScaleRequest) (*JobScaleResponse, error)
+	SuspendTrainingJob(context.Context, *JobSuspendRequest) (*JobSuspendResponse, error)
+	ResumeTrainingJob(context.Context, *JobResumeRequest) (*JobResumeResponse, error)
+	RedeployTrainingJob(context.Context, *JobRedeployRequest) (*JobRedeployResponse, error)
 }
 
 func RegisterLifecycleManagerServer(s *grpc.Server, srv LifecycleManagerServer) {
@@ -614,6 +1226,78 @@ func _LifecycleManager_HaltTrainingJob_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LifecycleManager_ScaleTrainingJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobScaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).ScaleTrainingJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.LifecycleManager/ScaleTrainingJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).ScaleTrainingJob(ctx, req.(*JobScaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_SuspendTrainingJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobSuspendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).SuspendTrainingJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.LifecycleManager/SuspendTrainingJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).SuspendTrainingJob(ctx, req.(*JobSuspendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_ResumeTrainingJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).ResumeTrainingJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.LifecycleManager/ResumeTrainingJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).ResumeTrainingJob(ctx, req.(*JobResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_RedeployTrainingJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobRedeployRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).RedeployTrainingJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/service.LifecycleManager/RedeployTrainingJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).RedeployTrainingJob(ctx, req.(*JobRedeployRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _LifecycleManager_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "service.LifecycleManager",
 	HandlerType: (*LifecycleManagerServer)(nil),
@@ -630,6 +1314,22 @@ var _LifecycleManager_serviceDesc = grpc.ServiceDesc{
 			MethodName: "HaltTrainingJob",
 			Handler:    _LifecycleManager_HaltTrainingJob_Handler,
 		},
+		{
+			MethodName: "ScaleTrainingJob",
+			Handler:    _LifecycleManager_ScaleTrainingJob_Handler,
+		},
+		{
+			MethodName: "SuspendTrainingJob",
+			Handler:    _LifecycleManager_SuspendTrainingJob_Handler,
+		},
+		{
+			MethodName: "ResumeTrainingJob",
+			Handler:    _LifecycleManager_ResumeTrainingJob_Handler,
+		},
+		{
+			MethodName: "RedeployTrainingJob",
+			Handler:    _LifecycleManager_RedeployTrainingJob_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "lcm.proto",
@@ -638,63 +1338,110 @@ var _LifecycleManager_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("lcm.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 919 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0xc1, 0x6f, 0xdb, 0xb6,
-	0x17, 0x8e, 0x6c, 0xc7, 0x8e, 0x9e, 0x13, 0x57, 0x25, 0xd2, 0x44, 0x3f, 0xff, 0xd6, 0x2d, 0xf3,
-	0xc9, 0xeb, 0x21, 0x87, 0x0c, 0x18, 0xb6, 0x0e, 0xc3, 0x10, 0x67, 0x4e, 0xe7, 0x34, 0x76, 0x06,
-	0x5a, 0xe9, 0x71, 0x02, 0x2d, 0xbf, 0xaa, 0x44, 0x24, 0x51, 0x23, 0x69, 0x0f, 0x06, 0x76, 0xda,
-	0x9f, 0xba, 0x3f, 0x60, 0xe7, 0x1d, 0x07, 0x52, 0xb2, 0xad, 0xb4, 0x69, 0x80, 0x1e, 0x76, 0xe3,
-	0xf7, 0x3d, 0xbf, 0x8f, 0xe4, 0xf7, 0x3e, 0xca, 0xe0, 0x26, 0x51, 0x7a, 0x9a, 0x4b, 0xa1, 0x05,
-	0x69, 0x29, 0x94, 0x4b, 0x1e, 0x61, 0xef, 0xaf, 0x3a, 0x1c, 0x52, 0x54, 0x62, 0x21, 0x23, 0xa4,
-	0xf8, 0xdb, 0x82, 0x4b, 0x4c, 0x31, 0xd3, 0x8a, 0x10, 0x68, 0x44, 0xf9, 0x42, 0xf9, 0xce, 0x89,
-	0xd3, 0x77, 0xa8, 0x5d, 0x1b, 0x2e, 0x36, 0x5c, 0xad, 0xe0, 0xcc, 0x9a, 0x1c, 0x41, 0x33, 0xc5,
-	0x54, 0xc8, 0x95, 0x5f, 0xb7, 0x6c, 0x89, 0xc8, 0x08, 0xda, 0xc5, 0x2a, 0x5c, 0x64, 0x5c, 0xfb,
-	0x8d, 0x13, 0xa7, 0xdf, 0x39, 0xeb, 0x9f, 0x96, 0xfb, 0x9e, 0x3e, 0xb4, 0xe7, 0xe9, 0xd8, 0x36,
-	0xdc, 0x66, 0x5c, 0x53, 0x48, 0x37, 0x6b, 0xd2, 0x85, 0xbd, 0x04, 0x99, 0xcc, 0x50, 0x2a, 0x7f,
-	0xf7, 0xc4, 0xe9, 0xef, 0xd2, 0x0d, 0x26, 0x27, 0xd0, 0x56, 0xd1, 0x3b, 0x9c, 0xe7, 0x22, 0xe1,
-	0xd1, 0xca, 0x6f, 0x9e, 0x38, 0x7d, 0x97, 0x56, 0x29, 0xd3, 0xad, 0x45, 0x2e, 0x12, 0x11, 0xaf,
-	0xfc, 0x96, 0x2d, 0x6f, 0x30, 0xe9, 0xc1, 0x3e, 0x93, 0xd1, 0x3b, 0xae, 0x31, 0xd2, 0x0b, 0x89,
-	0xfe, 0x9e, 0xad, 0xdf, 0xe3, 0x88, 0x0f, 0x2d, 0xa5, 0x85, 0x64, 0x31, 0xfa, 0xae, 0xbd, 0xe1,
-	0x1a, 0x92, 0xd7, 0xb0, 0x5f, 0x2e, 0x8b, 0x3b, 0xc2, 0x27, 0xde, 0xb1, 0x5d, 0x76, 0xdb, 0x4b,
-	0xfe, 0x0f, 0xf6, 0xe2, 0x7c, 0x11, 0xea, 0x55, 0x8e, 0x7e, 0xdb, 0x1e, 0xa3, 0x15, 0xe7, 0x8b,
-	0x60, 0x95, 0x63, 0xef, 0x47, 0x80, 0x6d, 0x17, 0x69, 0x42, 0x6d, 0x3c, 0xf0, 0x76, 0x48, 0x0b,
-	0xea, 0x63, 0x3e, 0xf0, 0x1c, 0x43, 0xbc, 0x1a, 0x78, 0x35, 0x43, 0xbc, 0xe2, 0x03, 0xaf, 0x6e,
-	0x88, 0x60, 0xe0, 0x35, 0x0c, 0x11, 0xf0, 0x81, 0xb7, 0xdb, 0xfb, 0x03, 0x1a, 0xb7, 0x0a, 0x25,
-	0xe9, 0x40, 0x8d, 0xcf, 0xed, 0x44, 0x5d, 0x5a, 0xe3, 0x73, 0x72, 0x08, 0xbb, 0x52, 0x24, 0x68,
-	0x06, 0x5a, 0xef, 0xbb, 0xb4, 0x00, 0xe4, 0x33, 0x70, 0xdf, 0x72, 0xa9, 0x74, 0xc6, 0x52, 0xb4,
-	0x43, 0x75, 0xe9, 0x96, 0xb0, 0xc3, 0x60, 0x65, 0xb1, 0x51, 0xd8, 0xb9, 0xc6, 0x46, 0x0f, 0x53,
-	0xc6, 0x13, 0x3b, 0x25, 0x97, 0x16, 0xa0, 0xf7, 0x77, 0x03, 0x0e, 0xaf, 0xc4, 0xec, 0x27, 0xcc,
-	0x13, 0xb1, 0x32, 0x26, 0x18, 0x3f, 0x50, 0x69, 0x13, 0x27, 0x2b, 0x53, 0x1c, 0xc8, 0xae, 0xc9,
-	0xf7, 0xe0, 0xca, 0xd2, 0x36, 0x65, 0xf5, 0xdb, 0x67, 0xcf, 0x1f, 0x35, 0x94, 0x6e, 0x7f, 0x4f,
-	0x86, 0xb0, 0x87, 0xd9, 0x32, 0x5c, 0x32, 0x1b, 0x94, 0x7a, 0xbf, 0x7d, 0xf6, 0x62, 0xd3, 0xfb,
-	0xd0, 0x09, 0x4e, 0x87, 0xd9, 0xf2, 0x0d, 0x93, 0x6a, 0x98, 0x69, 0xb9, 0xa2, 0x2d, 0x2c, 0x10,
-	0x39, 0x87, 0x66, 0xc2, 0x66, 0x98, 0x28, 0xbf, 0x69, 0x45, 0xbe, 0x7a, 0x5c, 0xe4, 0xda, 0xfe,
-	0xb6, 0xd0, 0x28, 0x1b, 0xc9, 0x31, 0xb4, 0x16, 0x0a, 0x65, 0xc8, 0xe7, 0x65, 0xe6, 0x9a, 0x06,
-	0x8e, 0xe6, 0xe4, 0x0b, 0x68, 0x6b, 0xc9, 0x78, 0xc6, 0xb3, 0xd8, 0x14, 0x8b, 0xc0, 0xc1, 0x9a,
-	0x1a, 0xcd, 0xad, 0xfb, 0x92, 0xa5, 0xf8, 0xbb, 0x90, 0x77, 0x36, 0x70, 0xc6, 0xfd, 0x35, 0x61,
-	0xc2, 0xb8, 0x44, 0xa9, 0xb8, 0xc8, 0x6c, 0xda, 0x5c, 0xba, 0x86, 0xe4, 0x1b, 0x38, 0xc6, 0x25,
-	0x4b, 0x16, 0x4c, 0x73, 0x91, 0x85, 0x29, 0x6a, 0xc9, 0x23, 0x15, 0xaa, 0x1c, 0xa3, 0x32, 0x4e,
-	0xcf, 0xb6, 0xe5, 0x71, 0x51, 0x9d, 0xe6, 0x18, 0x91, 0xff, 0x83, 0xcb, 0x53, 0x13, 0x61, 0xcd,
-	0x62, 0x7f, 0xbf, 0x18, 0xa8, 0x25, 0x02, 0x16, 0x93, 0x1f, 0xa0, 0x53, 0x14, 0x13, 0x11, 0xd9,
-	0x4e, 0xff, 0xc0, 0x8e, 0xe4, 0x68, 0xe3, 0xc8, 0xc8, 0x94, 0xaf, 0xcb, 0x2a, 0x3d, 0xe0, 0x55,
-	0xd8, 0x7d, 0x09, 0xfb, 0x55, 0x87, 0x89, 0x07, 0xf5, 0x3b, 0x5c, 0x95, 0xf3, 0x36, 0x4b, 0x93,
-	0x18, 0x73, 0x2a, 0xb4, 0x9f, 0x14, 0x97, 0x16, 0xe0, 0x65, 0xed, 0x5b, 0xa7, 0xfb, 0x1d, 0xb4,
-	0x2b, 0xc6, 0x7e, 0x4a, 0x6b, 0xef, 0x4f, 0x07, 0x0e, 0xee, 0x9d, 0xcb, 0x84, 0x56, 0x62, 0xcc,
-	0x95, 0x96, 0x6b, 0x89, 0x0d, 0x36, 0x86, 0x9b, 0xe4, 0xa9, 0x9c, 0x45, 0x6b, 0xad, 0x2d, 0x41,
-	0xbe, 0x84, 0x7d, 0x16, 0x45, 0xa8, 0x54, 0xa8, 0xc5, 0x1d, 0x66, 0xe5, 0x7b, 0x68, 0x17, 0x5c,
-	0x60, 0xa8, 0x6d, 0xea, 0x1b, 0xd5, 0xd4, 0x5f, 0xc0, 0xb3, 0xf7, 0xd2, 0xa2, 0x72, 0x91, 0x29,
-	0x7c, 0x30, 0xf5, 0x47, 0xd0, 0x54, 0x9a, 0xe9, 0xf2, 0xd3, 0xea, 0xd2, 0x12, 0xf5, 0x7e, 0x85,
-	0xce, 0x95, 0x98, 0xbd, 0xe6, 0x49, 0xf2, 0xd8, 0x9b, 0x79, 0x2f, 0x53, 0xb5, 0x0f, 0x32, 0x55,
-	0x49, 0x63, 0xbd, 0x9a, 0xc6, 0xde, 0x53, 0x78, 0xb2, 0xd1, 0x2f, 0x8e, 0x57, 0x6e, 0xf9, 0x33,
-	0x4b, 0xf4, 0x7f, 0xb9, 0x65, 0xa1, 0x5f, 0x6c, 0xf9, 0xe2, 0x0d, 0x74, 0xa6, 0xf6, 0xbe, 0x63,
-	0x54, 0x8a, 0xc5, 0xa8, 0xc8, 0x21, 0x78, 0x93, 0x1b, 0x3a, 0x3e, 0xbf, 0x0e, 0x6f, 0x7e, 0x19,
-	0xd2, 0xf3, 0x60, 0x74, 0x33, 0xf1, 0x76, 0x08, 0x81, 0xce, 0x68, 0x12, 0x0c, 0xe9, 0xe4, 0xfc,
-	0x3a, 0x1c, 0x52, 0x7a, 0x43, 0x3d, 0x20, 0x5d, 0x38, 0x1a, 0x4d, 0xa6, 0xb7, 0x97, 0x97, 0xa3,
-	0x8b, 0xd1, 0x70, 0x12, 0x84, 0x74, 0x38, 0xbd, 0xb9, 0xa5, 0x17, 0xc3, 0xa9, 0x77, 0x78, 0xf6,
-	0x8f, 0x03, 0xde, 0x35, 0x7f, 0x8b, 0xd1, 0x2a, 0x4a, 0x70, 0xcc, 0x32, 0x16, 0xa3, 0x24, 0x01,
-	0x3c, 0x2d, 0x86, 0x12, 0x94, 0x87, 0xbd, 0x12, 0x33, 0xf2, 0xfc, 0xd1, 0x17, 0xde, 0xfd, 0xfc,
-	0x63, 0xe5, 0xd2, 0xb3, 0x1d, 0x72, 0x09, 0x4f, 0x8c, 0x8b, 0x55, 0xcd, 0xe3, 0x6a, 0x53, 0x65,
-	0x84, 0x5d, 0xff, 0xc3, 0x42, 0x55, 0xc7, 0x58, 0xf3, 0x51, 0x9d, 0xca, 0x5c, 0xee, 0xeb, 0x54,
-	0x0d, 0xed, 0xed, 0xcc, 0x9a, 0xf6, 0x6f, 0xfe, 0xeb, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x54,
-	0x84, 0xb1, 0xe4, 0xf3, 0x07, 0x00, 0x00,
+	// 1673 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0x5b, 0x6f, 0x1b, 0xc7,
+	0x15, 0x36, 0x45, 0x8a, 0x97, 0x43, 0x5d, 0xa8, 0xb1, 0x2c, 0x6f, 0xe8, 0x38, 0x55, 0xf9, 0xa4,
+	0x04, 0xa8, 0x80, 0xaa, 0x40, 0xd0, 0xa6, 0x2d, 0x02, 0x4b, 0xa1, 0x1d, 0x3a, 0xba, 0x04, 0x43,
+	0xda, 0x7d, 0xeb, 0x76, 0xb8, 0x7b, 0x44, 0x0d, 0xbc, 0xb7, 0xce, 0xcc, 0xca, 0x61, 0xda, 0xbf,
+	0xd1, 0x87, 0xbe, 0xf6, 0xa1, 0xed, 0x2f, 0xe8, 0x9f, 0x29, 0xd0, 0xdf, 0x52, 0xcc, 0x65, 0x97,
+	0xbb, 0x32, 0x23, 0xc0, 0x0f, 0x7a, 0xdb, 0xf3, 0x7d, 0x33, 0xdf, 0xcc, 0x9c, 0x39, 0x97, 0x21,
+	0xa1, 0x17, 0x05, 0xf1, 0x71, 0x26, 0x52, 0x95, 0x92, 0x8e, 0x44, 0x71, 0xcb, 0x03, 0x1c, 0xfd,
+	0xbb, 0x05, 0xfb, 0x14, 0x65, 0x9a, 0x8b, 0x00, 0x29, 0xfe, 0x39, 0xe7, 0x02, 0x63, 0x4c, 0x94,
+	0x24, 0x04, 0x5a, 0x41, 0x96, 0x4b, 0xaf, 0x71, 0xd8, 0x38, 0x6a, 0x50, 0xf3, 0xad, 0xb1, 0x85,
+	0xc6, 0x36, 0x2c, 0xa6, 0xbf, 0xc9, 0x01, 0xb4, 0x63, 0x8c, 0x53, 0xb1, 0xf4, 0x9a, 0x06, 0x75,
+	0x16, 0x99, 0x40, 0xdf, 0x7e, 0xf9, 0x79, 0xc2, 0x95, 0xd7, 0x3a, 0x6c, 0x1c, 0xed, 0x9c, 0x1c,
+	0x1d, 0xbb, 0x75, 0x8f, 0xd7, 0xad, 0x79, 0x7c, 0x61, 0x26, 0xbc, 0x49, 0xb8, 0xa2, 0x10, 0x97,
+	0xdf, 0x64, 0x08, 0xdd, 0x08, 0x99, 0x48, 0x50, 0x48, 0x6f, 0xf3, 0xb0, 0x71, 0xb4, 0x49, 0x4b,
+	0x9b, 0x1c, 0x42, 0x5f, 0x06, 0x37, 0x18, 0x66, 0x69, 0xc4, 0x83, 0xa5, 0xd7, 0x3e, 0x6c, 0x1c,
+	0xf5, 0x68, 0x15, 0xd2, 0xb3, 0x55, 0x9a, 0xa5, 0x51, 0xba, 0x58, 0x7a, 0x1d, 0x43, 0x97, 0x36,
+	0x19, 0xc1, 0x16, 0x13, 0xc1, 0x0d, 0x57, 0x18, 0xa8, 0x5c, 0xa0, 0xd7, 0x35, 0x7c, 0x0d, 0x23,
+	0x1e, 0x74, 0xa4, 0x4a, 0x05, 0x5b, 0xa0, 0xd7, 0x33, 0x27, 0x2c, 0x4c, 0xf2, 0x1d, 0x6c, 0xb9,
+	0x4f, 0x7b, 0x46, 0xf8, 0xc8, 0x33, 0xf6, 0xdd, 0x6c, 0x73, 0xc8, 0x4f, 0xa0, 0xbb, 0xc8, 0x72,
+	0x5f, 0x2d, 0x33, 0xf4, 0xfa, 0x66, 0x1b, 0x9d, 0x45, 0x96, 0xcf, 0x96, 0x19, 0x92, 0x9f, 0xc3,
+	0x56, 0xcc, 0x13, 0xbf, 0xf4, 0xc1, 0x96, 0xf1, 0x41, 0x3f, 0xe6, 0xc9, 0x79, 0xe1, 0x06, 0x3d,
+	0x84, 0xfd, 0xb0, 0x1a, 0xb2, 0xed, 0x86, 0xb0, 0x1f, 0x8a, 0x21, 0xa3, 0xaf, 0x01, 0x56, 0x6b,
+	0x93, 0x36, 0x6c, 0x5c, 0x9c, 0x0e, 0x1e, 0x91, 0x0e, 0x34, 0x2f, 0xf8, 0xe9, 0xa0, 0xa1, 0x81,
+	0x57, 0xa7, 0x83, 0x0d, 0x0d, 0xbc, 0xe2, 0xa7, 0x83, 0xa6, 0x06, 0x66, 0xa7, 0x83, 0x96, 0x06,
+	0x66, 0xfc, 0x74, 0xb0, 0x39, 0xfa, 0x2b, 0xb4, 0xde, 0x48, 0x14, 0x64, 0x07, 0x36, 0x78, 0x68,
+	0xe2, 0xa2, 0x47, 0x37, 0x78, 0x48, 0xf6, 0x61, 0x53, 0xa4, 0x11, 0xea, 0xb0, 0x68, 0x1e, 0xf5,
+	0xa8, 0x35, 0xc8, 0xa7, 0xd0, 0xbb, 0xe6, 0x42, 0xaa, 0x84, 0xc5, 0x68, 0x42, 0xa3, 0x47, 0x57,
+	0x80, 0xb9, 0x52, 0xe6, 0xc8, 0x96, 0xbd, 0x94, 0xc2, 0xd6, 0x7a, 0x18, 0x33, 0x1e, 0x99, 0xbb,
+	0xee, 0x51, 0x6b, 0x8c, 0xfe, 0xd1, 0x85, 0xfd, 0xd7, 0xe9, 0xfc, 0x1b, 0xcc, 0xa2, 0x74, 0xa9,
+	0x5d, 0xa9, 0xbd, 0x8a, 0x52, 0xe9, 0xa0, 0x34, 0x32, 0x76, 0x43, 0xe6, 0x9b, 0xfc, 0x16, 0x7a,
+	0xc2, 0x39, 0x5f, 0x1a, 0xfd, 0xfe, 0xc9, 0xf3, 0x7b, 0xaf, 0x85, 0xae, 0xc6, 0x93, 0x31, 0x74,
+	0x31, 0xb9, 0xf5, 0x6f, 0x99, 0x09, 0xb7, 0xe6, 0x51, 0xff, 0xe4, 0x8b, 0x72, 0xee, 0xba, 0x1d,
+	0x1c, 0x8f, 0x93, 0xdb, 0xb7, 0x4c, 0xc8, 0x71, 0xa2, 0xc4, 0x92, 0x76, 0xd0, 0x5a, 0xe4, 0x05,
+	0xb4, 0x23, 0x36, 0xc7, 0x48, 0x7a, 0x6d, 0x23, 0xf2, 0xf9, 0xfd, 0x22, 0xe7, 0x66, 0xac, 0xd5,
+	0x70, 0x13, 0xc9, 0x53, 0xe8, 0xe4, 0x12, 0x85, 0xcf, 0x43, 0x17, 0xb9, 0x6d, 0x6d, 0x4e, 0x42,
+	0xf2, 0x33, 0xe8, 0x2b, 0xc1, 0x78, 0xc2, 0x93, 0x85, 0x26, 0x6d, 0xd8, 0x42, 0x01, 0x4d, 0x42,
+	0xe3, 0x7d, 0xc1, 0x62, 0x7c, 0x9f, 0x8a, 0x77, 0x5e, 0xcf, 0x79, 0xbf, 0x00, 0x74, 0x48, 0xdf,
+	0xa2, 0x90, 0x3c, 0x4d, 0x4c, 0xcc, 0xf6, 0x68, 0x61, 0x92, 0x2f, 0xe1, 0x29, 0xde, 0xb2, 0x28,
+	0x67, 0x8a, 0xa7, 0x89, 0x1f, 0xa3, 0x12, 0x3c, 0x90, 0xbe, 0xcc, 0x30, 0x70, 0x41, 0xf9, 0x64,
+	0x45, 0x5f, 0x58, 0x76, 0x9a, 0x61, 0x40, 0x9e, 0x41, 0x8f, 0xc7, 0x3a, 0x11, 0x14, 0x5b, 0x98,
+	0xf8, 0xec, 0xd1, 0xae, 0x01, 0x66, 0x6c, 0x41, 0x7e, 0x0f, 0x3b, 0x96, 0x8c, 0xd2, 0xc0, 0xcc,
+	0x34, 0xe1, 0xd9, 0x3f, 0x39, 0x28, 0x3d, 0x32, 0xd1, 0xf4, 0xb9, 0x63, 0xe9, 0x36, 0xaf, 0x9a,
+	0xe4, 0x77, 0xb0, 0x23, 0x30, 0x8b, 0x78, 0xc0, 0xfc, 0x85, 0x48, 0xf3, 0x4c, 0x7a, 0x3b, 0xc6,
+	0xa1, 0x4f, 0x2a, 0x37, 0x6a, 0xe8, 0x57, 0x9a, 0xa5, 0xdb, 0xa2, 0x62, 0x49, 0xf2, 0x39, 0x0c,
+	0x82, 0x34, 0xce, 0x22, 0x34, 0x27, 0xb2, 0x81, 0xba, 0x6b, 0x02, 0x75, 0x77, 0x85, 0x53, 0x0d,
+	0x93, 0xaf, 0x61, 0x37, 0x64, 0x8a, 0x49, 0x54, 0xfe, 0x6d, 0x1a, 0xe5, 0x31, 0x4a, 0x6f, 0x70,
+	0xd8, 0xac, 0x6d, 0xf4, 0x1b, 0xcb, 0xbf, 0x35, 0x34, 0xdd, 0x09, 0xab, 0xa6, 0xd4, 0x3b, 0xe5,
+	0x49, 0x96, 0x2b, 0xdf, 0xe1, 0xd2, 0xdb, 0xbb, 0xb3, 0xd3, 0x89, 0xa6, 0x9d, 0x08, 0xdd, 0xe6,
+	0x15, 0x4b, 0x92, 0x2f, 0x01, 0x04, 0x2e, 0xb8, 0x54, 0x82, 0xa3, 0xf4, 0xc8, 0x61, 0xf3, 0x1e,
+	0x17, 0x55, 0x46, 0xea, 0x5c, 0x92, 0x3c, 0xc4, 0x40, 0xc7, 0xeb, 0x63, 0x73, 0xb2, 0xd2, 0x26,
+	0x67, 0x30, 0xb8, 0xc1, 0x28, 0x43, 0xe1, 0xaf, 0xf2, 0x61, 0xdf, 0x28, 0x7b, 0xa5, 0xf2, 0xb7,
+	0x66, 0x40, 0x91, 0x15, 0x92, 0xee, 0xde, 0xd4, 0x01, 0xf2, 0x4b, 0xd8, 0x0f, 0x04, 0x86, 0x98,
+	0x28, 0xce, 0x22, 0xe9, 0xeb, 0x65, 0xb3, 0x0c, 0x43, 0xef, 0xc9, 0x61, 0xe3, 0xa8, 0x4b, 0x1f,
+	0x57, 0xb8, 0xa9, 0xa3, 0x86, 0x5f, 0xc1, 0x56, 0x35, 0x2b, 0xc8, 0x00, 0x9a, 0xef, 0x70, 0xe9,
+	0x72, 0x54, 0x7f, 0xea, 0x2c, 0xd7, 0x91, 0x84, 0xa6, 0x99, 0xf4, 0xa8, 0x35, 0xbe, 0xda, 0xf8,
+	0x75, 0x63, 0xf8, 0x1b, 0xe8, 0x57, 0x92, 0xe1, 0x63, 0xa6, 0x8e, 0xfe, 0xdb, 0x80, 0xed, 0x9a,
+	0xa3, 0xb4, 0x73, 0x9c, 0xab, 0x0a, 0x89, 0xd2, 0xd6, 0x49, 0xa2, 0xab, 0x85, 0xcc, 0x58, 0x50,
+	0x68, 0xad, 0x00, 0x5d, 0x52, 0x59, 0x10, 0xa0, 0x94, 0xbe, 0x4a, 0xdf, 0x61, 0xe2, 0x6a, 0x58,
+	0xdf, 0x62, 0x33, 0x0d, 0xad, 0x2a, 0x55, 0xab, 0x52, 0xa9, 0xf4, 0x92, 0x3a, 0x4d, 0x4d, 0x51,
+	0xb2, 0x25, 0xac, 0xb4, 0x35, 0x97, 0x31, 0x29, 0xdf, 0xa7, 0x22, 0x74, 0xbd, 0xaa, 0xb4, 0x75,
+	0x52, 0x67, 0x79, 0x14, 0xf9, 0x12, 0x03, 0x81, 0xca, 0x65, 0x3c, 0x68, 0x68, 0x6a, 0x90, 0xd1,
+	0x19, 0x3c, 0xb9, 0x53, 0x3a, 0x64, 0x96, 0x26, 0x12, 0xd7, 0x96, 0xc0, 0x03, 0x68, 0x4b, 0xc5,
+	0x94, 0xeb, 0xd6, 0x3d, 0xea, 0xac, 0xd1, 0x1f, 0x61, 0xe7, 0x75, 0x3a, 0xff, 0x8e, 0x47, 0xd1,
+	0x7d, 0x05, 0xf4, 0x4e, 0x81, 0xd9, 0xf8, 0xa0, 0xc0, 0x54, 0x4a, 0x53, 0xb3, 0x5a, 0x9a, 0x46,
+	0x7b, 0xb0, 0x5b, 0xea, 0xdb, 0xed, 0xb9, 0x25, 0xbf, 0x65, 0x91, 0x7a, 0xc8, 0x25, 0xad, 0xbe,
+	0x5b, 0xf2, 0x6f, 0x0d, 0xd8, 0xaa, 0x56, 0x85, 0xb5, 0x2b, 0x9a, 0xd8, 0x30, 0x63, 0xac, 0x93,
+	0x36, 0x69, 0x69, 0xd7, 0x3b, 0x48, 0xf3, 0x23, 0x3b, 0x88, 0x07, 0x9d, 0x20, 0x8d, 0x63, 0x96,
+	0x84, 0x2e, 0x32, 0x0a, 0x73, 0xf4, 0x17, 0xb3, 0xd5, 0x69, 0xc0, 0x22, 0x7c, 0x10, 0x5f, 0xd4,
+	0xde, 0x4a, 0xad, 0xfa, 0x5b, 0x69, 0x74, 0x0c, 0x83, 0xd5, 0xe2, 0x2e, 0x74, 0xaa, 0xe3, 0x1b,
+	0x77, 0xc6, 0x33, 0xd8, 0xd3, 0xe3, 0x73, 0x99, 0x61, 0x12, 0x3e, 0xcc, 0xd5, 0xed, 0x03, 0xa9,
+	0x2e, 0xe1, 0x6e, 0xef, 0x4f, 0x66, 0xa3, 0x14, 0xa5, 0x2e, 0xb2, 0x0f, 0xb2, 0xee, 0x63, 0xd8,
+	0xab, 0xac, 0xe0, 0x96, 0xfd, 0x7b, 0xc3, 0xec, 0x86, 0x62, 0x68, 0x52, 0xec, 0x61, 0x2e, 0xe8,
+	0x39, 0x00, 0x0a, 0x91, 0x0a, 0x3f, 0x48, 0xc3, 0xe2, 0xed, 0xd3, 0x33, 0xc8, 0x59, 0x1a, 0x9a,
+	0xb4, 0x15, 0xc8, 0x64, 0x9a, 0xb8, 0xd2, 0xe1, 0xac, 0xd1, 0x15, 0x3c, 0xae, 0x6d, 0xcd, 0x5d,
+	0xdf, 0x67, 0xba, 0x67, 0x58, 0x0c, 0xed, 0x9b, 0xac, 0x4b, 0x2b, 0x88, 0x8e, 0x44, 0xa6, 0x14,
+	0xc6, 0x99, 0x72, 0x11, 0x5e, 0x98, 0xa3, 0xff, 0x35, 0x60, 0xbb, 0xd6, 0xcd, 0xf4, 0xce, 0x82,
+	0x88, 0xf1, 0xd8, 0xaf, 0x9c, 0xb6, 0x67, 0x90, 0x4b, 0x66, 0xe9, 0xe4, 0x5a, 0xfa, 0x3a, 0x07,
+	0x50, 0x94, 0xe5, 0xf2, 0x5a, 0x4e, 0x0d, 0xa0, 0xdf, 0xaf, 0x9a, 0xce, 0x98, 0xba, 0x71, 0x27,
+	0xee, 0x24, 0xd7, 0xf2, 0x7b, 0xa6, 0x6e, 0xf4, 0xcc, 0x38, 0xcd, 0x13, 0x65, 0x49, 0x77, 0x64,
+	0x83, 0x18, 0xfa, 0x99, 0x4e, 0x35, 0x16, 0xfa, 0x69, 0x12, 0x2d, 0xcd, 0xa9, 0xbb, 0x3a, 0x0f,
+	0x59, 0x78, 0x95, 0x44, 0x4b, 0x2d, 0x2b, 0xf3, 0xb9, 0x9d, 0x69, 0x0b, 0x66, 0x47, 0xe6, 0xf3,
+	0x42, 0xd6, 0xcc, 0x7b, 0x2f, 0xb8, 0x42, 0x53, 0x2e, 0xbb, 0xd4, 0x28, 0xfd, 0x41, 0x03, 0xa3,
+	0x7f, 0x6d, 0xc0, 0x56, 0xb5, 0xdd, 0xae, 0xbd, 0x47, 0x02, 0x2d, 0xf3, 0xe2, 0xb6, 0xc7, 0x31,
+	0xdf, 0x3a, 0x25, 0x30, 0x09, 0xb3, 0x94, 0x27, 0xca, 0x9d, 0xa4, 0xb4, 0xed, 0xf5, 0x2c, 0xf4,
+	0x13, 0xa6, 0x55, 0x5c, 0x8f, 0xb6, 0x34, 0x3e, 0xcf, 0x83, 0x77, 0xa8, 0x8a, 0x6b, 0xb3, 0x56,
+	0xad, 0x17, 0xb4, 0xef, 0xf4, 0x82, 0x03, 0x68, 0xb3, 0x8c, 0xeb, 0xde, 0xe6, 0x1e, 0x77, 0xd6,
+	0xd2, 0x6d, 0x29, 0xe4, 0x02, 0x03, 0xa5, 0x7f, 0x54, 0xd9, 0xa7, 0xdd, 0x0a, 0x20, 0x23, 0xd8,
+	0x0e, 0x58, 0x70, 0x83, 0xbe, 0xe4, 0x3f, 0xa2, 0xbf, 0x98, 0x9b, 0xd7, 0xdd, 0x26, 0xed, 0x1b,
+	0x70, 0xca, 0x7f, 0xc4, 0x57, 0x73, 0xf2, 0x0b, 0x20, 0xb5, 0x86, 0x6d, 0x1b, 0x8a, 0x7d, 0xea,
+	0xed, 0x55, 0xdb, 0xb5, 0xed, 0x2b, 0xff, 0x6c, 0xc0, 0xee, 0x9d, 0x47, 0x80, 0xde, 0x44, 0x90,
+	0x26, 0x8a, 0xf1, 0x04, 0x45, 0x19, 0x0b, 0x05, 0x50, 0xfe, 0x38, 0xdc, 0xa8, 0xfc, 0x38, 0x7c,
+	0xf8, 0x1f, 0x82, 0x5f, 0xbc, 0x85, 0x9d, 0xa9, 0xe9, 0x62, 0x17, 0x28, 0x25, 0x5b, 0xa0, 0x24,
+	0xfb, 0x30, 0xb8, 0xbc, 0xa2, 0x17, 0x2f, 0xce, 0xfd, 0xab, 0xef, 0xc7, 0xf4, 0xc5, 0x6c, 0x72,
+	0x75, 0x39, 0x78, 0x44, 0x08, 0xec, 0x4c, 0x2e, 0x67, 0x63, 0x7a, 0xf9, 0xe2, 0xdc, 0x1f, 0x53,
+	0x7a, 0x45, 0x07, 0x40, 0x86, 0x70, 0x30, 0xb9, 0x9c, 0xbe, 0x79, 0xf9, 0x72, 0x72, 0x36, 0x19,
+	0x5f, 0xce, 0x7c, 0x3a, 0x9e, 0x5e, 0xbd, 0xa1, 0x67, 0xe3, 0xe9, 0x60, 0xff, 0xe4, 0x3f, 0x2d,
+	0x18, 0x9c, 0xf3, 0x6b, 0x0c, 0x96, 0x41, 0x84, 0x17, 0x2c, 0x61, 0x0b, 0x14, 0x64, 0x06, 0x7b,
+	0xb6, 0xd5, 0xce, 0x5c, 0x56, 0xbf, 0x4e, 0xe7, 0xe4, 0xf9, 0xbd, 0x8f, 0xf8, 0xe1, 0x67, 0x3f,
+	0x45, 0xbb, 0x0a, 0xf3, 0x88, 0xbc, 0x84, 0x5d, 0xdd, 0x1b, 0xab, 0x9a, 0x4f, 0xab, 0x93, 0x2a,
+	0x8d, 0x79, 0xe8, 0x7d, 0x48, 0x54, 0x75, 0x74, 0xc3, 0xfb, 0x49, 0x9d, 0x4a, 0xb7, 0x1d, 0x7a,
+	0x1f, 0x12, 0xa5, 0xce, 0x04, 0x06, 0xa6, 0x21, 0x54, 0x85, 0x6a, 0xe3, 0xab, 0xbd, 0x6a, 0xf8,
+	0xc9, 0x1a, 0xa6, 0x94, 0xba, 0x02, 0xe2, 0x0a, 0x79, 0x55, 0x6c, 0x58, 0x9b, 0x52, 0xeb, 0x25,
+	0xc3, 0x67, 0x6b, 0xb9, 0x52, 0xf0, 0x1c, 0xf6, 0x6c, 0x85, 0xae, 0xea, 0xd5, 0xb6, 0x50, 0x6b,
+	0x11, 0xc3, 0xe1, 0x3a, 0xaa, 0x54, 0xa3, 0xf0, 0xb8, 0x28, 0x9f, 0x55, 0xbd, 0x67, 0xf5, 0x49,
+	0xb5, 0xd2, 0x3f, 0xfc, 0x74, 0x3d, 0x59, 0x68, 0xce, 0xdb, 0xe6, 0xdf, 0x94, 0x5f, 0xfd, 0x7f,
+	0x00, 0x01, 0x1e, 0x39, 0x35, 0x5a, 0x11, 0x00, 0x00,
 }
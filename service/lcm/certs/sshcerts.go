@@ -24,10 +24,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-lcm/service/lcm/frameworks"
 	"golang.org/x/crypto/ssh"
 	v1core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -127,12 +127,8 @@ func provisionSSHCertsAsK8sSecret(secretName string, publicKey, privateKey []byt
 	return secret
 }
 
-//NeedsMountedSSHCerts ...
+//NeedsMountedSSHCerts is true if the framework registry asks for ssh keys for the framework version
 func NeedsMountedSSHCerts(framework, version string) bool {
-	result := false
-	if (strings.EqualFold(framework, "tensorflow") && (strings.HasSuffix(version, "horovod") || strings.HasSuffix(version, "ddl"))) || 
-	   strings.EqualFold(framework, "mxnet") || strings.EqualFold(framework, "pytorch") {
-		result = true
-	}
-	return result
+	fw, _ := frameworks.Lookup(framework, version)
+	return fw.SSHKeys
 }
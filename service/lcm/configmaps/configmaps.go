@@ -0,0 +1,43 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//Package configmaps reads the optional configmaps mounted into LCM, a missing configmap leaves the caller with its defaults
+package configmaps
+
+import (
+	"io/ioutil"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+)
+
+//Load parses the yaml file at configPath into out, callers read it on every use so configmap updates are picked up.
+//It returns false if the file is absent or invalid and out must not be used then, what names the configmap in the logs.
+func Load(configPath string, what string, out interface{}) bool {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithError(err).Errorf("failed to read %s %s, using the defaults", what, configPath)
+		}
+		return false
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		log.WithError(err).Errorf("failed to parse %s %s, using the defaults", what, configPath)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package configmaps
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service/lcm/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	Name  string `yaml:"name"`
+	Count int    `yaml:"count"`
+}
+
+func TestLoad(t *testing.T) {
+	configPath := "/nonexistent/config.yaml"
+	var config testConfig
+	assert.False(t, Load(configPath, "test config", &config))
+
+	restore, err := testutil.OverrideConfigMap(&configPath, "name: a\ncount: 2\n")
+	assert.NoError(t, err)
+	assert.True(t, Load(configPath, "test config", &config))
+	assert.Equal(t, testConfig{Name: "a", Count: 2}, config)
+	restore()
+	assert.Equal(t, "/nonexistent/config.yaml", configPath)
+
+	restore, err = testutil.OverrideConfigMap(&configPath, "count: [")
+	assert.NoError(t, err)
+	defer restore()
+	assert.False(t, Load(configPath, "test config", &testConfig{}))
+}
@@ -39,6 +39,8 @@ const (
 	zkGlobalCursor     = "globalcursor"
 	zkGCState          = "gcstate"
 	zkFramework        = "framework"
+	zkSuspend          = "suspend"
+	zkSuspended        = "suspended"
 )
 
 const (
@@ -80,9 +82,7 @@ const (
 )
 
 const (
-	//Total CPU for helpers = 2.5
-	//Total RAM for helpers = 4 GB
-	// These are different from FfDL vs. DLaaS.  Need to get these value from Viper config, I think.  -sb Feb 13, 2019
+	//Default resources of the helper containers, the lcm-helper-resources configmap replaces them, see helper_resources.go
 	storeResultsMilliCPU     = 100
 	storeResultsMemInMB      = 500
 	loadModelMilliCPU        = 100
@@ -101,11 +101,14 @@ const (
 	framework                       = "framework"
 	progress                        = "progress"
 	outcome                         = "outcome"
+	zoneLabel                       = "zone"
 	halted                          = "job_halted"
 	started                         = "job_started"
 	jmLaunchFailed                  = "jm_launch_failed"
 	psLaunchFailed                  = "ps_launch_failed"
 	learnerLaunchFailed             = "learner_launch_failed"
+	staticVolumeLeaseFailed         = "static_volume_lease_failed"
+	resourceAdmissionFailed         = "resource_admission_failed"
 	killed                          = "job_killed"
 	servicesDeletedPhaseComplete    = "servicesDeletedPhaseComplete"
 	deploymentsDeletedPhaseComplete = "deploymentsDeletedPhaseComplete"
@@ -25,8 +25,8 @@ import (
 	"github.com/AISphere/ffdl-commons/config"
 	"github.com/AISphere/ffdl-lcm/lcmconfig"
 	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/databrokers"
 	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
-	yaml "gopkg.in/yaml.v2"
 
 	"github.com/spf13/viper"
 
@@ -40,6 +40,7 @@ import (
 )
 
 const logCollectorContainerName string = "log-collector" // the name of the learner container in the pod
+const controllerContainerName = "controller"
 const loadDataContainerName = "load-data"
 const loadModelContainerName = "load-model"
 const learnerContainerName = "learner"
@@ -47,18 +48,10 @@ const storeResultsContainerName = "store-results"
 const storeLogsContainerName = "store-logs"
 const learnerConfigDir = "/etc/learner-config"
 
-const simpleLogCollectorName = "log_collector"
-
 const logCollectorBadTagNoTDSFound = "dummy-tag-no-tds-found"
 
-// valid names of databroker types that map to "databroker_<type>" Docker image names
-var validDatabrokerTypes = []string{"objectstorage", "s3"}
-
-// default databroker type
-var defaultDatabrokerType = "objectstorage"
-
 const (
-	workerPort int32 = 2222
+	workerPort int32 = learner.TensorFlowWorkerPort
 	sshPort    int32 = 22
 )
 
@@ -67,7 +60,7 @@ const (
 // need to use 1 and not 0 because job monitor tracks path starting with learner 1 and not 0
 const masterLearnerID = 1
 
-func constructControllerContainer(trainingID string, etcdVolumeMount, sharedVolumeMount v1core.VolumeMount, skipStoreData, skipStoreResults bool) v1core.Container {
+func constructControllerContainer(trainingID string, etcdVolumeMount, sharedVolumeMount v1core.VolumeMount, skipStoreData, skipStoreResults, learnerSyncsLogs bool, resources v1core.ResourceRequirements) v1core.Container {
 
 	learnerNodeBasePath := learnerNodeEtcdBasePath(trainingID, masterLearnerID)
 	learnerNodeStatusPath := learnerNodeEtcdStatusPath(trainingID, masterLearnerID)
@@ -102,12 +95,8 @@ func constructControllerContainer(trainingID string, etcdVolumeMount, sharedVolu
 		cmd = "echo 0 > " + sharedVolumeMount.MountPath + "/load-data.exit && " + cmd
 	}
 
-	cpuCount := v1resource.NewMilliQuantity(int64(controllerMilliCPU), v1resource.DecimalSI)
-	memInBytes := int64(controllerMemInMB * 1024 * 1024)
-	memCount := v1resource.NewQuantity(memInBytes, v1resource.DecimalSI)
-
 	container := v1core.Container{
-		Name:    "controller",
+		Name:    controllerContainerName,
 		Image:   controllerImageName,
 		Command: []string{"sh", "-c", cmd},
 		Env: []v1core.EnvVar{
@@ -122,89 +111,14 @@ func constructControllerContainer(trainingID string, etcdVolumeMount, sharedVolu
 			getEnvVarFromLCMSecret("DLAAS_ETCD_PASSWORD"),
 			getEnvVarFromLCMSecret("DLAAS_ETCD_PREFIX"),
 		},
-		Resources: v1core.ResourceRequirements{
-			Requests: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-			Limits: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-		},
+		Resources:       resources,
 		VolumeMounts:    []v1core.VolumeMount{etcdVolumeMount, sharedVolumeMount},
 		ImagePullPolicy: lcmconfig.GetImagePullPolicy(),
 	}
-	return container
-}
-
-func fetchImageNameFromEvaluationMetrics(evalMetricsString string,
-	learnerTag string,
-	framework string,
-	version string,
-	logr *logger.LocLoggingEntry) (string, string) {
-
-	logr.Debugf("evaluation_metrics: %v<end>", evalMetricsString)
-	logCollectorImageShortName := simpleLogCollectorName
-
-	learnerEMTag := learnerTag
-
-	logr.Debugf("evalMetricsString: %s", evalMetricsString)
-	if evalMetricsString != "" {
-		em := make(map[interface{}]interface{})
-		err := yaml.Unmarshal([]byte(evalMetricsString), &em)
-		if err != nil {
-			// Assuming pre-validation, this is unlikely to happen, so this is mostly a programmer assertion.
-			logr.WithError(err).Error("evaluation_metrics was specified in manifest, but can't be parsed!")
-		}
-
-		m := em["evaluation_metrics"].(map[interface{}]interface{})
-
-		if m != nil {
-			val, ok := m["image_tag"]
-			logr.Debugf("learner tag: %s %t", val, ok)
-			if ok == false {
-				// TODO: fix dropping underbar problem.  Somehow.
-				// Having a hard time with, I think the yaml to string stuff, dropping underbars.
-				val, ok = m["imagetag"]
-			}
-			if ok && val.(string) != "" {
-				learnerEMTag = val.(string)
-			}
-
-			imageType, ok := m["type"]
-
-			// Allow some synonyms for simple file extractor
-			if ok && (imageType == "optivist" || imageType == "emetrics_file" || imageType == "file") {
-				imageType = "emetrics_file_extractor"
-			}
-
-			if ok && imageType.(string) != "" {
-				logr.Debugf("initial evaluation_metrics type: %s", imageType)
-				// Assume the image name has been validated upstream
-				logCollectorImageShortName = imageType.(string)
-				if logCollectorImageShortName == "tensorboard" || logCollectorImageShortName == "tensorboard_extractor" {
-					// For the moment we're just going to use TF 1.3, but the tag should change to be non-version
-					// specific, and we should just use latest TF.
-					logCollectorImageShortName = fmt.Sprintf("%s_extract", "tensorboard")
-				}
-				// Be flexible
-				if logCollectorImageShortName == "null" || logCollectorImageShortName == "nil" ||
-					logCollectorImageShortName == "logger" || logCollectorImageShortName == "none" {
-					logCollectorImageShortName = simpleLogCollectorName
-				}
-
-			} else {
-				logr.Error("evaluation_metrics type is empty")
-				logCollectorImageShortName = simpleLogCollectorName
-			}
-		} else {
-			logr.Debug("No evaluation metrics specified! (2)")
-		}
-	} else {
-		logr.Debug("No evaluation metrics specified! (1)")
+	if learnerSyncsLogs {
+		container.Env = append(container.Env, v1core.EnvVar{Name: "LEARNER_SYNCS_LOGS", Value: "true"})
 	}
-	return logCollectorImageShortName, learnerEMTag
+	return container
 }
 
 func findTrainingDataServiceTag(k8sClient kubernetes.Interface, logr *logger.LocLoggingEntry) string {
@@ -235,10 +149,11 @@ func findTrainingDataServiceTag(k8sClient kubernetes.Interface, logr *logger.Loc
 }
 
 func constructLogCollector(sssVolumeMount *v1core.VolumeMount, sharedVolumeMount v1core.VolumeMount, k8sClient kubernetes.Interface, req *service.JobDeploymentRequest,
-	envVars []v1core.EnvVar, logr *logger.LocLoggingEntry) v1core.Container {
+	envVars []v1core.EnvVar, resources v1core.ResourceRequirements, logr *logger.LocLoggingEntry) v1core.Container {
 
 	defaultTag := findTrainingDataServiceTag(k8sClient, logr)
-	logCollectorImageShortName, learnerEMTag := fetchImageNameFromEvaluationMetrics(req.EvaluationMetricsSpec, defaultTag, req.Framework, req.Version, logr)
+	emSpec := evaluationMetrics(req, logr)
+	logCollectorImageShortName, learnerEMTag := logCollectorImageName(emSpec, defaultTag)
 
 	dockerRegistry := viper.GetString(config.LearnerRegistryKey)
 	logCollectorImage :=
@@ -260,54 +175,38 @@ func constructLogCollector(sssVolumeMount *v1core.VolumeMount, sharedVolumeMount
 	vars = append(vars, v1core.EnvVar{Name: "TRAINING_DATA_SERVICE_NAME", Value: config.GetTDSServiceName()})
 
 	if req.EvaluationMetricsSpec != "" {
-		vars = append(vars, v1core.EnvVar{Name: "EM_DESCRIPTION", Value: req.EvaluationMetricsSpec})
+		vars = append(vars, evaluationMetricsEnvVars(emSpec)...)
 	}
 
-	cpuCount := v1resource.NewMilliQuantity(int64(logCollectorMilliCPU), v1resource.DecimalSI)
-	memInBytes := int64(logCollectorMemInMB * 1024 * 1024)
-	memCount := v1resource.NewQuantity(memInBytes, v1resource.DecimalSI)
-
 	volumeMounts := []v1core.VolumeMount{sharedVolumeMount}
 	if sssVolumeMount != nil {
 		volumeMounts = append(volumeMounts, *sssVolumeMount)
 	}
 
 	logCollectorContainer := v1core.Container{
-		Name:    logCollectorContainerName,
-		Image:   logCollectorImage,
-		Command: []string{"bash", "-c", "/scripts/run.sh"},
-		Env:     vars,
-		Resources: v1core.ResourceRequirements{
-			Requests: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-			Limits: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-		},
+		Name:            logCollectorContainerName,
+		Image:           logCollectorImage,
+		Command:         []string{"bash", "-c", "/scripts/run.sh"},
+		Env:             vars,
+		Resources:       resources,
 		VolumeMounts:    volumeMounts,
 		ImagePullPolicy: lcmconfig.GetImagePullPolicy(),
 	}
 	return logCollectorContainer
 }
 
-func constructLoadTrainingDataContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar) v1core.Container {
+// The load-data container loads the DATA_STORE_ of the job if loadDataStore is set, then waits for the
+// exit files of the containers loading input datasets, its exit file tells the controller all data is loaded.
+func constructLoadTrainingDataContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar, loadDataStore bool, datasetExits []string, resources v1core.ResourceRequirements) v1core.Container {
 
 	// Construct the environment variables to pass to the container.
 	// Include all the variables in the job that start with "DATA_STORE_"
 	vars := make([]v1core.EnvVar, 0, len(jobEnvVars))
 	prefix := "DATA_STORE_"
+	broker := dataBroker(prefix, jobEnvVars)
 	for _, ev := range jobEnvVars {
 		if strings.HasPrefix(ev.Name, prefix) {
-			if ev.Name == "DATA_STORE_APIKEY" {
-				vars = append(vars, v1core.EnvVar{Name: "DATA_STORE_PASSWORD", Value: ev.Value})
-			} else if strings.HasPrefix(ev.Name, "DATA_STORE_OBJECTID") {
-				vars = append(vars, v1core.EnvVar{Name: "DATA_STORE_BUCKET", Value: ev.Value})
-			} else {
-				vars = append(vars, ev)
-			}
+			vars = append(vars, renamedEnvVar(broker.EnvVarName(databrokers.LoadData, strings.TrimPrefix(ev.Name, prefix)), ev))
 		}
 		if strings.HasPrefix(ev.Name, "DATA_DIR") { // special case
 			dataDir := path.Join(sharedVolumeMount.MountPath, ev.Value)
@@ -315,26 +214,21 @@ func constructLoadTrainingDataContainer(sharedVolumeMount v1core.VolumeMount, jo
 		}
 	}
 
-	cpuCount := v1resource.NewMilliQuantity(int64(loadTrainingDataMilliCPU), v1resource.DecimalSI)
-	memInBytes := int64(loadTrainingDataMemInMB * 1024 * 1024)
-	memCount := v1resource.NewQuantity(memInBytes, v1resource.DecimalSI)
-
-	command := fmt.Sprintf(`load.sh |tee -a %s/load-data.log`, PodLevelLogDir)
+	var commands []string
+	if loadDataStore {
+		commands = append(commands, fmt.Sprintf(`load.sh |tee -a %s/load-data.log`, PodLevelLogDir))
+	}
+	if len(datasetExits) > 0 {
+		commands = append(commands, fmt.Sprintf(`status=0 ; for exit in %s; do while [ ! -f %s/$exit.exit ]; do sleep 2; done ; [ "$(cat %s/$exit.exit)" = "0" ] || status=1 ; done ; [ $status -eq 0 ]`,
+			strings.Join(datasetExits, " "), sharedVolumeMount.MountPath, sharedVolumeMount.MountPath))
+	}
+	command := strings.Join(commands, " && ")
 	cmd := wrapCommand(command, loadDataContainerName, sharedVolumeMount.MountPath, false)
 	container := v1core.Container{
-		Name:    loadDataContainerName,
-		Image:   dataBrokerImageName(vars),
-		Command: []string{"sh", "-c", cmd},
-		Resources: v1core.ResourceRequirements{
-			Requests: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-			Limits: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-		},
+		Name:            loadDataContainerName,
+		Image:           dataBrokerImageName(broker),
+		Command:         []string{"sh", "-c", cmd},
+		Resources:       resources,
 		VolumeMounts:    []v1core.VolumeMount{sharedVolumeMount},
 		Env:             vars,
 		ImagePullPolicy: lcmconfig.GetImagePullPolicy(),
@@ -342,7 +236,7 @@ func constructLoadTrainingDataContainer(sharedVolumeMount v1core.VolumeMount, jo
 	return container
 }
 
-func constructLoadModelContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar) v1core.Container {
+func constructLoadModelContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar, resources v1core.ResourceRequirements) v1core.Container {
 
 	// Construct the environment variables to pass to the container.
 	// Include all the variables in the job that start with "MODEL_STORE_"
@@ -351,16 +245,10 @@ func constructLoadModelContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVar
 	vars = append(vars, v1core.EnvVar{Name: "DOWNWARD_API_POD_NAMESPACE", ValueFrom: &v1core.EnvVarSource{FieldRef: &v1core.ObjectFieldSelector{FieldPath: "metadata.namespace"}}})
 
 	prefix := "MODEL_STORE_"
+	broker := dataBroker(prefix, jobEnvVars)
 	for _, ev := range jobEnvVars {
 		if strings.HasPrefix(ev.Name, prefix) {
-			name := strings.Replace(ev.Name, "MODEL_STORE_", "DATA_STORE_", 1)
-			if name == "DATA_STORE_APIKEY" {
-				vars = append(vars, v1core.EnvVar{Name: "DATA_STORE_PASSWORD", Value: ev.Value})
-			} else if name == "DATA_STORE_OBJECTID" {
-				vars = append(vars, v1core.EnvVar{Name: "DATA_STORE_OBJECT", Value: ev.Value})
-			} else {
-				vars = append(vars, v1core.EnvVar{Name: name, Value: ev.Value})
-			}
+			vars = append(vars, renamedEnvVar(broker.EnvVarName(databrokers.LoadModel, strings.TrimPrefix(ev.Name, prefix)), ev))
 		}
 		if ev.Name == "MODEL_DIR" { // special case
 			dataDir := path.Join(sharedVolumeMount.MountPath, ev.Value)
@@ -371,24 +259,11 @@ func constructLoadModelContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVar
 	command := "loadmodel.sh"
 	cmd := wrapCommand(command, loadModelContainerName, sharedVolumeMount.MountPath, false)
 
-	cpuCount := v1resource.NewMilliQuantity(int64(loadModelMilliCPU), v1resource.DecimalSI)
-	memInBytes := int64(loadModelMemInMB * 1024 * 1024)
-	memCount := v1resource.NewQuantity(memInBytes, v1resource.DecimalSI)
-
 	container := v1core.Container{
-		Name:    loadModelContainerName,
-		Image:   dataBrokerImageName(vars),
-		Command: []string{"sh", "-c", cmd},
-		Resources: v1core.ResourceRequirements{
-			Requests: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-			Limits: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-		},
+		Name:            loadModelContainerName,
+		Image:           dataBrokerImageName(broker),
+		Command:         []string{"sh", "-c", cmd},
+		Resources:       resources,
 		VolumeMounts:    []v1core.VolumeMount{sharedVolumeMount},
 		Env:             vars,
 		ImagePullPolicy: lcmconfig.GetImagePullPolicy(),
@@ -396,7 +271,7 @@ func constructLoadModelContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVar
 	return container
 }
 
-func constructLearnerContainer(req *service.JobDeploymentRequest, envVars []v1core.EnvVar, learnerVolumeMounts []v1core.VolumeMount, sharedVolumeMount v1core.VolumeMount, mountTrainingDataStoreInLearner, mountResultsStoreInLearner, mountSSHCertsInLearner bool, logr *logger.LocLoggingEntry, useLogCollector bool) v1core.Container {
+func constructLearnerContainer(req *service.JobDeploymentRequest, envVars []v1core.EnvVar, learnerVolumeMounts []v1core.VolumeMount, mountWaitCommand string, sharedVolumeMount v1core.VolumeMount, mountTrainingDataStoreInLearner, mountResultsStoreInLearner, mountSSHCertsInLearner bool, logr *logger.LocLoggingEntry, useLogCollector bool) v1core.Container {
 
 	cpuCount := v1resource.NewMilliQuantity(int64(float64(req.Resources.Cpus)*1000.0), v1resource.DecimalSI)
 	gpuCount := v1resource.NewQuantity(int64(req.Resources.Gpus), v1resource.DecimalSI)
@@ -405,6 +280,13 @@ func constructLearnerContainer(req *service.JobDeploymentRequest, envVars []v1co
 
 	//argh!!! this should be abstracted out as well
 	command := "for i in ${!ALERTMANAGER*} ${!DLAAS*} ${!ETCD*} ${!GRAFANA*} ${!HOSTNAME*} ${!KUBERNETES*} ${!MONGO*} ${!PUSHGATEWAY*}; do unset $i; done;"
+	distributedCommand := ""
+	var distributedPorts []v1core.ContainerPort
+	if adapter := distributedAdapterForLearners(req); adapter != nil {
+		distributedCommand = adapter.Command()
+		distributedPorts = adapter.Ports()
+	}
+	command += distributedCommand
 	learnerBashCommand := `bash -c 'train.sh >> $JOB_STATE_DIR/latest-log 2>&1 ; exit ${PIPESTATUS[0]}'`
 	image := learner.Image{
 		Framework: req.Framework,
@@ -434,11 +316,12 @@ func constructLearnerContainer(req *service.JobDeploymentRequest, envVars []v1co
 		learnerCommand := fmt.Sprintf(`%s bash -c ' train.sh 2>&1 | tee -a %s/latest-log; exit ${PIPESTATUS[0]}'`, command, sharedVolumeMount.MountPath)
 		storeLogsCommand := `bash -c 'exit 0'`
 		if mountResultsStoreInLearner {
+			//store-logs only runs once training ended, so a suspend asks the log sync for a final sync (suspend-sync) instead
 			loadModelComand += `mkdir -p "$MODEL_DIR"
 			unzip -nq "$RESULT_DIR/_submitted_code/model.zip" -d "$MODEL_DIR"`
 			learnerCommand = `
 			for i in ${!ALERTMANAGER*} ${!DLAAS*} ${!ETCD*} ${!GRAFANA*} ${!HOSTNAME*} ${!KUBERNETES*} ${!MONGO*} ${!PUSHGATEWAY*}; do unset $i; done;
-			export LEARNER_ID=$((${DOWNWARD_API_POD_NAME##*-} + 1)) ;
+			export LEARNER_ID=$((${DOWNWARD_API_POD_NAME##*-} + ${LEARNER_ID_OFFSET:-0} + 1)) ;
 			mkdir -p $RESULT_DIR/learner-$LEARNER_ID ;
 			mkdir -p $CHECKPOINT_DIR ;
 			RESULT_STORE_PUBLIC_AUTHURL=$(echo $RESULT_STORE_AUTHURL | sed -e 's/service.networklayer.com/softlayer.net/g' | sed -e 's/.private//g')
@@ -447,10 +330,16 @@ func constructLearnerContainer(req *service.JobDeploymentRequest, envVars []v1co
 				while true; do
 				AWS_ACCESS_KEY_ID=$RESULT_STORE_USERNAME AWS_SECRET_ACCESS_KEY=$RESULT_STORE_APIKEY \
 	timeout -s 3 20 aws --endpoint-url=$RESULT_STORE_PUBLIC_AUTHURL s3 sync $LOG_DIR s3://$RESULT_STORE_OBJECTID/learner-$LEARNER_ID
-				sleep 40
+				sync_exit=$?
+				if [ -f $JOB_STATE_DIR/suspend-sync.start ]; then echo $sync_exit > $JOB_STATE_DIR/suspend-sync.exit ; fi
+				for i in 1 2 3 4 5 6 7 8; do
+					if [ -f $JOB_STATE_DIR/suspend-sync.start ] && [ ! -f $JOB_STATE_DIR/suspend-sync.exit ]; then break ; fi
+					sleep 5
+				done
 			done
 			}
 			syncLogs & `
+			learnerCommand += distributedCommand
 			storeLogsCommand = `
 			mv -nf $LOG_DIR/* $RESULT_DIR/learner-$LEARNER_ID ;
 			ERROR_CODE=$? ;
@@ -463,14 +352,16 @@ func constructLearnerContainer(req *service.JobDeploymentRequest, envVars []v1co
 		learnerCommand += learnerBashCommand
 		cmd = wrapCommands([]containerCommands{
 			{cmd: loadModelComand, container: loadModelContainerName},
-			{cmd: learnerCommand, container: learnerContainerName},
+			{cmd: learnerCommand, container: learnerContainerName, exit: learnerExitName(req)},
 			{cmd: storeLogsCommand, container: storeLogsContainerName},
 		}, sharedVolumeMount.MountPath)
 	} else {
 		command = fmt.Sprintf(`%s mkdir -p $RESULT_DIR ; bash -c ' train.sh 2>&1 | tee -a %s/latest-log; exit ${PIPESTATUS[0]}'`, command, sharedVolumeMount.MountPath)
 		doCondExitWrite = false
-		cmd = wrapCommand(command, learnerContainerName, sharedVolumeMount.MountPath, doCondExitWrite)
+		cmd = wrapCommandWithExitFile(command, learnerContainerName, learnerExitName(req), sharedVolumeMount.MountPath, doCondExitWrite)
 	}
+	//the buckets of fuse sidecars are mounted some time after the learner started
+	cmd = mountWaitCommand + cmd
 
 	container := learner.Container{
 		Image: image,
@@ -481,6 +372,7 @@ func constructLearnerContainer(req *service.JobDeploymentRequest, envVars []v1co
 		Name:         learnerContainerName,
 		EnvVars:      envVars,
 		Command:      cmd,
+		Ports:        distributedPorts,
 	}
 
 	learnerContainer := learner.CreateContainerSpec(container, req.Labels["kube_major"], req.Labels["kube_minor"])
@@ -490,13 +382,14 @@ func constructLearnerContainer(req *service.JobDeploymentRequest, envVars []v1co
 	return learnerContainer
 }
 
-func constructStoreLogsContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar) v1core.Container {
+func constructStoreLogsContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar, resources v1core.ResourceRequirements) v1core.Container {
 
 	command := "store.sh"
-	container := constructStoreContainer(storeLogsContainerName, command, sharedVolumeMount, jobEnvVars)
+	container := constructStoreContainer(storeLogsContainerName, command, databrokers.StoreLogs, sharedVolumeMount, jobEnvVars, resources)
 
+	bucketEnvVar := dataBroker("RESULT_STORE_", jobEnvVars).EnvVarName(databrokers.StoreLogs, "OBJECTID")
 	for i := range container.Env {
-		if container.Env[i].Name == "DATA_STORE_BUCKET" {
+		if container.Env[i].Name == bucketEnvVar {
 			value := fmt.Sprintf("%s/learner-%d", container.Env[i].Value, masterLearnerID) // per-learner directory
 			container.Env[i].Value = value
 		} else if container.Env[i].Name == "DATA_DIR" {
@@ -508,15 +401,15 @@ func constructStoreLogsContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVar
 	return container
 }
 
-func constructStoreResultsContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar) v1core.Container {
+func constructStoreResultsContainer(sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar, resources v1core.ResourceRequirements) v1core.Container {
 
 	//FIXME how does this work in terms of split learner
 	command := "store.sh" // only store results from first learner
-	container := constructStoreContainer(storeResultsContainerName, command, sharedVolumeMount, jobEnvVars)
+	container := constructStoreContainer(storeResultsContainerName, command, databrokers.StoreResults, sharedVolumeMount, jobEnvVars, resources)
 	return container
 }
 
-func constructStoreContainer(containerName, command string, sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar) v1core.Container {
+func constructStoreContainer(containerName, command string, op databrokers.Operation, sharedVolumeMount v1core.VolumeMount, jobEnvVars []v1core.EnvVar, resources v1core.ResourceRequirements) v1core.Container {
 
 	// Construct the environment variables to pass to the container.
 	// Include all the variables in the job that start with "DATA_STORE_"
@@ -525,16 +418,10 @@ func constructStoreContainer(containerName, command string, sharedVolumeMount v1
 	vars = append(vars, v1core.EnvVar{Name: "DOWNWARD_API_POD_NAMESPACE", ValueFrom: &v1core.EnvVarSource{FieldRef: &v1core.ObjectFieldSelector{FieldPath: "metadata.namespace"}}})
 
 	prefix := "RESULT_STORE_"
+	broker := dataBroker(prefix, jobEnvVars)
 	for _, ev := range jobEnvVars {
 		if strings.HasPrefix(ev.Name, prefix) {
-			name := strings.Replace(ev.Name, "RESULT_STORE_", "DATA_STORE_", 1)
-			if name == "DATA_STORE_APIKEY" {
-				vars = append(vars, v1core.EnvVar{Name: "DATA_STORE_PASSWORD", Value: ev.Value})
-			} else if name == "DATA_STORE_OBJECTID" {
-				vars = append(vars, v1core.EnvVar{Name: "DATA_STORE_BUCKET", Value: ev.Value})
-			} else {
-				vars = append(vars, v1core.EnvVar{Name: name, Value: ev.Value})
-			}
+			vars = append(vars, renamedEnvVar(broker.EnvVarName(op, strings.TrimPrefix(ev.Name, prefix)), ev))
 		}
 		if ev.Name == "RESULT_DIR" { // special case
 			dataDir := path.Join(sharedVolumeMount.MountPath, ev.Value)
@@ -542,25 +429,12 @@ func constructStoreContainer(containerName, command string, sharedVolumeMount v1
 		}
 	}
 
-	cpuCount := v1resource.NewMilliQuantity(int64(storeResultsMilliCPU), v1resource.DecimalSI)
-	memInBytes := int64(storeResultsMemInMB * 1024 * 1024)
-	memCount := v1resource.NewQuantity(memInBytes, v1resource.DecimalSI)
-
 	cmd := wrapCommand(command, containerName, sharedVolumeMount.MountPath, false)
 	container := v1core.Container{
-		Name:    containerName,
-		Image:   dataBrokerImageName(vars),
-		Command: []string{"sh", "-c", cmd},
-		Resources: v1core.ResourceRequirements{
-			Requests: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-			Limits: v1core.ResourceList{
-				v1core.ResourceCPU:    *cpuCount,
-				v1core.ResourceMemory: *memCount,
-			},
-		},
+		Name:            containerName,
+		Image:           dataBrokerImageName(broker),
+		Command:         []string{"sh", "-c", cmd},
+		Resources:       resources,
 		VolumeMounts:    []v1core.VolumeMount{sharedVolumeMount},
 		Env:             vars,
 		ImagePullPolicy: lcmconfig.GetImagePullPolicy(),
@@ -573,6 +447,8 @@ func constructStoreContainer(containerName, command string, sharedVolumeMount v1
 type containerCommands struct {
 	cmd       string
 	container string
+	//exit is the name of the .exit control file, the name of the container if empty
+	exit string
 }
 
 // Wrap a sequence of commands with start and exit files.
@@ -581,8 +457,13 @@ func wrapCommands(commands []containerCommands, controlFilesDirectory string) st
 
 	for _, command := range commands {
 		var buf bytes.Buffer
+		exit := command.exit
+		if exit == "" {
+			exit = command.container
+		}
 		vars := map[string]string{
 			"Name": command.container,
+			"Exit": exit,
 			"Cmd":  command.cmd,
 			"Dir":  controlFilesDirectory,
 		}
@@ -594,11 +475,11 @@ func wrapCommands(commands []containerCommands, controlFilesDirectory string) st
 		//   file will get overwritten by each learner, which is intentional.
 		// - Write exit code of command to .exit file.
 		tmpl, _ := template.New("wrapped command").Parse(`
-			if [ ! -f {{.Dir}}/{{.Name}}.exit ]; then
+			if [ ! -f {{.Dir}}/{{.Exit}}.exit ]; then
 				while [ ! -f {{.Dir}}/{{.Name}}.start ]; do sleep 2; done ;
 				date "+%s%N" | cut -b1-13 > {{.Dir}}/{{.Name}}.start_time ;
 				{{.Cmd}} ;
-				echo $? > {{.Dir}}/{{.Name}}.exit ;
+				echo $? > {{.Dir}}/{{.Exit}}.exit ;
 			fi
 			echo "Done {{.Name}}" ;`)
 		tmpl.Execute(&buf, vars)
@@ -614,9 +495,15 @@ func wrapCommands(commands []containerCommands, controlFilesDirectory string) st
 
 // Wrap a single command with start and exit files.
 func wrapCommand(cmd string, containerName string, controlFilesDirectory string, doCondExitWrite bool) string {
+	return wrapCommandWithExitFile(cmd, containerName, containerName, controlFilesDirectory, doCondExitWrite)
+}
+
+// Wrap a single command with the start file of the container and the exit file exitName.
+func wrapCommandWithExitFile(cmd string, containerName string, exitName string, controlFilesDirectory string, doCondExitWrite bool) string {
 
 	vars := map[string]string{
 		"Name": containerName,
+		"Exit": exitName,
 		"Dir":  controlFilesDirectory,
 		"Cmd":  cmd,
 	}
@@ -624,20 +511,20 @@ func wrapCommand(cmd string, containerName string, controlFilesDirectory string,
 	var exitWriteStr string
 	if doCondExitWrite {
 		exitWriteStr = `
-		if [ ! -f {{.Dir}}/{{.Name}}.exit ]; then
-			echo $main_cmd_status > {{.Dir}}/{{.Name}}.exit
+		if [ ! -f {{.Dir}}/{{.Exit}}.exit ]; then
+			echo $main_cmd_status > {{.Dir}}/{{.Exit}}.exit
         fi
 		`
 	} else {
 		exitWriteStr = `
-		echo $? > {{.Dir}}/{{.Name}}.exit
+		echo $? > {{.Dir}}/{{.Exit}}.exit
 		`
 	}
 
 	var buf bytes.Buffer
 	tmpl, _ := template.New("wrapped command").Parse(`
 		# Don't repeat if already executed.
-		if [ -f {{.Dir}}/{{.Name}}.exit ]; then
+		if [ -f {{.Dir}}/{{.Exit}}.exit ]; then
 			while true; do sleep 1000; done
 		fi
 		# Wait for start signal.
@@ -653,15 +540,14 @@ func wrapCommand(cmd string, containerName string, controlFilesDirectory string,
 	return buf.String()
 }
 
-func constructVolumeClaim(name string, namespace string, volumeSize int64, labels map[string]string) *v1core.PersistentVolumeClaim {
-	claim, err := GetVolumeClaim(volumeSize)
+func constructVolumeClaim(name string, namespace string, profile storageProfile, volumeSize int64, labels map[string]string) *v1core.PersistentVolumeClaim {
+	claim, err := profile.volumeClaim(volumeSize)
 	if err != nil {
 		return nil
 	}
 	claim.Name = name
 	claim.Namespace = namespace
 	claim.Labels = labels
-	claim.Spec.AccessModes = []v1core.PersistentVolumeAccessMode{v1core.ReadWriteMany}
 	return claim
 }
 
@@ -677,29 +563,21 @@ func getValue(envVars []v1core.EnvVar, name string) string {
 	return value
 }
 
-// Return the Docker image of the data broker for this set of variables
-func dataBrokerImageName(vars []v1core.EnvVar) string {
-	t := defaultDatabrokerType
-	for _, ev := range vars {
-		if ev.Name == "DATA_STORE_TYPE" {
-			if ev.Value == "s3_datastore" {
-				t = "s3"
-				break
-			}
-		} else if ev.Name == "DATA_STORE_TYPE" {
-			// t should be a string like "s3" or "objectstorage", but we expect to also receive
-			// strings like "s3_datastore" (read in from the .ini files), hence strip the suffix here.
-			storeType := strings.Replace(ev.Value, "_datastore", "", 1)
-			if contains(validDatabrokerTypes, storeType) {
-				t = storeType
-				break
-			}
-		}
+// Return the broker of the store type in the <prefix>TYPE variable of the job, unknown types are rejected by validateDataStores
+func dataBroker(prefix string, jobEnvVars []v1core.EnvVar) databrokers.Broker {
+	broker, known := databrokers.Lookup(getValue(jobEnvVars, prefix+"TYPE"))
+	if !known {
+		broker, _ = databrokers.Lookup(databrokers.DefaultType)
 	}
+	return broker
+}
+
+// Return the Docker image of the data broker
+func dataBrokerImageName(broker databrokers.Broker) string {
 	//TODO: Tag the databroker and statusrecorder images
 	dockerRegistry := viper.GetString(config.LearnerRegistryKey)
 	dataBrokerTag := viper.GetString(config.DataBrokerTagKey)
-	imageName := dataBrokerImageNameExtended(dockerRegistry, t, dataBrokerTag)
+	imageName := dataBrokerImageNameExtended(dockerRegistry, broker.Image, dataBrokerTag)
 	return imageName
 }
 
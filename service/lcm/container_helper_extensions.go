@@ -18,6 +18,7 @@ package lcm
 
 import (
 	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/frameworks"
 	"github.com/spf13/viper"
 
 	v1core "k8s.io/api/core/v1"
@@ -43,34 +44,14 @@ func extendLearnerContainer(learner *v1core.Container, req *service.JobDeploymen
 	logr *logger.LocLoggingEntry) {
 
 	learnerImage := "__unknown__"
-
-	switch req.Framework {
-	case caffeFrameworkName:
-		learnerImage = "bvlc/caffe:" + req.Version
-	case tfFrameworkName:
-		learnerImage = "tensorflow/tensorflow:" + req.Version
-	case caffe2FrameworkName:
-		learnerImage = "caffe2ai/caffe2:" + req.Version
-	case pytorchFrameworkName:
-		learnerImage = "pytorch/pytorch:" + req.Version
-	case h2o3FrameworkName:
-		learnerImage = "opsh2oai/h2o3-ffdl:" + req.Version
-	case horovodFrameworkName:
-		learnerImage = "uber/horovod:" + req.Version
-	case pytorchMPIFrameworkName:
-		learnerImage = "tomcli/pytorch:" + req.Version
-	case customFrameworkName:
-		learnerImage = req.Version
-	default:
-		// TODO!
+	fw, known := frameworks.Lookup(req.Framework, req.Version)
+	if image := fw.ImageName(req.Version); image != "" {
+		learnerImage = image
+	} else if !known {
+		logr.Warnf("framework %s version %s is not in the framework registry", req.Framework, req.Version)
 	}
 
-	extCmd := ""
-	if req.Framework != horovodFrameworkName && req.Framework != pytorchMPIFrameworkName {
-		extCmd = "export PATH=/usr/local/bin/:$PATH; cp " + learnerEntrypointFilesPath + "/*.sh /usr/local/bin/; chmod +x /usr/local/bin/*.sh;"
-	} else {
-		extCmd = "export PATH=/usr/local/bin/:$PATH; cp " + learnerEntrypointFilesPath + "/*.sh /usr/local/bin/; chmod +x /usr/local/bin/*.sh; mv /usr/local/bin/train-horovod.sh /usr/local/bin/train.sh;"
-	}
+	extCmd := "export PATH=/usr/local/bin/:$PATH; cp " + learnerEntrypointFilesPath + "/*.sh /usr/local/bin/; chmod +x /usr/local/bin/*.sh; " + fw.LaunchCommand
 	extMount := v1core.VolumeMount{
 		Name:      learnerEntrypointFilesVolume,
 		MountPath: learnerEntrypointFilesPath,
@@ -94,14 +75,7 @@ func extendLearnerContainer(learner *v1core.Container, req *service.JobDeploymen
 
 	learner.Image = learnerImage
 	learner.Command[2] = extCmd + learner.Command[2]
-	if req.Framework == horovodFrameworkName || req.Framework == pytorchMPIFrameworkName {
-		RSApub := v1core.EnvVar{Name: "RSA_PUB_KEY", ValueFrom: &v1core.EnvVarSource{
-			SecretKeyRef: &v1core.SecretKeySelector{Key: "RSA_PUB_KEY", LocalObjectReference: v1core.LocalObjectReference{Name: "rsa-keys",},},},}
-		RSApri := v1core.EnvVar{Name: "RSA_PRI_KEY", ValueFrom: &v1core.EnvVarSource{
-			SecretKeyRef: &v1core.SecretKeySelector{Key: "RSA_PRI_KEY", LocalObjectReference: v1core.LocalObjectReference{Name: "rsa-keys",},},},}
-		learner.Env = append(learner.Env, RSApub)
-		learner.Env = append(learner.Env, RSApri)
-	}
+	learner.Env = append(learner.Env, fw.EnvVarSpecs()...)
 }
 
 
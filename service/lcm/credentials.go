@@ -0,0 +1,284 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-trainer/client"
+	"github.com/cenkalti/backoff"
+	"github.com/golang/protobuf/proto"
+	v1core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//request env vars with these suffixes hold credentials, e.g. DATA_STORE_APIKEY, the pods read them from the credentials secret of the job
+var credentialEnvVarSuffixes = []string{"_USERNAME", "_APIKEY", "_PASSWORD", "_TOKEN", "_SECRET", "_ACCESS_KEY", "_SECRET_KEY"}
+
+//what logs show instead of a credential
+const redactedCredential = "<redacted>"
+
+func isCredentialEnvVar(name string) bool {
+	for _, suffix := range credentialEnvVarSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func credentialsSecretName(jobName string) string {
+	return "jobcredentials-" + jobName
+}
+
+//credentialsSecret holds the credentials of the request env vars, keyed by env var name, nil if there are none.
+//It is labelled with the training id like the other secrets of the job, so kill deletes it.
+func credentialsSecret(req *service.JobDeploymentRequest) *v1core.Secret {
+	credentials := make(map[string]string)
+	for k, v := range req.EnvVars {
+		if isCredentialEnvVar(k) {
+			credentials[k] = v
+		}
+	}
+	if len(credentials) == 0 {
+		return nil
+	}
+	return &v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialsSecretName(req.Name),
+			Namespace: config.GetLearnerNamespace(),
+			Labels:    map[string]string{"training_id": req.TrainingId},
+		},
+		Type:       v1core.SecretTypeOpaque,
+		StringData: credentials,
+	}
+}
+
+//credentialEnvVar reads the credential of the env var name from the credentials secret of the job
+func credentialEnvVar(name string, jobName string) v1core.EnvVar {
+	return v1core.EnvVar{
+		Name: name,
+		ValueFrom: &v1core.EnvVarSource{
+			SecretKeyRef: &v1core.SecretKeySelector{
+				Key: name,
+				LocalObjectReference: v1core.LocalObjectReference{
+					Name: credentialsSecretName(jobName),
+				},
+			},
+		},
+	}
+}
+
+//renamedEnvVar passes the value of ev under another name, whether it is a literal or a secret reference
+func renamedEnvVar(name string, ev v1core.EnvVar) v1core.EnvVar {
+	return v1core.EnvVar{Name: name, Value: ev.Value, ValueFrom: ev.ValueFrom}
+}
+
+//redactCredentials is a copy of the request env vars which is safe to log
+func redactCredentials(envVars map[string]string) map[string]string {
+	redacted := make(map[string]string, len(envVars))
+	for k, v := range envVars {
+		if isCredentialEnvVar(k) {
+			v = redactedCredential
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+//requestCredentialsSecretName is the secret with the credentials of the persisted deployment request of a training. It is not
+//labelled with the training id like the secrets of the pods, so it outlives the teardown before a redeploy.
+func requestCredentialsSecretName(trainingID string) string {
+	return "jobrequest-" + trainingID
+}
+
+//the training id of a request credentials secret
+const requestCredentialsLabel = "request_of"
+
+//keys of the credentials of the request which are not env vars, env vars are keyed by their names
+func datasetCredentialKey(i int, field string) string {
+	return fmt.Sprintf("input_datasets.%d.%s", i, field)
+}
+
+func registryCredentialKey(i int, field string) string {
+	if i < 0 {
+		return "image_location." + field
+	}
+	return fmt.Sprintf("registries.%d.%s", i, field)
+}
+
+//imageLocationCredentials are the password and token of the image location and registries of the request, the image location at index -1
+func imageLocationCredentials(req *service.JobDeploymentRequest) map[int]*service.ImageLocation {
+	locations := make(map[int]*service.ImageLocation)
+	if req.ImageLocation != nil {
+		locations[-1] = req.ImageLocation
+	}
+	for i, location := range req.Registries {
+		if location != nil {
+			locations[i] = location
+		}
+	}
+	return locations
+}
+
+//withoutCredentials is a copy of the request without its credentials, which is safe to persist in etcd and the TrainingJob
+//resource. The credentials it lacks are returned, keyed for the request credentials secret.
+func withoutCredentials(req *service.JobDeploymentRequest) (*service.JobDeploymentRequest, map[string]string) {
+	stripped := proto.Clone(req).(*service.JobDeploymentRequest)
+	credentials := make(map[string]string)
+	for k, v := range stripped.EnvVars {
+		if isCredentialEnvVar(k) {
+			credentials[k] = v
+			delete(stripped.EnvVars, k)
+		}
+	}
+	for i, dataset := range stripped.InputDatasets {
+		if dataset.Username != "" {
+			credentials[datasetCredentialKey(i, "username")] = dataset.Username
+		}
+		if dataset.Apikey != "" {
+			credentials[datasetCredentialKey(i, "apikey")] = dataset.Apikey
+		}
+		dataset.Username, dataset.Apikey = "", ""
+	}
+	for i, location := range imageLocationCredentials(stripped) {
+		if location.Password != "" {
+			credentials[registryCredentialKey(i, "password")] = location.Password
+		}
+		if location.AccessToken != "" {
+			credentials[registryCredentialKey(i, "access_token")] = location.AccessToken
+		}
+		location.Password, location.AccessToken = "", ""
+	}
+	stripped.CredentialsStripped = len(credentials) > 0
+	return stripped, credentials
+}
+
+//withCredentials is a copy of the persisted request with the credentials withoutCredentials took out
+func withCredentials(req *service.JobDeploymentRequest, credentials map[string]string) *service.JobDeploymentRequest {
+	restored := proto.Clone(req).(*service.JobDeploymentRequest)
+	restored.CredentialsStripped = false
+	for k, v := range credentials {
+		if isCredentialEnvVar(k) {
+			if restored.EnvVars == nil {
+				restored.EnvVars = make(map[string]string)
+			}
+			restored.EnvVars[k] = v
+		}
+	}
+	for i, dataset := range restored.InputDatasets {
+		if v, ok := credentials[datasetCredentialKey(i, "username")]; ok {
+			dataset.Username = v
+		}
+		if v, ok := credentials[datasetCredentialKey(i, "apikey")]; ok {
+			dataset.Apikey = v
+		}
+	}
+	for i, location := range imageLocationCredentials(restored) {
+		if v, ok := credentials[registryCredentialKey(i, "password")]; ok {
+			location.Password = v
+		}
+		if v, ok := credentials[registryCredentialKey(i, "access_token")]; ok {
+			location.AccessToken = v
+		}
+	}
+	return restored
+}
+
+//saveRequestCredentials keeps the credentials of a request in its request credentials secret and returns the request
+//without them, for the copies of the request which are persisted
+func (s *lcmService) saveRequestCredentials(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) (*service.JobDeploymentRequest, error) {
+	stripped, credentials := withoutCredentials(req)
+	if len(credentials) == 0 {
+		return stripped, nil
+	}
+	data := make(map[string][]byte, len(credentials))
+	for k, v := range credentials {
+		data[k] = []byte(v)
+	}
+	secret := &v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      requestCredentialsSecretName(req.TrainingId),
+			Namespace: config.GetLearnerNamespace(),
+			Labels:    map[string]string{requestCredentialsLabel: req.TrainingId},
+		},
+		Type: v1core.SecretTypeOpaque,
+		Data: data,
+	}
+	err := backoff.RetryNotify(func() error {
+		secrets := s.k8sClient.CoreV1().Secrets(secret.Namespace)
+		_, err := secrets.Create(secret)
+		if k8serrors.IsAlreadyExists(err) {
+			_, err = secrets.Update(secret)
+		}
+		return err
+	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed in creating secret %s while deploying for training", secret.Name)
+		k8sFailureCounter.With(component, "secret").Add(1)
+	})
+	return stripped, err
+}
+
+//restoreRequestCredentials is the persisted request with the credentials of its request credentials secret, the request
+//itself if it had none. A request whose credentials were stripped is rejected when the secret is gone, without them
+//the training would start with empty store credentials.
+func (s *lcmService) restoreRequestCredentials(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) (*service.JobDeploymentRequest, error) {
+	if !req.CredentialsStripped {
+		return req, nil
+	}
+	name := requestCredentialsSecretName(req.TrainingId)
+	var secret *v1core.Secret
+	err := backoff.RetryNotify(func() error {
+		var err error
+		secret, err = s.k8sClient.CoreV1().Secrets(config.GetLearnerNamespace()).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			secret = nil
+			return nil
+		}
+		return err
+	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed to read secret %s while deploying for training", name)
+		k8sFailureCounter.With(component, "secret").Add(1)
+	})
+	if err != nil {
+		return req, err
+	}
+	if secret == nil {
+		logr.Errorf("Secret %s with the credentials of training job %s is missing", name, req.TrainingId)
+		return req, rejectDeployment(client.ErrInvalidCredentials, fmt.Errorf("the credentials of training job %s are missing", req.TrainingId))
+	}
+	credentials := make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		credentials[k] = string(v)
+	}
+	return withCredentials(req, credentials), nil
+}
+
+//deleteRequestCredentials deletes the request credentials secret once the request is no longer needed
+func (s *lcmService) deleteRequestCredentials(trainingID string, logr *logger.LocLoggingEntry) {
+	name := requestCredentialsSecretName(trainingID)
+	err := s.k8sClient.CoreV1().Secrets(config.GetLearnerNamespace()).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logr.WithError(err).Errorf("Failed to delete secret %s of training job %s", name, trainingID)
+	}
+}
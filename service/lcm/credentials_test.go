@@ -0,0 +1,151 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-trainer/client"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func credentialsRequest() *service.JobDeploymentRequest {
+	return &service.JobDeploymentRequest{
+		Name:       "training-1",
+		TrainingId: "training-1",
+		EnvVars: map[string]string{
+			"DATA_STORE_TYPE":       "s3_datastore",
+			"DATA_STORE_OBJECTID":   "mnist",
+			"DATA_STORE_USERNAME":   "user",
+			"DATA_STORE_APIKEY":     "key",
+			"MODEL_STORE_OBJECTID":  "models",
+			"MODEL_STORE_APIKEY":    "key3",
+			"RESULT_STORE_TYPE":     "s3_datastore",
+			"RESULT_STORE_OBJECTID": "results",
+			"RESULT_STORE_APIKEY":   "key2",
+			"DATA_DIR":              "data",
+		},
+	}
+}
+
+func TestCredentialsSecret(t *testing.T) {
+	req := credentialsRequest()
+	secret := credentialsSecret(req)
+	assert.Equal(t, "jobcredentials-training-1", secret.Name)
+	assert.Equal(t, "training-1", secret.Labels["training_id"])
+	assert.Equal(t, map[string]string{"DATA_STORE_USERNAME": "user", "DATA_STORE_APIKEY": "key", "MODEL_STORE_APIKEY": "key3", "RESULT_STORE_APIKEY": "key2"}, secret.StringData)
+
+	redacted := redactCredentials(req.EnvVars)
+	assert.Equal(t, redactedCredential, redacted["DATA_STORE_APIKEY"])
+	assert.Equal(t, "mnist", redacted["DATA_STORE_OBJECTID"])
+	assert.Equal(t, "key", req.EnvVars["DATA_STORE_APIKEY"])
+
+	assert.Nil(t, credentialsSecret(&service.JobDeploymentRequest{EnvVars: map[string]string{"DATA_DIR": "data"}}))
+}
+
+func TestCredentialsOfContainers(t *testing.T) {
+	envVars := extractEnvVarsFromDeploymentRequest(credentialsRequest())
+	mount := v1core.VolumeMount{Name: "jobdata", MountPath: "/job"}
+	containers := []v1core.Container{
+		constructLoadTrainingDataContainer(mount, envVars, true, nil, v1core.ResourceRequirements{}),
+		constructLoadModelContainer(mount, envVars, v1core.ResourceRequirements{}),
+		constructStoreResultsContainer(mount, envVars, v1core.ResourceRequirements{}),
+		constructStoreLogsContainer(mount, envVars, v1core.ResourceRequirements{}),
+	}
+	for _, container := range containers {
+		var credentials int
+		for _, ev := range container.Env {
+			assert.NotEqual(t, "user", ev.Value, container.Name)
+			assert.NotEqual(t, "key", ev.Value, container.Name)
+			assert.NotEqual(t, "key2", ev.Value, container.Name)
+			assert.NotEqual(t, "key3", ev.Value, container.Name)
+			if ev.ValueFrom != nil && ev.ValueFrom.SecretKeyRef != nil {
+				assert.Equal(t, "jobcredentials-training-1", ev.ValueFrom.SecretKeyRef.Name)
+				credentials++
+			}
+		}
+		assert.NotZero(t, credentials, container.Name)
+	}
+	assert.Equal(t, "results/learner-1", getValue(containers[3].Env, "DATA_STORE_BUCKET"))
+}
+
+func TestRequestWithoutCredentials(t *testing.T) {
+	req := credentialsRequest()
+	req.InputDatasets = []*service.InputDataset{{Name: "imagenet", Bucket: "imagenet", Username: "user1", Apikey: "key1"}, {Name: "public", Bucket: "public"}}
+	req.ImageLocation = &service.ImageLocation{Registry: "registry.example.com", Username: "reg", Password: "secret"}
+	req.Registries = []*service.ImageLocation{{Registry: "other.example.com", AccessToken: "token"}}
+
+	stripped, credentials := withoutCredentials(req)
+	assert.NotContains(t, stripped.EnvVars, "DATA_STORE_APIKEY")
+	assert.Equal(t, "mnist", stripped.EnvVars["DATA_STORE_OBJECTID"])
+	assert.Empty(t, stripped.InputDatasets[0].Apikey)
+	assert.Empty(t, stripped.ImageLocation.Password)
+	assert.Equal(t, "reg", stripped.ImageLocation.Username)
+	assert.Empty(t, stripped.Registries[0].AccessToken)
+	assert.True(t, stripped.CredentialsStripped)
+	assert.Equal(t, "key", req.EnvVars["DATA_STORE_APIKEY"], "the request itself keeps its credentials")
+	assert.Equal(t, map[string]string{
+		"DATA_STORE_USERNAME":       "user",
+		"DATA_STORE_APIKEY":         "key",
+		"MODEL_STORE_APIKEY":        "key3",
+		"RESULT_STORE_APIKEY":       "key2",
+		"input_datasets.0.username": "user1",
+		"input_datasets.0.apikey":   "key1",
+		"image_location.password":   "secret",
+		"registries.0.access_token": "token",
+	}, credentials)
+
+	assert.Equal(t, req, withCredentials(stripped, credentials))
+}
+
+func TestSaveAndRestoreRequestCredentials(t *testing.T) {
+	s := &lcmService{k8sClient: fake.NewSimpleClientset()}
+	logr := logger.LocLogger(InitLogger("training-1", "user-1"))
+	req := credentialsRequest()
+
+	stripped, err := s.saveRequestCredentials(req, logr)
+	assert.NoError(t, err)
+	secret, err := s.k8sClient.CoreV1().Secrets(config.GetLearnerNamespace()).Get(requestCredentialsSecretName("training-1"), metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, secret.Labels["training_id"], "the secret survives the teardown before a redeploy")
+
+	restored, err := s.restoreRequestCredentials(stripped, logr)
+	assert.NoError(t, err)
+	assert.Equal(t, req.EnvVars, restored.EnvVars)
+	assert.False(t, restored.CredentialsStripped)
+
+	//the training would start without its store credentials
+	s.deleteRequestCredentials("training-1", logr)
+	_, err = s.restoreRequestCredentials(stripped, logr)
+	assert.Error(t, err)
+	assert.Equal(t, client.ErrInvalidCredentials, deploymentErrorCode(err))
+
+	//requests without credentials never had a secret
+	bare := &service.JobDeploymentRequest{TrainingId: "training-2", EnvVars: map[string]string{"MODEL_DIR": "/model-code"}}
+	stripped, err = s.saveRequestCredentials(bare, logr)
+	assert.NoError(t, err)
+	assert.False(t, stripped.CredentialsStripped)
+	restored, err = s.restoreRequestCredentials(stripped, logr)
+	assert.NoError(t, err)
+	assert.Equal(t, stripped, restored)
+}
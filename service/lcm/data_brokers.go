@@ -0,0 +1,57 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/databrokers"
+)
+
+//validateDataStores rejects store types without a data broker for what the helper does with the store
+func validateDataStores(req *service.JobDeploymentRequest) error {
+	if !dataStoreUnused(req) {
+		if err := validateDataStore(req, "DATA_STORE_", databrokers.LoadData); err != nil {
+			return err
+		}
+	}
+	if mountProvider(req.EnvVars["RESULT_STORE_TYPE"]) != nil || req.EnvVars["RESULT_STORE_OBJECTID"] == noResultBucketTag {
+		return nil
+	}
+	if err := validateDataStore(req, "MODEL_STORE_", databrokers.LoadModel); err != nil {
+		return err
+	}
+	return validateDataStore(req, "RESULT_STORE_", databrokers.StoreResults, databrokers.StoreLogs)
+}
+
+func validateDataStore(req *service.JobDeploymentRequest, prefix string, ops ...databrokers.Operation) error {
+	storeType := req.EnvVars[prefix+"TYPE"]
+	if mountProvider(storeType) != nil {
+		return nil
+	}
+	broker, known := databrokers.Lookup(storeType)
+	if !known {
+		return fmt.Errorf("there is no data broker for %sTYPE %s", prefix, storeType)
+	}
+	for _, op := range ops {
+		if !broker.Supports(op) {
+			return fmt.Errorf("the %s data broker of %sTYPE %s does not support %s", broker.Type, prefix, storeType, op)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+)
+
+func TestValidateDataStores(t *testing.T) {
+	req := &service.JobDeploymentRequest{EnvVars: map[string]string{
+		"DATA_STORE_TYPE":   "s3_datastore",
+		"MODEL_STORE_TYPE":  "s3_datastore",
+		"RESULT_STORE_TYPE": "s3_datastore",
+	}}
+	assert.NoError(t, validateDataStores(req))
+
+	req.EnvVars["DATA_STORE_TYPE"] = "git"
+	assert.NoError(t, validateDataStores(req))
+
+	req.EnvVars["RESULT_STORE_TYPE"] = "http"
+	assert.Error(t, validateDataStores(req))
+
+	req.EnvVars["RESULT_STORE_TYPE"] = cosMountType
+	req.EnvVars["MODEL_STORE_TYPE"] = "unknown"
+	assert.NoError(t, validateDataStores(req))
+
+	req.EnvVars["DATA_STORE_TYPE"] = "unknown"
+	assert.Error(t, validateDataStores(req))
+
+	req.DatasetVolumes = []*service.DatasetVolume{{ClaimName: "data", MountPath: "/mnt/data"}}
+	assert.NoError(t, validateDataStores(req))
+}
+
+func TestLoadTrainingDataContainerOfBroker(t *testing.T) {
+	mount := v1core.VolumeMount{Name: "jobdata", MountPath: "/job"}
+	container := constructLoadTrainingDataContainer(mount, []v1core.EnvVar{
+		{Name: "DATA_STORE_TYPE", Value: "git"},
+		{Name: "DATA_STORE_OBJECTID", Value: "https://example.com/data.git"},
+		{Name: "DATA_STORE_APIKEY", Value: "token"},
+		{Name: "DATA_DIR", Value: "data"},
+	}, true, nil, v1core.ResourceRequirements{})
+	assert.Contains(t, container.Image, "databroker_git:")
+	assert.Equal(t, "https://example.com/data.git", getValue(container.Env, "DATA_STORE_REPOSITORY"))
+	assert.Equal(t, "token", getValue(container.Env, "DATA_STORE_TOKEN"))
+	assert.Equal(t, "/job/data", getValue(container.Env, "DATA_DIR"))
+}
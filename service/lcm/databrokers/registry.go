@@ -0,0 +1,128 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package databrokers
+
+import (
+	"strings"
+)
+
+//Operation ... what a helper container does with a store
+type Operation string
+
+const (
+	//LoadData downloads the training data from DATA_STORE_ into the job volume
+	LoadData Operation = "load_data"
+	//LoadModel downloads the model definition from MODEL_STORE_
+	LoadModel Operation = "load_model"
+	//StoreResults uploads the results to RESULT_STORE_
+	StoreResults Operation = "store_results"
+	//StoreLogs uploads the learner logs to RESULT_STORE_
+	StoreLogs Operation = "store_logs"
+)
+
+//DefaultType is the broker of stores without a type
+const DefaultType = "objectstorage"
+
+//the suffix of store types read from the datastore .ini files, e.g. s3_datastore
+const typeSuffix = "_datastore"
+
+//Broker ... a data broker image and how the store env vars of a job are passed to it. The broker sees the
+//<prefix>_STORE_<name> vars of the job as DATA_STORE_<name>, renamed by the defaults of the operation and EnvVars
+type Broker struct {
+	Type string
+	//Aliases are other store types served by the broker
+	Aliases []string
+	//Image is the type of the databroker_<type> image
+	Image      string
+	Operations []Operation
+	//EnvVars renames store vars for the broker, e.g. OBJECTID to REPOSITORY, after the defaults of the operation
+	EnvVars map[string]string
+}
+
+//Registry ... the known data brokers, store types are matched case insensitive
+type Registry struct {
+	Brokers []Broker
+}
+
+var allOperations = []Operation{LoadData, LoadModel, StoreResults, StoreLogs}
+
+var defaultRegistry = Registry{
+	Brokers: []Broker{
+		{Type: "objectstorage", Aliases: []string{"softlayer_objectstorage", "swift"}, Image: "objectstorage", Operations: allOperations},
+		{Type: "s3", Aliases: []string{"cos"}, Image: "s3", Operations: allOperations},
+		{Type: "gcs", Image: "gcs", Operations: allOperations},
+		{Type: "http", Aliases: []string{"https"}, Image: "http", Operations: []Operation{LoadData, LoadModel},
+			EnvVars: map[string]string{"BUCKET": "URL", "OBJECT": "URL"}},
+		{Type: "nfs", Image: "nfs", Operations: allOperations,
+			EnvVars: map[string]string{"AUTHURL": "SERVER", "BUCKET": "PATH", "OBJECT": "PATH"}},
+		{Type: "git", Image: "git", Operations: []Operation{LoadData, LoadModel},
+			EnvVars: map[string]string{"PASSWORD": "TOKEN", "BUCKET": "REPOSITORY", "OBJECT": "REPOSITORY"}},
+	},
+}
+
+//Lookup returns the broker of the store type, the default broker for an empty type and false for unknown types
+func Lookup(storeType string) (Broker, bool) {
+	return defaultRegistry.Lookup(storeType)
+}
+
+//Lookup ...
+func (r *Registry) Lookup(storeType string) (Broker, bool) {
+	storeType = strings.TrimSuffix(strings.ToLower(storeType), typeSuffix)
+	if storeType == "" {
+		storeType = DefaultType
+	}
+	for _, b := range r.Brokers {
+		if b.Type == storeType {
+			return b, true
+		}
+		for _, alias := range b.Aliases {
+			if alias == storeType {
+				return b, true
+			}
+		}
+	}
+	return Broker{}, false
+}
+
+//Supports is true if the broker can do the operation
+func (b Broker) Supports(op Operation) bool {
+	for _, o := range b.Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+//EnvVarName is the name the broker gets the store var with the name suffix under, e.g. APIKEY is DATA_STORE_PASSWORD
+func (b Broker) EnvVarName(op Operation, name string) string {
+	switch {
+	case name == "APIKEY":
+		name = "PASSWORD"
+	case op == LoadData && strings.HasPrefix(name, "OBJECTID"):
+		//all buckets of a training with more than one data store go to the same var
+		name = "BUCKET"
+	case name == "OBJECTID" && op == LoadModel:
+		name = "OBJECT"
+	case name == "OBJECTID":
+		name = "BUCKET"
+	}
+	if renamed, ok := b.EnvVars[name]; ok {
+		name = renamed
+	}
+	return "DATA_STORE_" + name
+}
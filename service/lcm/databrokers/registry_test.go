@@ -0,0 +1,64 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package databrokers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	broker, known := Lookup("s3_datastore")
+	assert.True(t, known)
+	assert.Equal(t, "s3", broker.Image)
+
+	broker, known = Lookup("softlayer_objectstorage")
+	assert.True(t, known)
+	assert.Equal(t, "objectstorage", broker.Image)
+
+	broker, known = Lookup("")
+	assert.True(t, known)
+	assert.Equal(t, DefaultType, broker.Type)
+
+	broker, known = Lookup("HTTPS")
+	assert.True(t, known)
+	assert.True(t, broker.Supports(LoadData))
+	assert.False(t, broker.Supports(StoreResults))
+
+	_, known = Lookup("ftp")
+	assert.False(t, known)
+}
+
+func TestEnvVarName(t *testing.T) {
+	s3, _ := Lookup("s3")
+	assert.Equal(t, "DATA_STORE_PASSWORD", s3.EnvVarName(LoadData, "APIKEY"))
+	assert.Equal(t, "DATA_STORE_BUCKET", s3.EnvVarName(LoadData, "OBJECTID_2"))
+	assert.Equal(t, "DATA_STORE_OBJECT", s3.EnvVarName(LoadModel, "OBJECTID"))
+	assert.Equal(t, "DATA_STORE_BUCKET", s3.EnvVarName(StoreResults, "OBJECTID"))
+	assert.Equal(t, "DATA_STORE_OBJECTID_2", s3.EnvVarName(StoreResults, "OBJECTID_2"))
+	assert.Equal(t, "DATA_STORE_AUTHURL", s3.EnvVarName(LoadData, "AUTHURL"))
+
+	git, _ := Lookup("git")
+	assert.Equal(t, "DATA_STORE_REPOSITORY", git.EnvVarName(LoadData, "OBJECTID"))
+	assert.Equal(t, "DATA_STORE_REPOSITORY", git.EnvVarName(LoadModel, "OBJECTID"))
+	assert.Equal(t, "DATA_STORE_TOKEN", git.EnvVarName(LoadModel, "APIKEY"))
+
+	nfs, _ := Lookup("nfs")
+	assert.Equal(t, "DATA_STORE_SERVER", nfs.EnvVarName(StoreLogs, "AUTHURL"))
+	assert.Equal(t, "DATA_STORE_PATH", nfs.EnvVarName(StoreLogs, "OBJECTID"))
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+
+	"github.com/cenkalti/backoff"
+	v1core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func datasetVolumeID(jobName string, index int) string {
+	return fmt.Sprintf("dataset-%d-%s", index, jobName)
+}
+
+//learnerDatasetVolumes are the PVCs and NFS exports of the request the learners mount their training data from
+func learnerDatasetVolumes(req *service.JobDeploymentRequest) []*learner.DatasetVolume {
+	var volumes []*learner.DatasetVolume
+	for i, v := range req.DatasetVolumes {
+		volumes = append(volumes, &learner.DatasetVolume{
+			ID:        datasetVolumeID(req.Name, i),
+			ClaimName: v.ClaimName,
+			NFSServer: v.NfsServer,
+			NFSPath:   v.NfsPath,
+			ReadOnly:  !v.ReadWrite,
+			MountSpec: learner.VolumeMountSpec{MountPath: v.MountPath, SubPath: v.SubPath},
+		})
+	}
+	return volumes
+}
+
+//dataStoreUnused is true if all training data is on dataset volumes or in input datasets, the DATA_STORE_ of the job has no bucket
+func dataStoreUnused(req *service.JobDeploymentRequest) bool {
+	if len(req.DatasetVolumes) == 0 && len(req.InputDatasets) == 0 {
+		return false
+	}
+	for _, bucket := range getDatastoreBuckets(req.EnvVars) {
+		if bucket != "" {
+			return false
+		}
+	}
+	return true
+}
+
+//validateDatasetVolumes rejects dataset volumes that are neither a PVC nor an NFS export and mount paths used twice
+func validateDatasetVolumes(req *service.JobDeploymentRequest) error {
+	mountPaths := make(map[string]bool)
+	for i, v := range req.DatasetVolumes {
+		isClaim := v.ClaimName != ""
+		isNFS := v.NfsServer != "" || v.NfsPath != ""
+		if isClaim == isNFS {
+			return fmt.Errorf("dataset volume %d needs either a claim name or an nfs server and path", i)
+		}
+		if isNFS && (v.NfsServer == "" || !path.IsAbs(v.NfsPath)) {
+			return fmt.Errorf("dataset volume %d needs an nfs server and an absolute nfs path", i)
+		}
+		if !path.IsAbs(v.MountPath) {
+			return fmt.Errorf("mount path %q of dataset volume %d is not an absolute path", v.MountPath, i)
+		}
+		if path.IsAbs(v.SubPath) {
+			return fmt.Errorf("sub path %q of dataset volume %d has to be relative", v.SubPath, i)
+		}
+		mountPath := path.Clean(v.MountPath)
+		if mountPaths[mountPath] {
+			return fmt.Errorf("mount path %s is used by more than one dataset volume", mountPath)
+		}
+		mountPaths[mountPath] = true
+	}
+	return nil
+}
+
+//label admins set to "true" on the claims of datasets every user may mount, they are always mounted read-only
+const sharedDatasetLabel = "shared_dataset"
+
+//checkDatasetClaimOwner rejects the claims of trainings and static volumes and claims that are neither shared nor labelled
+//for the user of the request, a training must not mount the data of another user
+func checkDatasetClaimOwner(claim *v1core.PersistentVolumeClaim, v *service.DatasetVolume, userID string, staticVolumes []staticVolume) error {
+	if _, ok := claim.Labels["training_id"]; ok {
+		return fmt.Errorf("dataset volume claim %s belongs to a training", claim.Name)
+	}
+	for _, volume := range staticVolumes {
+		if volume.Name == claim.Name {
+			return fmt.Errorf("dataset volume claim %s is a static volume", claim.Name)
+		}
+	}
+	if claim.Labels[sharedDatasetLabel] == "true" {
+		if v.ReadWrite {
+			return fmt.Errorf("shared dataset volume claim %s can only be mounted read-only", claim.Name)
+		}
+		return nil
+	}
+	if owner := claim.Labels[ownerLabel]; owner == "" || owner != userID {
+		return fmt.Errorf("dataset volume claim %s is neither labelled %s=true nor %s=%s", claim.Name, sharedDatasetLabel, ownerLabel, userID)
+	}
+	return nil
+}
+
+//checkDatasetClaims makes sure the claims of the dataset volumes exist in the learner namespace and may be mounted by the user,
+//the learners would be stuck in pending otherwise
+func (s *lcmService) checkDatasetClaims(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) error {
+	namespace := config.GetLearnerNamespace()
+	var staticVolumes []staticVolume
+	if len(req.DatasetVolumes) > 0 {
+		staticVolumes = loadStaticVolumes(logr)
+	}
+	for _, v := range req.DatasetVolumes {
+		if v.ClaimName == "" {
+			continue
+		}
+		var claim *v1core.PersistentVolumeClaim
+		var notFound bool
+		err := backoff.RetryNotify(func() error {
+			var err error
+			claim, err = s.k8sClient.CoreV1().PersistentVolumeClaims(namespace).Get(v.ClaimName, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				notFound = true
+				return nil
+			}
+			return err
+		}, k8sRequestBackoff(), func(err error, window time.Duration) {
+			logr.WithError(err).Errorf("Failed in getting dataset volume claim %s", v.ClaimName)
+			k8sFailureCounter.With(component, "pvc").Add(1)
+		})
+		if err != nil {
+			return err
+		}
+		if notFound {
+			return fmt.Errorf("dataset volume claim %s does not exist in namespace %s", v.ClaimName, namespace)
+		}
+		if err := checkDatasetClaimOwner(claim, v, req.UserId, staticVolumes); err != nil {
+			return err
+		}
+		if claim.Status.Phase == v1core.ClaimLost {
+			return fmt.Errorf("dataset volume claim %s lost its volume", v.ClaimName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateDatasetVolumes(t *testing.T) {
+	req := &service.JobDeploymentRequest{
+		DatasetVolumes: []*service.DatasetVolume{
+			{ClaimName: "imagenet", MountPath: "/mnt/data/imagenet"},
+			{NfsServer: "nfs.example.com", NfsPath: "/exports/coco", MountPath: "/mnt/data/coco", SubPath: "2017"},
+		},
+	}
+	assert.NoError(t, validateDatasetVolumes(req))
+
+	req.DatasetVolumes[1].MountPath = "/mnt/data/imagenet/"
+	assert.Error(t, validateDatasetVolumes(req))
+
+	req.DatasetVolumes[1].MountPath = "/mnt/data/coco"
+	req.DatasetVolumes[1].ClaimName = "coco"
+	assert.Error(t, validateDatasetVolumes(req))
+
+	req.DatasetVolumes[1] = &service.DatasetVolume{NfsPath: "/exports/coco", MountPath: "/mnt/data/coco"}
+	assert.Error(t, validateDatasetVolumes(req))
+
+	req.DatasetVolumes[1] = &service.DatasetVolume{ClaimName: "coco", MountPath: "data"}
+	assert.Error(t, validateDatasetVolumes(req))
+}
+
+func TestDataStoreUnused(t *testing.T) {
+	req := &service.JobDeploymentRequest{
+		Name:           "training-1",
+		EnvVars:        map[string]string{"DATA_STORE_OBJECTID": "bucket"},
+		DatasetVolumes: []*service.DatasetVolume{{ClaimName: "imagenet", MountPath: "/mnt/data", SubPath: "train"}},
+	}
+	assert.False(t, dataStoreUnused(req))
+
+	req.EnvVars = map[string]string{}
+	assert.True(t, dataStoreUnused(req))
+
+	volumes := learner.Volumes{DatasetVolumes: learnerDatasetVolumes(req)}
+	specs := volumes.CreateVolumeForLearner()
+	assert.Len(t, specs, 1)
+	assert.Equal(t, "dataset-0-training-1", specs[0].Name)
+	assert.Equal(t, "imagenet", specs[0].PersistentVolumeClaim.ClaimName)
+	assert.True(t, specs[0].PersistentVolumeClaim.ReadOnly)
+	mounts := volumes.CreateVolumeMountsForLearner()
+	assert.Len(t, mounts, 1)
+	assert.Equal(t, "/mnt/data", mounts[0].MountPath)
+	assert.Equal(t, "train", mounts[0].SubPath)
+	assert.True(t, mounts[0].ReadOnly)
+
+	req.DatasetVolumes[0].ReadWrite = true
+	volumes = learner.Volumes{DatasetVolumes: learnerDatasetVolumes(req)}
+	assert.False(t, volumes.CreateVolumeForLearner()[0].PersistentVolumeClaim.ReadOnly)
+
+	req.DatasetVolumes = nil
+	assert.False(t, dataStoreUnused(req))
+}
+
+func TestCheckDatasetClaimOwner(t *testing.T) {
+	claim := func(labels map[string]string) *v1core.PersistentVolumeClaim {
+		return &v1core.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "imagenet", Labels: labels}}
+	}
+	volume := &service.DatasetVolume{ClaimName: "imagenet", MountPath: "/mnt/data"}
+
+	assert.NoError(t, checkDatasetClaimOwner(claim(map[string]string{ownerLabel: "user-1"}), volume, "user-1", nil))
+	assert.NoError(t, checkDatasetClaimOwner(claim(map[string]string{sharedDatasetLabel: "true"}), volume, "user-1", nil))
+	assert.Error(t, checkDatasetClaimOwner(claim(nil), volume, "user-1", nil))
+	assert.Error(t, checkDatasetClaimOwner(claim(map[string]string{ownerLabel: "user-2"}), volume, "user-1", nil))
+	//the shared volumes of trainings and the static volume pool
+	assert.Error(t, checkDatasetClaimOwner(claim(map[string]string{ownerLabel: "user-1", "training_id": "training-2"}), volume, "user-1", nil))
+	assert.Error(t, checkDatasetClaimOwner(claim(map[string]string{ownerLabel: "user-1"}), volume, "user-1", []staticVolume{{Name: "imagenet"}}))
+
+	volume.ReadWrite = true
+	assert.NoError(t, checkDatasetClaimOwner(claim(map[string]string{ownerLabel: "user-1"}), volume, "user-1", nil))
+	assert.Error(t, checkDatasetClaimOwner(claim(map[string]string{sharedDatasetLabel: "true"}), volume, "user-1", nil))
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/frameworks"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	"github.com/AISphere/ffdl-lcm/service/lcm/trainingjob"
+
+	"github.com/cenkalti/backoff"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+
+	"k8s.io/api/apps/v1beta1"
+	v1core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//annotations of the learner statefulset of an elastic training, ScaleTrainingJob only gets the name of the job
+const (
+	minLearnersAnnotation    = "ffdl.aisphere.io/min-learners"
+	maxLearnersAnnotation    = "ffdl.aisphere.io/max-learners"
+	gpusPerLearnerAnnotation = "ffdl.aisphere.io/gpus-per-learner"
+	mpiHostfileAnnotation    = "ffdl.aisphere.io/mpi-hostfile"
+)
+
+//configmap with the current learners of an elastic training, mounted into every learner
+func learnersConfigName(jobName string) string {
+	return fmt.Sprintf("learners-%s", jobName)
+}
+
+//elasticBounds are the min and max learners of a training, false if the training can not be scaled
+func elasticBounds(req *service.JobDeploymentRequest) (int, int, bool) {
+	resources := req.GetResources()
+	if resources.GetMinLearners() == 0 && resources.GetMaxLearners() == 0 {
+		return 0, 0, false
+	}
+	if fw, _ := frameworks.Lookup(req.Framework, req.Version); !fw.Elastic || len(req.ReplicaGroups) > 0 {
+		return 0, 0, false
+	}
+	minLearners, maxLearners := int(resources.MinLearners), int(resources.MaxLearners)
+	if minLearners < 1 {
+		minLearners = 1
+	}
+	if maxLearners < 1 {
+		maxLearners = totalLearners(req)
+	}
+	return minLearners, maxLearners, true
+}
+
+//validateElasticBounds rejects bounds for frameworks that can not pick up a changed number of learners
+func validateElasticBounds(req *service.JobDeploymentRequest) error {
+	resources := req.GetResources()
+	if resources.GetMinLearners() == 0 && resources.GetMaxLearners() == 0 {
+		return nil
+	}
+	if len(req.ReplicaGroups) > 0 {
+		return fmt.Errorf("min and max learners are not supported for trainings with replica groups")
+	}
+	if fw, _ := frameworks.Lookup(req.Framework, req.Version); !fw.Elastic {
+		return fmt.Errorf("framework %s %s does not support elastic training, min and max learners can not be set", req.Framework, req.Version)
+	}
+	minLearners, maxLearners, _ := elasticBounds(req)
+	if learners := totalLearners(req); learners < minLearners || learners > maxLearners {
+		return fmt.Errorf("%d learners are not within the min learners %d and max learners %d", learners, minLearners, maxLearners)
+	}
+	return nil
+}
+
+//maxLearnerPods is the number of learner pods an elastic training can grow to, learnerPods for all other trainings
+func maxLearnerPods(req *service.JobDeploymentRequest) int {
+	_, maxLearners, elastic := elasticBounds(req)
+	if !elastic {
+		return learnerPods(req)
+	}
+	if usesMPILauncher(req) {
+		return maxLearners + 1
+	}
+	return maxLearners
+}
+
+//addElasticAnnotations adds what ScaleTrainingJob needs to know about the learners to the annotations of their
+//statefulset, nothing if the training can not be scaled
+func addElasticAnnotations(meta *metav1.ObjectMeta, req *service.JobDeploymentRequest) {
+	minLearners, maxLearners, elastic := elasticBounds(req)
+	if !elastic {
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[minLearnersAnnotation] = strconv.Itoa(minLearners)
+	meta.Annotations[maxLearnersAnnotation] = strconv.Itoa(maxLearners)
+	meta.Annotations[gpusPerLearnerAnnotation] = strconv.Itoa(int(req.GetResources().Gpus))
+	if usesMPILauncher(req) {
+		meta.Annotations[mpiHostfileAnnotation] = mpiHostfileName(req.Name)
+	}
+}
+
+//learnersConfigMaps is the learners configmap of an elastic training, none for all other trainings
+func learnersConfigMaps(req *service.JobDeploymentRequest) []*v1core.ConfigMap {
+	if _, _, elastic := elasticBounds(req); !elastic {
+		return nil
+	}
+	return []*v1core.ConfigMap{learner.CreateLearnersConfigMap(learnersConfigName(req.Name), req.TrainingId, learnerCluster(req))}
+}
+
+//ScaleTrainingJob changes the number of learners of a running elastic training job within the bounds it was deployed with.
+//The learners find the new number in the learners configmap, their env vars keep the number they were deployed with
+func (s *lcmService) ScaleTrainingJob(ctx context.Context, req *service.JobScaleRequest) (*service.JobScaleResponse, error) {
+	logr := logger.LocLogger(InitLogger(req.TrainingId, req.UserId))
+	logr.Infof("Scaling training job %s to %d learners", req.TrainingId, req.Learners)
+
+	//a suspended training restores the replicas it had before the suspend when it is resumed
+	response, err := s.etcdClient.Get(req.TrainingId+"/"+zkSuspend, logr)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to check if training job %s is suspended", req.TrainingId)
+		return nil, err
+	}
+	if len(response) > 0 {
+		return nil, gerrf(codes.FailedPrecondition, "training job %s is suspended, it can be scaled once it is resumed", req.TrainingId)
+	}
+
+	set, err := s.elasticLearnerStatefulSet(req, logr)
+	if err != nil {
+		return nil, err
+	}
+	previous := int32(1)
+	if set.Spec.Replicas != nil {
+		previous = *set.Spec.Replicas
+	}
+
+	//the learners and the job monitor must know the new number before the statefulset adds or removes learners,
+	//the number they were told is restored when a step fails so it keeps matching the learners that are running
+	if err := s.updateLearnerCount(req, set, req.Learners, logr); err != nil {
+		s.restoreLearnerCount(req, set, previous, logr)
+		return nil, err
+	}
+	if err := s.scaleLearnerStatefulSet(set.Name, req.Learners, logr); err != nil {
+		s.restoreLearnerCount(req, set, previous, logr)
+		return nil, err
+	}
+
+	if s.trainingJobs != nil {
+		err := s.updateTrainingJobSpec(req.TrainingId, func(spec *trainingjob.TrainingJobSpec) {
+			if spec.DeploymentRequest != nil && spec.DeploymentRequest.Resources != nil {
+				spec.DeploymentRequest.Resources.Learners = req.Learners
+			}
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			logr.WithError(err).Warnf("Failed to record the number of learners on the TrainingJob resource of training job %s", req.TrainingId)
+		}
+	}
+
+	return &service.JobScaleResponse{Learners: req.Learners}, nil
+}
+
+//gets the learner statefulset of a training and checks the request against the bounds in its annotations
+func (s *lcmService) elasticLearnerStatefulSet(req *service.JobScaleRequest, logr *logger.LocLoggingEntry) (*v1beta1.StatefulSet, error) {
+	learnerName := fmt.Sprintf("learner-%s", req.Name)
+	set, err := s.k8sClient.AppsV1beta1().StatefulSets(config.GetLearnerNamespace()).Get(learnerName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) || (err == nil && set.Labels["training_id"] != req.TrainingId) {
+		return nil, gerrf(codes.NotFound, "no learners found for training job %s", req.TrainingId)
+	}
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to get the learner statefulset %s", learnerName)
+		k8sFailureCounter.With(component, "learner").Add(1)
+		return nil, err
+	}
+
+	minLearners, minErr := strconv.Atoi(set.Annotations[minLearnersAnnotation])
+	maxLearners, maxErr := strconv.Atoi(set.Annotations[maxLearnersAnnotation])
+	if minErr != nil || maxErr != nil {
+		return nil, gerrf(codes.FailedPrecondition, "training job %s was not deployed with min and max learners of an elastic framework", req.TrainingId)
+	}
+	if int(req.Learners) < minLearners || int(req.Learners) > maxLearners {
+		return nil, gerrf(codes.InvalidArgument, "%d learners are not within the min learners %d and max learners %d of training job %s", req.Learners, minLearners, maxLearners, req.TrainingId)
+	}
+	return set, nil
+}
+
+//writes the number of learners to the learners configmap, the hostfile of MPI trainings and etcd for the job monitor
+func (s *lcmService) updateLearnerCount(req *service.JobScaleRequest, set *v1beta1.StatefulSet, learners int32, logr *logger.LocLoggingEntry) error {
+	gpusPerLearner, _ := strconv.Atoi(set.Annotations[gpusPerLearnerAnnotation])
+	cluster := learner.Cluster{
+		StatefulSetName: set.Name,
+		ServiceName:     set.Spec.ServiceName,
+		NumLearners:     int(learners),
+		GPUsPerLearner:  gpusPerLearner,
+	}
+	if err := s.updateConfigMapData(learnersConfigName(req.Name), learner.LearnersConfigData(cluster), logr); err != nil {
+		return err
+	}
+
+	numLearners := int(learners)
+	if hostfile := set.Annotations[mpiHostfileAnnotation]; hostfile != "" {
+		if err := s.updateConfigMapData(hostfile, learner.MPIHostfileData(cluster), logr); err != nil {
+			return err
+		}
+		numLearners++ //the launcher
+	}
+
+	//the job monitor picks up the new number of learners on its next poll
+	path := req.TrainingId + "/" + zkLearners + "/" + zkTotLearners
+	if _, err := s.etcdClient.Put(path, strconv.Itoa(numLearners), logr); err != nil {
+		logr.WithError(err).Errorf("Failed to update the number of learners on path %s for training job %s", path, req.TrainingId)
+		return err
+	}
+	return nil
+}
+
+//puts the number of learners the statefulset still runs back after a failed scale
+func (s *lcmService) restoreLearnerCount(req *service.JobScaleRequest, set *v1beta1.StatefulSet, learners int32, logr *logger.LocLoggingEntry) {
+	if err := s.updateLearnerCount(req, set, learners, logr); err != nil {
+		logr.WithError(err).Errorf("Failed to restore the number of learners of training job %s to %d", req.TrainingId, learners)
+	}
+}
+
+//sets the replicas of the learner statefulset, it is read again when the update conflicts with another change
+func (s *lcmService) scaleLearnerStatefulSet(learnerName string, replicas int32, logr *logger.LocLoggingEntry) error {
+	sets := s.k8sClient.AppsV1beta1().StatefulSets(config.GetLearnerNamespace())
+
+	var err error
+	for i := 0; i < numRetries; i++ {
+		var set *v1beta1.StatefulSet
+		set, err = sets.Get(learnerName, metav1.GetOptions{})
+		if err != nil {
+			break
+		}
+		set.Spec.Replicas = &replicas
+		if _, err = sets.Update(set); err == nil {
+			logr.Infof("Scaled learner statefulset %s to %d replicas", learnerName, replicas)
+			return nil
+		}
+		if !k8serrors.IsConflict(err) {
+			break
+		}
+	}
+	logr.WithError(err).Errorf("Failed to scale the learner statefulset %s", learnerName)
+	k8sFailureCounter.With(component, "learner").Add(1)
+	return err
+}
+
+//replaces the data of a configmap of the training, the learners see the new content once the kubelet syncs the mounted volume
+func (s *lcmService) updateConfigMapData(name string, data map[string]string, logr *logger.LocLoggingEntry) error {
+	configMaps := s.k8sClient.CoreV1().ConfigMaps(config.GetLearnerNamespace())
+	return backoff.RetryNotify(func() error {
+		configMap, err := configMaps.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		configMap.Data = data
+		_, err = configMaps.Update(configMap)
+		return err
+	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed in updating config map %s while scaling training", name)
+		k8sFailureCounter.With(component, "configmap").Add(1)
+	})
+}
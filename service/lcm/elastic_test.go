@@ -0,0 +1,69 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestElasticBounds(t *testing.T) {
+	req := &service.JobDeploymentRequest{
+		Name:       "training-job3",
+		TrainingId: "training-3",
+		Framework:  "pytorch",
+		Version:    "1.0",
+		Resources:  &service.ResourceRequirements{Gpus: 2, Learners: 2, MinLearners: 1, MaxLearners: 4},
+	}
+	assert.NoError(t, validateElasticBounds(req))
+	minLearners, maxLearners, elastic := elasticBounds(req)
+	assert.True(t, elastic)
+	assert.Equal(t, 1, minLearners)
+	assert.Equal(t, 4, maxLearners)
+	assert.Equal(t, 4, maxLearnerPods(req))
+	//the annotations of the statefulset spec are kept
+	meta := metav1.ObjectMeta{Annotations: map[string]string{"kept": "true"}}
+	addElasticAnnotations(&meta, req)
+	assert.Equal(t, "4", meta.Annotations[maxLearnersAnnotation])
+	assert.Equal(t, "2", meta.Annotations[gpusPerLearnerAnnotation])
+	assert.Equal(t, "true", meta.Annotations["kept"])
+	assert.Len(t, learnersConfigMaps(req), 1)
+
+	req.Resources.Learners = 5
+	assert.Error(t, validateElasticBounds(req))
+
+	//horovod learners are the workers of the mpi launcher
+	req.Framework, req.Version, req.Resources.Learners = "horovod", "0.13.10", 2
+	assert.Equal(t, 5, maxLearnerPods(req))
+	addElasticAnnotations(&meta, req)
+	assert.Equal(t, "mpi-hostfile-training-job3", meta.Annotations[mpiHostfileAnnotation])
+
+	req.Framework = "caffe"
+	assert.Error(t, validateElasticBounds(req))
+	_, _, elastic = elasticBounds(req)
+	assert.False(t, elastic)
+	meta = metav1.ObjectMeta{}
+	addElasticAnnotations(&meta, req)
+	assert.Nil(t, meta.Annotations)
+
+	req.Resources.MinLearners, req.Resources.MaxLearners = 0, 0
+	assert.NoError(t, validateElasticBounds(req))
+	assert.Equal(t, 2, maxLearnerPods(req))
+}
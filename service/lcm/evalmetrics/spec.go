@@ -0,0 +1,286 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evalmetrics
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+//Collector ... a log collector image and the evaluation metrics types it extracts
+type Collector struct {
+	Type string
+	//Aliases are other types served by the collector, matched case insensitive
+	Aliases []string
+	//Image is the short name of the collector image
+	Image string
+	//NeedsIn collectors read the file or directory in `in`, tensorboard also takes tensorboard_dirs
+	NeedsIn bool
+	//NeedsGroups collectors extract the metrics with the regex groups
+	NeedsGroups bool
+}
+
+//DefaultType is the collector of trainings without evaluation metrics, it only collects the logs
+const DefaultType = "log_collector"
+
+var collectors = []Collector{
+	{Type: DefaultType, Aliases: []string{"null", "nil", "logger", "none"}, Image: "log_collector"},
+	{Type: "emetrics_file_extractor", Aliases: []string{"optivist", "emetrics_file", "file"}, Image: "emetrics_file_extractor"},
+	{Type: "regex_extractor", Aliases: []string{"regex"}, Image: "regex_extractor", NeedsIn: true, NeedsGroups: true},
+	{Type: "tensorboard", Aliases: []string{"tensorboard_extractor", "tensorboard_extract"}, Image: "tensorboard_extract", NeedsIn: true},
+}
+
+//LookupCollector returns the collector of the type or one of its aliases, false for unknown types
+func LookupCollector(collectorType string) (Collector, bool) {
+	t := strings.ToLower(strings.TrimSpace(collectorType))
+	for _, c := range collectors {
+		if c.Type == t {
+			return c, true
+		}
+		for _, alias := range c.Aliases {
+			if alias == t {
+				return c, true
+			}
+		}
+	}
+	return Collector{}, false
+}
+
+//Group ... a regex matched against the log lines and how its named groups become metrics
+type Group struct {
+	Regex string `yaml:"regex" json:"regex"`
+	//Meta are values recorded with every match, like the time of the line
+	Meta map[string]string `yaml:"meta,omitempty" json:"meta,omitempty"`
+	//Scalars are the metrics, by the named regex group they are read from
+	Scalars map[string]string `yaml:"scalars,omitempty" json:"scalars,omitempty"`
+	//Etimes are the points in training a match belongs to, like the iteration or epoch
+	Etimes map[string]string `yaml:"etimes,omitempty" json:"etimes,omitempty"`
+}
+
+//Spec ... the evaluation_metrics section of a training manifest
+type Spec struct {
+	Type string `yaml:"type"`
+	//ImageTag overrides the tag of the collector image
+	ImageTag string `yaml:"image_tag,omitempty"`
+	//In is the log file or tensorboard directory the metrics are read from, Out is where the collector writes them
+	In              string           `yaml:"in,omitempty"`
+	Out             string           `yaml:"out,omitempty"`
+	LineLookahead   int              `yaml:"line_lookahead,omitempty"`
+	EvalMetricNames []string         `yaml:"eval_metric_names,omitempty"`
+	Groups          map[string]Group `yaml:"groups,omitempty"`
+	//TensorboardDirs are read by the tensorboard collector in addition to In
+	TensorboardDirs []string `yaml:"tensorboard_dirs,omitempty"`
+}
+
+//the keys of the evaluation_metrics section, imagetag is image_tag as some older trainers forwarded it
+var specKeys = []string{"type", "image_tag", "imagetag", "in", "out", "line_lookahead", "eval_metric_names", "groups", "tensorboard_dirs"}
+var groupKeys = []string{"regex", "meta", "scalars", "etimes"}
+
+//docker image tag
+var imageTagRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+//Parse reads the evaluation metrics spec of a deployment request, nil if the request has none. Other keys of the manifest
+//next to evaluation_metrics are ignored, unknown keys within it are errors. The type is normalised to the type of its
+//collector and the spec is validated.
+func Parse(description string) (*Spec, error) {
+	if strings.TrimSpace(description) == "" {
+		return nil, nil
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal([]byte(description), &raw); err != nil {
+		return nil, fmt.Errorf("evaluation metrics can not be parsed: %v", err)
+	}
+	section, present := raw["evaluation_metrics"]
+	if !present || section == nil {
+		return nil, nil
+	}
+	if err := checkKeys(section); err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		EvaluationMetrics struct {
+			Spec           `yaml:",inline"`
+			LegacyImageTag string `yaml:"imagetag,omitempty"`
+		} `yaml:"evaluation_metrics"`
+	}
+	if err := yaml.Unmarshal([]byte(description), &parsed); err != nil {
+		return nil, fmt.Errorf("evaluation metrics can not be parsed: %v", err)
+	}
+	spec := parsed.EvaluationMetrics.Spec
+	if spec.ImageTag == "" {
+		spec.ImageTag = parsed.EvaluationMetrics.LegacyImageTag
+	}
+	if err := spec.normalise(); err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+//checkKeys rejects keys of the evaluation_metrics section and its groups the model does not know
+func checkKeys(section interface{}) error {
+	em, ok := stringMap(section)
+	if !ok {
+		return fmt.Errorf("evaluation_metrics is not a map")
+	}
+	if unknown := unknownKeys(em, specKeys); len(unknown) > 0 {
+		return fmt.Errorf("unknown evaluation_metrics keys: %s", strings.Join(unknown, ", "))
+	}
+	if em["groups"] == nil {
+		return nil
+	}
+	groups, ok := stringMap(em["groups"])
+	if !ok {
+		return fmt.Errorf("evaluation_metrics groups is not a map")
+	}
+	for name, group := range groups {
+		g, ok := stringMap(group)
+		if !ok {
+			return fmt.Errorf("evaluation_metrics group %s is not a map", name)
+		}
+		if unknown := unknownKeys(g, groupKeys); len(unknown) > 0 {
+			return fmt.Errorf("unknown keys in evaluation_metrics group %s: %s", name, strings.Join(unknown, ", "))
+		}
+	}
+	return nil
+}
+
+//yaml.v2 decodes nested maps with interface{} keys
+func stringMap(value interface{}) (map[string]interface{}, bool) {
+	m, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, false
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[fmt.Sprint(k)] = v
+	}
+	return out, true
+}
+
+func unknownKeys(m map[string]interface{}, known []string) []string {
+	var unknown []string
+	for key := range m {
+		isKnown := false
+		for _, k := range known {
+			isKnown = isKnown || k == key
+		}
+		if !isKnown {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+//normalise replaces aliases of collector types with the type of the collector, an empty type is the default collector
+func (s *Spec) normalise() error {
+	if strings.TrimSpace(s.Type) == "" {
+		s.Type = DefaultType
+		return nil
+	}
+	collector, ok := LookupCollector(s.Type)
+	if !ok {
+		return fmt.Errorf("unknown evaluation_metrics type %s", s.Type)
+	}
+	s.Type = collector.Type
+	return nil
+}
+
+//Collector is the collector of the type of the spec
+func (s *Spec) Collector() Collector {
+	collector, _ := LookupCollector(s.Type)
+	return collector
+}
+
+//Dirs are the tensorboard directories of the spec, In followed by TensorboardDirs
+func (s *Spec) Dirs() []string {
+	var dirs []string
+	if s.In != "" {
+		dirs = append(dirs, s.In)
+	}
+	return append(dirs, s.TensorboardDirs...)
+}
+
+//Validate checks the spec against its collector, the type must be normalised
+func (s *Spec) Validate() error {
+	collector, ok := LookupCollector(s.Type)
+	if !ok || collector.Type != s.Type {
+		return fmt.Errorf("unknown evaluation_metrics type %s", s.Type)
+	}
+	if s.ImageTag != "" && !imageTagRegexp.MatchString(s.ImageTag) {
+		return fmt.Errorf("evaluation_metrics image_tag %s is not a valid image tag", s.ImageTag)
+	}
+	if s.LineLookahead < 0 {
+		return fmt.Errorf("evaluation_metrics line_lookahead must not be negative")
+	}
+	for _, name := range s.EvalMetricNames {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("evaluation_metrics eval_metric_names has an empty name")
+		}
+	}
+	if len(s.TensorboardDirs) > 0 && collector.Type != "tensorboard" {
+		return fmt.Errorf("evaluation_metrics tensorboard_dirs is only read by the tensorboard collector")
+	}
+	if collector.NeedsIn && len(s.Dirs()) == 0 {
+		return fmt.Errorf("evaluation_metrics type %s needs the in path", s.Type)
+	}
+	if collector.NeedsGroups && len(s.Groups) == 0 {
+		return fmt.Errorf("evaluation_metrics type %s needs at least one group", s.Type)
+	}
+	for name, group := range s.Groups {
+		if err := group.validate(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//the regex of a group must compile and the scalars must name its groups, values starting with $ are set by the collector
+func (g Group) validate(name string) error {
+	if g.Regex == "" {
+		return fmt.Errorf("evaluation_metrics group %s has no regex", name)
+	}
+	re, err := regexp.Compile(g.Regex)
+	if err != nil {
+		return fmt.Errorf("evaluation_metrics group %s has an invalid regex: %v", name, err)
+	}
+	subexps := make(map[string]bool)
+	for _, subexp := range re.SubexpNames() {
+		subexps[subexp] = true
+	}
+	for metric, subexp := range g.Scalars {
+		if !strings.HasPrefix(subexp, "$") && !subexps[subexp] {
+			return fmt.Errorf("scalar %s of evaluation_metrics group %s reads the regex group %s, which the regex does not have", metric, name, subexp)
+		}
+	}
+	return nil
+}
+
+//Description is the normalised spec in the manifest format
+func (s *Spec) Description() string {
+	out, _ := yaml.Marshal(map[string]*Spec{"evaluation_metrics": s})
+	return string(out)
+}
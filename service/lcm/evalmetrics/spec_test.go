@@ -0,0 +1,107 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evalmetrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const regexSpec = `evaluation_metrics:
+  type: regex
+  in: "$JOB_STATE_DIR/logs/training-log.txt"
+  line_lookahead: 4
+  eval_metric_names:
+    - loss
+  groups:
+    train:
+      regex: 'Step (?P<iteration>\d+), loss = (?P<loss>[\d.]+)'
+      meta:
+        time: "$timestamp"
+      scalars:
+        loss: loss
+      etimes:
+        iteration: iteration
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse(regexSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, "regex_extractor", spec.Type)
+	assert.Equal(t, "$JOB_STATE_DIR/logs/training-log.txt", spec.In)
+	assert.Equal(t, 4, spec.LineLookahead)
+	assert.Equal(t, []string{"loss"}, spec.EvalMetricNames)
+	assert.Equal(t, "loss", spec.Groups["train"].Scalars["loss"])
+	assert.Equal(t, "regex_extractor", spec.Collector().Image)
+
+	//the normalised description parses to the same spec
+	again, err := Parse(spec.Description())
+	assert.NoError(t, err)
+	assert.Equal(t, spec, again)
+
+	spec, err = Parse("evaluation_metrics:\n  type: tensorboard_extractor\n  in: /job/logs/tb\n  imagetag: v1.2\n  tensorboard_dirs: [/job/logs/eval]\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "tensorboard", spec.Type)
+	assert.Equal(t, "tensorboard_extract", spec.Collector().Image)
+	assert.Equal(t, "v1.2", spec.ImageTag)
+	assert.Equal(t, []string{"/job/logs/tb", "/job/logs/eval"}, spec.Dirs())
+
+	spec, err = Parse("evaluation_metrics:\n  type: Optivist\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "emetrics_file_extractor", spec.Type)
+
+	spec, err = Parse("evaluation_metrics:\n  type: none\n")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultType, spec.Type)
+
+	spec, err = Parse("evaluation_metrics:\n  image_tag: latest\n")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultType, spec.Type)
+
+	spec, err = Parse("name: mnist\nlearners: 2\nevaluation_metrics:\n  type: file\nframework:\n  name: tensorflow\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "emetrics_file_extractor", spec.Type)
+
+	for _, description := range []string{"", "  ", "evaluation_metrics:\n", "name: mnist\nlearners: 2\n"} {
+		spec, err = Parse(description)
+		assert.NoError(t, err)
+		assert.Nil(t, spec)
+	}
+}
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	for _, description := range []string{
+		"evaluation_metrics: [a, b]",
+		"evaluation_metrics:\n  type: [regex]\n",
+		"evaluation_metrics:\n  type: mystery\n",
+		"evaluation_metrics:\n  type: file\n  inn: /job/logs\n",
+		"evaluation_metrics:\n  type: regex\n  in: /job/log.txt\n",
+		"evaluation_metrics:\n  type: regex\n  groups:\n    g:\n      regex: '(?P<loss>.*)'\n",
+		"evaluation_metrics:\n  type: regex\n  in: /job/log.txt\n  groups:\n    g:\n      regex: '(?P<loss'\n",
+		"evaluation_metrics:\n  type: regex\n  in: /job/log.txt\n  groups:\n    g:\n      regex: '(?P<loss>.*)'\n      scalars:\n        acc: accuracy\n",
+		"evaluation_metrics:\n  type: regex\n  in: /job/log.txt\n  groups:\n    g:\n      regex: '(?P<loss>.*)'\n      scalar:\n        loss: loss\n",
+		"evaluation_metrics:\n  type: tensorboard\n",
+		"evaluation_metrics:\n  type: file\n  tensorboard_dirs: [/job/tb]\n",
+		"evaluation_metrics:\n  type: file\n  image_tag: 'bad tag'\n",
+		"evaluation_metrics:\n  type: file\n  line_lookahead: -1\n",
+		"evaluation_metrics:\n  type: file\n  line_lookahead: many\n",
+	} {
+		_, err := Parse(description)
+		assert.Error(t, err, description)
+	}
+}
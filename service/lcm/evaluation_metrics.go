@@ -0,0 +1,87 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/evalmetrics"
+	v1core "k8s.io/api/core/v1"
+)
+
+//validateEvaluationMetrics rejects evaluation metrics specs the log collectors can not run with
+func validateEvaluationMetrics(req *service.JobDeploymentRequest) error {
+	_, err := evalmetrics.Parse(req.EvaluationMetricsSpec)
+	return err
+}
+
+//evaluationMetrics is the parsed spec of the request, the default collector if it has none. The spec was validated at
+//deploy, a training that is redeployed from an older request falls back to the default collector
+func evaluationMetrics(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) *evalmetrics.Spec {
+	spec, err := evalmetrics.Parse(req.EvaluationMetricsSpec)
+	if err != nil {
+		logr.WithError(err).Errorf("Invalid evaluation metrics spec, only collecting the logs")
+	}
+	if spec == nil || err != nil {
+		return &evalmetrics.Spec{Type: evalmetrics.DefaultType}
+	}
+	return spec
+}
+
+//logCollectorImageName is the short name and tag of the log collector image of the spec, defaultTag unless the spec has one
+func logCollectorImageName(spec *evalmetrics.Spec, defaultTag string) (string, string) {
+	tag := defaultTag
+	if spec.ImageTag != "" {
+		tag = spec.ImageTag
+	}
+	return spec.Collector().Image, tag
+}
+
+//evaluationMetricsEnvVars configure the log collector, EM_DESCRIPTION is the normalised spec for collectors that read the whole spec
+func evaluationMetricsEnvVars(spec *evalmetrics.Spec) []v1core.EnvVar {
+	vars := []v1core.EnvVar{{Name: "EM_TYPE", Value: spec.Type}}
+	if spec.In != "" {
+		vars = append(vars, v1core.EnvVar{Name: "EM_IN", Value: spec.In})
+	}
+	if spec.Out != "" {
+		vars = append(vars, v1core.EnvVar{Name: "EM_OUT", Value: spec.Out})
+	}
+	if spec.LineLookahead > 0 {
+		vars = append(vars, v1core.EnvVar{Name: "EM_LINE_LOOKAHEAD", Value: strconv.Itoa(spec.LineLookahead)})
+	}
+	if len(spec.EvalMetricNames) > 0 {
+		vars = append(vars, v1core.EnvVar{Name: "EM_EVAL_METRIC_NAMES", Value: strings.Join(spec.EvalMetricNames, ",")})
+	}
+	if len(spec.Groups) > 0 {
+		//the regexes are passed as they are, not with < and > escaped for html
+		groups := &bytes.Buffer{}
+		encoder := json.NewEncoder(groups)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(spec.Groups)
+		vars = append(vars, v1core.EnvVar{Name: "EM_GROUPS", Value: strings.TrimSpace(groups.String())})
+	}
+	if spec.Type == "tensorboard" {
+		vars = append(vars, v1core.EnvVar{Name: "EM_TENSORBOARD_DIRS", Value: strings.Join(spec.Dirs(), ",")})
+	}
+	//collector images that predate the EM_ variables still parse the whole spec from EM_DESCRIPTION, they get the normalised one
+	return append(vars, v1core.EnvVar{Name: "EM_DESCRIPTION", Value: spec.Description()})
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/evalmetrics"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+)
+
+func envVarValue(vars []v1core.EnvVar, name string) (string, bool) {
+	for _, ev := range vars {
+		if ev.Name == name {
+			return ev.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestValidateEvaluationMetrics(t *testing.T) {
+	assert.NoError(t, validateEvaluationMetrics(&service.JobDeploymentRequest{}))
+	assert.NoError(t, validateEvaluationMetrics(&service.JobDeploymentRequest{EvaluationMetricsSpec: "evaluation_metrics:\n  type: file\n"}))
+	assert.Error(t, validateEvaluationMetrics(&service.JobDeploymentRequest{EvaluationMetricsSpec: "evaluation_metrics: tensorboard"}))
+	assert.Error(t, validateEvaluationMetrics(&service.JobDeploymentRequest{EvaluationMetricsSpec: "evaluation_metrics:\n  type: tensorboard\n"}))
+}
+
+func TestLogCollectorFromEvaluationMetrics(t *testing.T) {
+	logr := logger.LocLogger(InitLogger("training-1", "user-1"))
+
+	spec := evaluationMetrics(&service.JobDeploymentRequest{}, logr)
+	image, tag := logCollectorImageName(spec, "master-97")
+	assert.Equal(t, "log_collector", image)
+	assert.Equal(t, "master-97", tag)
+
+	//invalid specs of older requests only collect the logs
+	spec = evaluationMetrics(&service.JobDeploymentRequest{EvaluationMetricsSpec: "evaluation_metrics: [1]"}, logr)
+	assert.Equal(t, evalmetrics.DefaultType, spec.Type)
+
+	spec = evaluationMetrics(&service.JobDeploymentRequest{
+		EvaluationMetricsSpec: "evaluation_metrics:\n  type: tensorboard_extractor\n  image_tag: v2\n  in: /job/logs/tb\n  tensorboard_dirs: [/job/logs/eval]\n",
+	}, logr)
+	image, tag = logCollectorImageName(spec, "master-97")
+	assert.Equal(t, "tensorboard_extract", image)
+	assert.Equal(t, "v2", tag)
+
+	vars := evaluationMetricsEnvVars(spec)
+	value, _ := envVarValue(vars, "EM_TYPE")
+	assert.Equal(t, "tensorboard", value)
+	value, _ = envVarValue(vars, "EM_TENSORBOARD_DIRS")
+	assert.Equal(t, "/job/logs/tb,/job/logs/eval", value)
+	value, _ = envVarValue(vars, "EM_DESCRIPTION")
+	assert.Contains(t, value, "type: tensorboard\n")
+	_, ok := envVarValue(vars, "EM_GROUPS")
+	assert.False(t, ok)
+
+	spec = evaluationMetrics(&service.JobDeploymentRequest{
+		EvaluationMetricsSpec: "evaluation_metrics:\n  type: regex_extractor\n  in: /job/log.txt\n  line_lookahead: 2\n  groups:\n    g:\n      regex: '(?P<loss>[0-9.]+)'\n      scalars:\n        loss: loss\n",
+	}, logr)
+	vars = evaluationMetricsEnvVars(spec)
+	value, _ = envVarValue(vars, "EM_IN")
+	assert.Equal(t, "/job/log.txt", value)
+	value, _ = envVarValue(vars, "EM_LINE_LOOKAHEAD")
+	assert.Equal(t, "2", value)
+	value, _ = envVarValue(vars, "EM_GROUPS")
+	assert.Equal(t, `{"g":{"regex":"(?P<loss>[0-9.]+)","scalars":{"loss":"loss"}}}`, value)
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package frameworks
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/AISphere/ffdl-lcm/service/lcm/configmaps"
+	log "github.com/sirupsen/logrus"
+	v1core "k8s.io/api/core/v1"
+)
+
+//LauncherType is how the learners of a distributed training are started
+type LauncherType string
+
+const (
+	//LauncherNone the framework does not support distributed training
+	LauncherNone LauncherType = "none"
+	//LauncherNative every learner runs the framework, which discovers its peers itself
+	LauncherNative LauncherType = "native"
+	//LauncherMPI the learners only run sshd, a launcher pod starts the training on them through mpirun
+	LauncherMPI LauncherType = "mpi"
+)
+
+//registryConfigPath is the framework registry mounted from the lcm-frameworks configmap, the defaults are used if it is absent
+var registryConfigPath = "/etc/framework-registry/frameworks.yaml"
+
+//EnvVar ... an env var set on the learner, either a plain value or a key of an existing secret
+type EnvVar struct {
+	Name       string `yaml:"name"`
+	Value      string `yaml:"value,omitempty"`
+	SecretName string `yaml:"secret_name,omitempty"`
+	SecretKey  string `yaml:"secret_key,omitempty"`
+}
+
+//Framework ... everything LCM needs to know to deploy learners of one framework version
+type Framework struct {
+	Name string `yaml:"name"`
+	//Version is a shell pattern matched against the version of the request, empty matches all versions
+	Version string `yaml:"version,omitempty"`
+	//Image is a template of the learner image for extended mode, e.g. "tensorflow/tensorflow:{{.Version}}"
+	Image string `yaml:"image,omitempty"`
+	//LaunchCommand is run in the learner before train.sh in extended mode
+	LaunchCommand string `yaml:"launch_command,omitempty"`
+	//SSHKeys mounts a per training ssh key pair into the learners
+	SSHKeys bool `yaml:"ssh_keys,omitempty"`
+	//SHMSize of /dev/shm in the learner in the units of learner.SHMVolume, 0 uses the container default
+	SHMSize  int64        `yaml:"shm_size,omitempty"`
+	EnvVars  []EnvVar     `yaml:"env,omitempty"`
+	Launcher LauncherType `yaml:"launcher,omitempty"`
+	//Adapter sets up the environment of distributed learners, see learner.NewDistributedAdapter
+	Adapter string `yaml:"adapter,omitempty"`
+	//Elastic frameworks (torchrun, horovod elastic) pick up a changed number of learners, see ScaleTrainingJob
+	Elastic bool `yaml:"elastic,omitempty"`
+}
+
+//Registry ... ordered list of frameworks, the first entry matching name and version wins
+type Registry struct {
+	Frameworks []Framework `yaml:"frameworks"`
+}
+
+var defaultRegistry = Registry{
+	Frameworks: []Framework{
+		{Name: "caffe", Image: "bvlc/caffe:{{.Version}}"},
+		{Name: "tensorflow", Version: "*horovod", Image: "tensorflow/tensorflow:{{.Version}}", SSHKeys: true, Launcher: LauncherNative},
+		{Name: "tensorflow", Version: "*ddl", Image: "tensorflow/tensorflow:{{.Version}}", SSHKeys: true, Launcher: LauncherNative},
+		{Name: "tensorflow", Image: "tensorflow/tensorflow:{{.Version}}", Launcher: LauncherNative, Adapter: "tensorflow"},
+		{Name: "caffe2", Image: "caffe2ai/caffe2:{{.Version}}", Launcher: LauncherNative},
+		{Name: "mxnet", SSHKeys: true, Launcher: LauncherNative},
+		{Name: "pytorch", Image: "pytorch/pytorch:{{.Version}}", SSHKeys: true, SHMSize: 4194304, Launcher: LauncherNative, Adapter: "pytorch", Elastic: true},
+		{Name: "h2o3", Image: "opsh2oai/h2o3-ffdl:{{.Version}}"},
+		{Name: "horovod", Image: "uber/horovod:{{.Version}}", SSHKeys: true, Launcher: LauncherMPI, Elastic: true},
+		{Name: "pytorchmpi", Image: "tomcli/pytorch:{{.Version}}", SSHKeys: true, Launcher: LauncherMPI},
+		{Name: "custom", Image: "{{.Version}}"},
+	},
+}
+
+//Load reads the framework registry from its configmap, it is read on every call so configmap updates are picked up.
+//The entries of a framework in the configmap replace all default entries of the same name, the other defaults are kept.
+func Load() *Registry {
+	configured := &Registry{}
+	if !configmaps.Load(registryConfigPath, "framework registry", configured) {
+		return &defaultRegistry
+	}
+	return defaultRegistry.merge(configured)
+}
+
+//merge keeps the entries of r whose framework is not in configured behind the entries of configured
+func (r *Registry) merge(configured *Registry) *Registry {
+	names := make(map[string]bool)
+	for _, f := range configured.Frameworks {
+		names[strings.ToLower(f.Name)] = true
+	}
+	merged := &Registry{Frameworks: append([]Framework{}, configured.Frameworks...)}
+	for _, f := range r.Frameworks {
+		if !names[strings.ToLower(f.Name)] {
+			merged.Frameworks = append(merged.Frameworks, f)
+		}
+	}
+	return merged
+}
+
+//Lookup returns the registry entry of the framework version, false if the framework is unknown
+func Lookup(name, version string) (Framework, bool) {
+	return Load().Lookup(name, version)
+}
+
+//Lookup ...
+func (r *Registry) Lookup(name, version string) (Framework, bool) {
+	for _, f := range r.Frameworks {
+		if !strings.EqualFold(f.Name, name) {
+			continue
+		}
+		if f.Version == "" {
+			return f, true
+		}
+		if matched, err := path.Match(f.Version, version); err == nil && matched {
+			return f, true
+		}
+	}
+	return Framework{Name: name, Launcher: LauncherNone}, false
+}
+
+//ImageName renders the image template for the version, empty if the framework has no image
+func (f Framework) ImageName(version string) string {
+	if f.Image == "" {
+		return ""
+	}
+	tmpl, err := template.New(f.Name).Parse(f.Image)
+	if err != nil {
+		log.WithError(err).Errorf("invalid image template %s for framework %s", f.Image, f.Name)
+		return ""
+	}
+	var image bytes.Buffer
+	if err := tmpl.Execute(&image, struct{ Framework, Version string }{f.Name, version}); err != nil {
+		log.WithError(err).Errorf("failed to render image template %s for framework %s", f.Image, f.Name)
+		return ""
+	}
+	return image.String()
+}
+
+//SupportsDistribution is true if more than one learner can be deployed for the framework
+func (f Framework) SupportsDistribution() bool {
+	return f.Launcher != "" && f.Launcher != LauncherNone
+}
+
+//EnvVarSpecs returns the env vars of the framework as container env vars
+func (f Framework) EnvVarSpecs() []v1core.EnvVar {
+	var envVars []v1core.EnvVar
+	for _, e := range f.EnvVars {
+		if e.SecretName != "" {
+			envVars = append(envVars, v1core.EnvVar{Name: e.Name, ValueFrom: &v1core.EnvVarSource{
+				SecretKeyRef: &v1core.SecretKeySelector{
+					Key:                  e.SecretKey,
+					LocalObjectReference: v1core.LocalObjectReference{Name: e.SecretName},
+				},
+			}})
+			continue
+		}
+		envVars = append(envVars, v1core.EnvVar{Name: e.Name, Value: e.Value})
+	}
+	return envVars
+}
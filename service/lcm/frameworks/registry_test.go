@@ -0,0 +1,92 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package frameworks
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service/lcm/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	fw, known := defaultRegistry.Lookup("tensorflow", "1.5-horovod")
+	assert.True(t, known)
+	assert.True(t, fw.SSHKeys)
+	assert.Equal(t, "tensorflow/tensorflow:1.5-horovod", fw.ImageName("1.5-horovod"))
+
+	fw, known = defaultRegistry.Lookup("TensorFlow", "1.5-py3")
+	assert.True(t, known)
+	assert.False(t, fw.SSHKeys)
+	assert.True(t, fw.SupportsDistribution())
+
+	fw, _ = defaultRegistry.Lookup("pytorch", "0.4")
+	assert.Equal(t, int64(4194304), fw.SHMSize)
+	assert.True(t, fw.Elastic)
+
+	fw, _ = defaultRegistry.Lookup("horovod", "0.13.10")
+	assert.Equal(t, LauncherMPI, fw.Launcher)
+	assert.True(t, fw.SSHKeys)
+	assert.Empty(t, fw.LaunchCommand)
+	assert.True(t, fw.Elastic)
+
+	fw, _ = defaultRegistry.Lookup("custom", "my.registry/team/image:1")
+	assert.Equal(t, "my.registry/team/image:1", fw.ImageName("my.registry/team/image:1"))
+
+	fw, known = defaultRegistry.Lookup("torch", "7")
+	assert.False(t, known)
+	assert.False(t, fw.SupportsDistribution())
+	assert.Equal(t, "", fw.ImageName("7"))
+}
+
+func TestLoadFromConfigMap(t *testing.T) {
+	restore, err := testutil.OverrideConfigMap(&registryConfigPath, `
+frameworks:
+- name: jax
+  version: "0.1*"
+  image: "myregistry/{{.Framework}}:{{.Version}}"
+  shm_size: 1024
+  launcher: native
+  env:
+  - name: XLA_FLAGS
+    value: --xla_gpu_cuda_data_dir=/usr/local/cuda
+- name: pytorch
+  image: "myregistry/pytorch:{{.Version}}"
+  launcher: native
+`)
+	assert.NoError(t, err)
+	defer restore()
+
+	fw, known := Lookup("jax", "0.1.69")
+	assert.True(t, known)
+	assert.Equal(t, "myregistry/jax:0.1.69", fw.ImageName("0.1.69"))
+	assert.Equal(t, int64(1024), fw.SHMSize)
+	assert.Equal(t, "XLA_FLAGS", fw.EnvVarSpecs()[0].Name)
+
+	_, known = Lookup("jax", "0.2")
+	assert.False(t, known)
+
+	fw, known = Lookup("tensorflow", "1.5")
+	assert.True(t, known)
+	assert.Equal(t, "tensorflow/tensorflow:1.5", fw.ImageName("1.5"))
+
+	fw, known = Lookup("PyTorch", "1.0")
+	assert.True(t, known)
+	assert.Equal(t, "myregistry/pytorch:1.0", fw.ImageName("1.0"))
+	assert.False(t, fw.SSHKeys)
+	assert.False(t, fw.Elastic)
+}
@@ -79,7 +79,8 @@ func CreateDeploymentForHelper(name string, podTemplateSpec v1core.PodTemplateSp
 	//but not sure if we can nicely revert back
 	return &v1beta1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:   name,
+			Labels: podTemplateSpec.Labels, //lets LCM find the helper of a training by its training_id
 		},
 		Spec: v1beta1.DeploymentSpec{
 			Template:             podTemplateSpec,
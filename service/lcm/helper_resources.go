@@ -0,0 +1,201 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/configmaps"
+
+	log "github.com/sirupsen/logrus"
+	v1core "k8s.io/api/core/v1"
+	v1resource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+//helperResourcesConfigPath is mounted from the lcm-helper-resources configmap, only the default profiles apply if it is absent
+var helperResourcesConfigPath = "/etc/helper-resources/helper-resources.yaml"
+
+//helperResourceProfile ... the cpu and memory of a helper container, both request and limit, as kubernetes quantities
+type helperResourceProfile struct {
+	CPU    string `yaml:"cpu"`
+	Memory string `yaml:"memory"`
+	//MaxCPU and MaxMemory bound the overrides of a training, trainings can not raise CPU and Memory if they are empty
+	MaxCPU    string `yaml:"max_cpu,omitempty"`
+	MaxMemory string `yaml:"max_memory,omitempty"`
+}
+
+//helperResourceProfiles ... the profile of every helper container, loaded once per deployment
+type helperResourceProfiles map[string]helperResourceProfile
+
+//the input dataset downloads run with the profile of load-data
+func defaultHelperResourceProfiles() helperResourceProfiles {
+	profile := func(milliCPU, memInMB int) helperResourceProfile {
+		return helperResourceProfile{CPU: fmt.Sprintf("%dm", milliCPU), Memory: fmt.Sprintf("%dMi", memInMB)}
+	}
+	return helperResourceProfiles{
+		controllerContainerName:   profile(controllerMilliCPU, controllerMemInMB),
+		loadDataContainerName:     profile(loadTrainingDataMilliCPU, loadTrainingDataMemInMB),
+		loadModelContainerName:    profile(loadModelMilliCPU, loadModelMemInMB),
+		storeResultsContainerName: profile(storeResultsMilliCPU, storeResultsMemInMB),
+		storeLogsContainerName:    profile(storeResultsMilliCPU, storeResultsMemInMB),
+		logCollectorContainerName: profile(logCollectorMilliCPU, logCollectorMemInMB),
+	}
+}
+
+func (p helperResourceProfile) validate() error {
+	quantities := []string{p.CPU, p.Memory}
+	for _, max := range []string{p.MaxCPU, p.MaxMemory} {
+		if max != "" {
+			quantities = append(quantities, max)
+		}
+	}
+	for _, quantity := range quantities {
+		if q, err := v1resource.ParseQuantity(quantity); err != nil || q.Sign() <= 0 {
+			return fmt.Errorf("invalid resource quantity %s", quantity)
+		}
+	}
+	cpu, memory := p.resources(nil)
+	maxCPU, maxMemory := p.maxima()
+	if cpu.Cmp(maxCPU) > 0 || memory.Cmp(maxMemory) > 0 {
+		return fmt.Errorf("the resources %s cpu and %s memory exceed the maxima %s cpu and %s memory", p.CPU, p.Memory, p.MaxCPU, p.MaxMemory)
+	}
+	return nil
+}
+
+//maxima are the most cpu and memory a training may override the profile to
+func (p helperResourceProfile) maxima() (v1resource.Quantity, v1resource.Quantity) {
+	maxCPU, maxMemory := p.MaxCPU, p.MaxMemory
+	if maxCPU == "" {
+		maxCPU = p.CPU
+	}
+	if maxMemory == "" {
+		maxMemory = p.Memory
+	}
+	return v1resource.MustParse(maxCPU), v1resource.MustParse(maxMemory)
+}
+
+//loadHelperResourceProfiles reads the profiles from their configmap on every call, a profile in the configmap replaces the
+//default of its container. Profiles of unknown containers and invalid profiles are ignored.
+func loadHelperResourceProfiles() helperResourceProfiles {
+	profiles := defaultHelperResourceProfiles()
+
+	var configured map[string]helperResourceProfile
+	if !configmaps.Load(helperResourcesConfigPath, "helper resources", &configured) {
+		return profiles
+	}
+	for container, profile := range configured {
+		if _, ok := profiles[container]; !ok {
+			log.Errorf("ignoring the resources of unknown helper container %s", container)
+			continue
+		}
+		if err := profile.validate(); err != nil {
+			log.WithError(err).Errorf("ignoring the resources of helper container %s, using the defaults", container)
+			continue
+		}
+		profiles[container] = profile
+	}
+	return profiles
+}
+
+//helperResourcesOverride is the override of the container in the request, nil if there is none
+func helperResourcesOverride(req *service.JobDeploymentRequest, container string) *service.HelperResources {
+	for _, override := range req.HelperResources {
+		if override.Container == container {
+			return override
+		}
+	}
+	return nil
+}
+
+//resources of the profile with the cpus and memory the override sets
+func (p helperResourceProfile) resources(override *service.HelperResources) (v1resource.Quantity, v1resource.Quantity) {
+	cpu, memory := v1resource.MustParse(p.CPU), v1resource.MustParse(p.Memory)
+	if override.GetCpus() > 0 {
+		cpu = *v1resource.NewMilliQuantity(int64(override.Cpus*1000.0), v1resource.DecimalSI)
+	}
+	if override.GetMemory() > 0 {
+		memInBytes := int64(calcSize(override.Memory, override.MemoryUnit) * 1024 * 1024)
+		memory = *v1resource.NewQuantity(memInBytes, v1resource.DecimalSI)
+	}
+	return cpu, memory
+}
+
+//validateHelperResources rejects overrides of unknown containers, repeated overrides and overrides above the maxima of the profiles
+func validateHelperResources(req *service.JobDeploymentRequest) error {
+	if len(req.HelperResources) == 0 {
+		return nil
+	}
+	profiles := loadHelperResourceProfiles()
+	overridden := make(map[string]bool)
+	for _, override := range req.HelperResources {
+		profile, ok := profiles[override.Container]
+		if !ok {
+			return fmt.Errorf("%s is not a helper container", override.Container)
+		}
+		if overridden[override.Container] {
+			return fmt.Errorf("the resources of helper container %s are set more than once", override.Container)
+		}
+		overridden[override.Container] = true
+		if override.Cpus < 0 || override.Memory < 0 {
+			return fmt.Errorf("the resources of helper container %s are negative", override.Container)
+		}
+		cpu, memory := profile.resources(override)
+		maxCPU, maxMemory := profile.maxima()
+		if cpu.Cmp(maxCPU) > 0 {
+			return fmt.Errorf("helper container %s requests %s cpu, at most %s are allowed", override.Container, cpu.String(), maxCPU.String())
+		}
+		if memory.Cmp(maxMemory) > 0 {
+			return fmt.Errorf("helper container %s requests %s memory, at most %s are allowed", override.Container, memory.String(), maxMemory.String())
+		}
+	}
+	return nil
+}
+
+//containerResources are the requests and limits of a helper container, its profile with the override of the request
+func (profiles helperResourceProfiles) containerResources(req *service.JobDeploymentRequest, container string) v1core.ResourceRequirements {
+	cpu, memory := profiles[container].resources(helperResourcesOverride(req, container))
+	return v1core.ResourceRequirements{
+		Requests: v1core.ResourceList{
+			v1core.ResourceCPU:    cpu,
+			v1core.ResourceMemory: memory,
+		},
+		Limits: v1core.ResourceList{
+			v1core.ResourceCPU:    cpu,
+			v1core.ResourceMemory: memory,
+		},
+	}
+}
+
+//helperResourcesRequired ... the cpus and bytes of memory the helper containers of a training request at most,
+//with one load-data profile for every input dataset that is downloaded
+func helperResourcesRequired(req *service.JobDeploymentRequest) (float64, float64) {
+	containers := []string{controllerContainerName, logCollectorContainerName, loadDataContainerName, loadModelContainerName, storeResultsContainerName, storeLogsContainerName}
+	for _, dataset := range req.InputDatasets {
+		if mountProvider(dataset.Type) == nil {
+			containers = append(containers, loadDataContainerName)
+		}
+	}
+	profiles := loadHelperResourceProfiles()
+	var cpus, memory float64
+	for _, container := range containers {
+		requests := profiles.containerResources(req, container).Requests
+		cpus += float64(requests.Cpu().MilliValue()) / 1000.0
+		memory += float64(requests.Memory().Value())
+	}
+	return cpus, memory
+}
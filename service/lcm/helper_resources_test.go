@@ -0,0 +1,106 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+)
+
+const testHelperResources = `
+load-data:
+  cpu: 500m
+  memory: 2Gi
+  max_cpu: "2"
+  max_memory: 16Gi
+store-results:
+  cpu: "1"
+  memory: 1Gi
+  max_cpu: 500m
+learner:
+  cpu: "4"
+  memory: 8Gi
+`
+
+func TestDefaultHelperResources(t *testing.T) {
+	resources := defaultHelperResourceProfiles().containerResources(&service.JobDeploymentRequest{}, loadDataContainerName)
+	assert.Equal(t, "100m", resources.Requests.Cpu().String())
+	assert.Equal(t, "500Mi", resources.Limits.Memory().String())
+
+	//trainings can only lower the defaults without configured maxima
+	req := &service.JobDeploymentRequest{HelperResources: []*service.HelperResources{{Container: loadDataContainerName, Memory: 1, MemoryUnit: service.ResourceRequirements_GiB}}}
+	assert.Error(t, validateHelperResources(req))
+	req.HelperResources[0] = &service.HelperResources{Container: controllerContainerName, Cpus: 0.05}
+	assert.NoError(t, validateHelperResources(req))
+	resources = defaultHelperResourceProfiles().containerResources(req, controllerContainerName)
+	assert.Equal(t, "50m", resources.Limits.Cpu().String())
+	assert.Equal(t, "500Mi", resources.Requests.Memory().String())
+}
+
+func TestConfiguredHelperResources(t *testing.T) {
+	defer withConfigMap(t, &helperResourcesConfigPath, testHelperResources)()
+
+	profiles := loadHelperResourceProfiles()
+	assert.Equal(t, "2Gi", profiles[loadDataContainerName].Memory)
+	assert.Equal(t, "100m", profiles[storeResultsContainerName].CPU, "profiles above their maxima are ignored")
+	assert.NotContains(t, profiles, learnerContainerName)
+
+	req := &service.JobDeploymentRequest{}
+	resources := profiles.containerResources(req, loadDataContainerName)
+	assert.Equal(t, "500m", resources.Requests.Cpu().String())
+	assert.Equal(t, "2Gi", resources.Limits.Memory().String())
+
+	req.HelperResources = []*service.HelperResources{{Container: loadDataContainerName, Cpus: 2, Memory: 8, MemoryUnit: service.ResourceRequirements_GiB}}
+	assert.NoError(t, validateHelperResources(req))
+	resources = profiles.containerResources(req, loadDataContainerName)
+	assert.Equal(t, "2", resources.Limits.Cpu().String())
+	assert.Equal(t, int64(8*1024*1024*1024), resources.Requests.Memory().Value())
+	assert.Equal(t, resources.Requests, resources.Limits)
+
+	req.HelperResources[0].Memory = 32
+	assert.Error(t, validateHelperResources(req))
+	req.HelperResources[0].Memory = -1
+	assert.Error(t, validateHelperResources(req))
+}
+
+func TestValidateHelperResources(t *testing.T) {
+	assert.NoError(t, validateHelperResources(&service.JobDeploymentRequest{}))
+	assert.Error(t, validateHelperResources(&service.JobDeploymentRequest{HelperResources: []*service.HelperResources{{Container: learnerContainerName}}}))
+	assert.Error(t, validateHelperResources(&service.JobDeploymentRequest{HelperResources: []*service.HelperResources{
+		{Container: storeLogsContainerName}, {Container: storeLogsContainerName},
+	}}))
+}
+
+func TestHelperResourcesOfContainers(t *testing.T) {
+	defer withConfigMap(t, &helperResourcesConfigPath, testHelperResources)()
+	req := inputDatasetsRequest()
+	req.HelperResources = []*service.HelperResources{{Container: loadDataContainerName, Cpus: 1}}
+
+	resources := loadHelperResourceProfiles().containerResources(req, loadDataContainerName)
+	containers := constructLoadInputDatasetContainers(req, v1core.VolumeMount{Name: "jobdata", MountPath: "/job"}, resources)
+	assert.Len(t, containers, 1)
+	assert.Equal(t, "1", containers[0].Resources.Limits.Cpu().String())
+	assert.Equal(t, "2Gi", containers[0].Resources.Requests.Memory().String())
+
+	//five helpers with the 100m and 500Mi defaults, load-data and the one downloaded dataset with 1 cpu and 2Gi
+	cpus, memory := helperResourcesRequired(req)
+	assert.InDelta(t, 2.5, cpus, 0.001)
+	assert.Equal(t, float64(5*500*1024*1024+2*2*1024*1024*1024), memory)
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	"github.com/AISphere/ffdl-trainer/client"
+	"github.com/cenkalti/backoff"
+	"github.com/spf13/viper"
+	v1core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//when enabled the images of a training are resolved to digests once at deploy time, so all its pods, including restarted
+//ones, run the same images even if a tag moves
+const pinImageDigestsKey = "images.pin_digests"
+
+//etcd node of a training with the digests of its images
+const zkImages = "images"
+
+//imageNotFoundError fails the deployment with ErrCodeImagePull instead of leaving the pods in ImagePullBackOff
+type imageNotFoundError struct {
+	image string
+}
+
+func (e *imageNotFoundError) Error() string {
+	return fmt.Sprintf("image %s does not exist", e.image)
+}
+
+//jobImages pins the images of the pod specs of a training to digests, each image is resolved once per training
+type jobImages struct {
+	enabled bool
+	auths   map[string]learner.RegistryAuth
+	resolve func(image string, auth learner.RegistryAuth) (string, error)
+	//image as named in the pod specs to its digest
+	digests map[string]string
+	logr    *logger.LocLoggingEntry
+}
+
+func pinImageDigestsEnabled() bool {
+	return viper.GetBool(pinImageDigestsKey)
+}
+
+//newJobImages collects the registry credentials of a training, from the default pull secret, the pull secrets the
+//registries of the training reference and the credentials in the request, in that order
+func (s *lcmService) newJobImages(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) *jobImages {
+	images := &jobImages{
+		enabled: pinImageDigestsEnabled(),
+		auths:   make(map[string]learner.RegistryAuth),
+		resolve: func(image string, auth learner.RegistryAuth) (string, error) {
+			return learner.ResolveImageDigest(registryClient, image, auth)
+		},
+		digests: make(map[string]string),
+		logr:    logr,
+	}
+	if !images.enabled {
+		return images
+	}
+
+	secretNames := []string{viper.GetString(config.LearnerImagePullSecretKey)}
+	for _, location := range learner.RegistryLocations(req) {
+		if location.PullSecret != "" {
+			secretNames = append(secretNames, location.PullSecret)
+		}
+	}
+	for _, name := range secretNames {
+		if name == "" {
+			continue
+		}
+		for registry, auth := range s.pullSecretAuths(name, logr) {
+			images.auths[registry] = auth
+		}
+	}
+	for _, location := range learner.RegistryLocations(req) {
+		if location.PullSecret == "" {
+			username, password := learner.RegistryCredentials(location)
+			images.auths[learner.RegistryHost(location.Registry)] = learner.RegistryAuth{Username: username, Password: password}
+		}
+	}
+	return images
+}
+
+//pullSecretAuths are the registry credentials of a pull secret in the learner namespace, none if it can not be read
+func (s *lcmService) pullSecretAuths(name string, logr *logger.LocLoggingEntry) map[string]learner.RegistryAuth {
+	var secret *v1core.Secret
+	err := backoff.RetryNotify(func() error {
+		var err error
+		secret, err = s.k8sClient.CoreV1().Secrets(config.GetLearnerNamespace()).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed in getting pull secret %s", name)
+		k8sFailureCounter.With(component, "secret").Add(1)
+	})
+	if err != nil || secret == nil {
+		logr.Warnf("Pull secret %s is not available, images are resolved without its credentials", name)
+		return nil
+	}
+	return learner.SecretRegistryAuths(secret)
+}
+
+//pin returns the image pinned to its digest. Images the registry can not be asked about keep their tag, only images
+//the registry does not know fail the training
+func (j *jobImages) pin(image string) (string, error) {
+	if digest, ok := j.digests[image]; ok {
+		if digest == "" {
+			return image, nil
+		}
+		return learner.PinImage(image, digest), nil
+	}
+	digest, err := j.resolve(image, j.auths[learner.ParseImageReference(image).Registry])
+	if err == learner.ErrImageNotFound {
+		return "", &imageNotFoundError{image}
+	}
+	if err != nil {
+		j.logr.WithError(err).Warnf("Could not resolve the digest of image %s, deploying it by tag", image)
+	}
+	j.digests[image] = digest
+	if digest == "" {
+		return image, nil
+	}
+	return learner.PinImage(image, digest), nil
+}
+
+//pinPodSpec pins the images of all the containers of a pod spec
+func (j *jobImages) pinPodSpec(spec *v1core.PodSpec) error {
+	if j == nil || !j.enabled {
+		return nil
+	}
+	for _, containers := range [][]v1core.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			image, err := j.pin(containers[i].Image)
+			if err != nil {
+				return err
+			}
+			containers[i].Image = image
+		}
+	}
+	return nil
+}
+
+//resolved are the digests of the images that could be resolved
+func (j *jobImages) resolved() map[string]string {
+	resolved := make(map[string]string)
+	for image, digest := range j.digests {
+		if digest != "" {
+			resolved[image] = digest
+		}
+	}
+	return resolved
+}
+
+//records the digests of the images of a training in etcd, where they stay until the training is deleted or redeployed
+func (s *lcmService) recordImageDigests(trainingID string, images *jobImages, logr *logger.LocLoggingEntry) {
+	digests := images.resolved()
+	if len(digests) == 0 {
+		return
+	}
+	record, err := json.Marshal(digests)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to marshal the image digests of training job %s", trainingID)
+		return
+	}
+	if _, err := s.etcdClient.Put(trainingID+"/"+zkImages, string(record), logr); err != nil {
+		logr.WithError(err).Errorf("Failed to record the image digests of training job %s", trainingID)
+	}
+}
+
+//imageDigests are the digests recorded for the images of a training, nil if none were recorded
+func (s *lcmService) imageDigests(trainingID string, logr *logger.LocLoggingEntry) map[string]string {
+	response, err := s.etcdClient.Get(trainingID+"/"+zkImages, logr)
+	if err != nil || len(response) == 0 {
+		return nil
+	}
+	var digests map[string]string
+	if err := json.Unmarshal([]byte(response[0].Value), &digests); err != nil {
+		logr.WithError(err).Errorf("Failed to read the image digests of training job %s", trainingID)
+		return nil
+	}
+	return digests
+}
+
+//deploymentRejectedError fails a deployment that fails the same way when it is redeployed, e.g. an exhausted volume pool
+type deploymentRejectedError struct {
+	errorCode string
+	err       error
+}
+
+func (e *deploymentRejectedError) Error() string {
+	return e.err.Error()
+}
+
+//rejectDeployment fails the deployment with errorCode instead of the redeployable ErrCodeFailedDeploy
+func rejectDeployment(errorCode string, err error) error {
+	return &deploymentRejectedError{errorCode: errorCode, err: err}
+}
+
+//deploymentErrorCode is the error code a training fails with when deploying its components failed, only
+//errors that may go away, like an unreachable etcd or kubernetes, get the redeployable ErrCodeFailedDeploy
+func deploymentErrorCode(err error) string {
+	switch e := err.(type) {
+	case *imageNotFoundError:
+		return client.ErrCodeImagePull
+	case *deploymentRejectedError:
+		return e.errorCode
+	}
+	if k8serrors.IsForbidden(err) {
+		//quotas and admission control, e.g. pod security policies, reject the resources of the training
+		return client.ErrCodeInsufficientResources
+	}
+	if k8serrors.IsInvalid(err) {
+		return client.ErrInvalidResourceSpecs
+	}
+	return client.ErrCodeFailedDeploy
+}
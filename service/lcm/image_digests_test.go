@@ -0,0 +1,108 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	"github.com/AISphere/ffdl-trainer/client"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func testJobImages(digests map[string]string) (*jobImages, map[string]int) {
+	calls := make(map[string]int)
+	return &jobImages{
+		enabled: true,
+		auths:   map[string]learner.RegistryAuth{},
+		resolve: func(image string, auth learner.RegistryAuth) (string, error) {
+			calls[image]++
+			digest, ok := digests[image]
+			if !ok {
+				return "", learner.ErrImageNotFound
+			}
+			if digest == "" {
+				return "", errors.New("registry unreachable")
+			}
+			return digest, nil
+		},
+		digests: make(map[string]string),
+		logr:    logger.LocLogger(InitLogger("training-1", "user-1")),
+	}, calls
+}
+
+func TestPinPodSpec(t *testing.T) {
+	images, calls := testJobImages(map[string]string{
+		"registry.ng.bluemix.net/dlaas/learner:1.0":   "sha256:aaa",
+		"registry.ng.bluemix.net/dlaas/databroker_s3": "sha256:bbb",
+		"quay.io/flaky/sidecar:2":                     "",
+	})
+
+	spec := &v1core.PodSpec{
+		InitContainers: []v1core.Container{{Image: "registry.ng.bluemix.net/dlaas/databroker_s3"}},
+		Containers: []v1core.Container{
+			{Image: "registry.ng.bluemix.net/dlaas/learner:1.0"},
+			{Image: "quay.io/flaky/sidecar:2"},
+		},
+	}
+	assert.NoError(t, images.pinPodSpec(spec))
+	assert.Equal(t, "registry.ng.bluemix.net/dlaas/databroker_s3@sha256:bbb", spec.InitContainers[0].Image)
+	assert.Equal(t, "registry.ng.bluemix.net/dlaas/learner@sha256:aaa", spec.Containers[0].Image)
+	//images that can not be resolved keep their tag
+	assert.Equal(t, "quay.io/flaky/sidecar:2", spec.Containers[1].Image)
+
+	//every image is resolved once per training
+	other := &v1core.PodSpec{Containers: []v1core.Container{{Image: "registry.ng.bluemix.net/dlaas/learner:1.0"}, {Image: "quay.io/flaky/sidecar:2"}}}
+	assert.NoError(t, images.pinPodSpec(other))
+	assert.Equal(t, "registry.ng.bluemix.net/dlaas/learner@sha256:aaa", other.Containers[0].Image)
+	assert.Equal(t, 1, calls["registry.ng.bluemix.net/dlaas/learner:1.0"])
+	assert.Equal(t, 1, calls["quay.io/flaky/sidecar:2"])
+
+	assert.Equal(t, map[string]string{
+		"registry.ng.bluemix.net/dlaas/learner:1.0":   "sha256:aaa",
+		"registry.ng.bluemix.net/dlaas/databroker_s3": "sha256:bbb",
+	}, images.resolved())
+}
+
+func TestPinPodSpecMissingImage(t *testing.T) {
+	images, _ := testJobImages(map[string]string{})
+	err := images.pinPodSpec(&v1core.PodSpec{Containers: []v1core.Container{{Image: "registry.ng.bluemix.net/dlaas/learner:missing"}}})
+	assert.Error(t, err)
+	assert.Equal(t, client.ErrCodeImagePull, deploymentErrorCode(err))
+	assert.Equal(t, client.ErrCodeFailedDeploy, deploymentErrorCode(errors.New("etcd unreachable")))
+
+	images.enabled = false
+	assert.NoError(t, images.pinPodSpec(&v1core.PodSpec{Containers: []v1core.Container{{Image: "registry.ng.bluemix.net/dlaas/learner:missing"}}}))
+
+	var none *jobImages
+	assert.NoError(t, none.pinPodSpec(&v1core.PodSpec{}))
+}
+
+func TestDeploymentErrorCode(t *testing.T) {
+	statefulSets := schema.GroupResource{Group: "apps", Resource: "statefulsets"}
+	assert.Equal(t, client.ErrCodeInsufficientResources, deploymentErrorCode(k8serrors.NewForbidden(statefulSets, "learner-1", errors.New("exceeded quota"))))
+	assert.Equal(t, client.ErrInvalidResourceSpecs, deploymentErrorCode(k8serrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, "learner-1", field.ErrorList{})))
+	assert.Equal(t, client.ErrCodeInsufficientResources, deploymentErrorCode(rejectDeployment(client.ErrCodeInsufficientResources, errors.New("static volume pool exhausted"))))
+	assert.False(t, isRedeployable(deploymentErrorCode(rejectDeployment(client.ErrInvalidResourceSpecs, errors.New("not deployed in split mode")))))
+	assert.True(t, isRedeployable(deploymentErrorCode(k8serrors.NewServerTimeout(statefulSets, "create", 1))))
+}
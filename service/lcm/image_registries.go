@@ -0,0 +1,155 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	"github.com/cenkalti/backoff"
+	"github.com/spf13/viper"
+	v1core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//when enabled the lcm logs into the registries of a training before deploying it, so bad credentials fail the deployment
+//right away instead of leaving the learners in ImagePullBackOff
+const validateRegistryCredentialsKey = "registry.validate_credentials"
+
+var registryClient = &http.Client{Timeout: 10 * time.Second}
+
+//checkImageRegistries rejects registries without exactly one way of authenticating, pull secrets that are missing from the
+//learner namespace, are not owned by the user or are no docker registry secrets and, if enabled, credentials the registries refuse
+func (s *lcmService) checkImageRegistries(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) error {
+	namespace := config.GetLearnerNamespace()
+	for _, location := range learner.RegistryLocations(req) {
+		if err := learner.ValidateRegistryLocation(location); err != nil {
+			return err
+		}
+		if location.PullSecret != "" {
+			if err := s.checkPullSecret(namespace, location.PullSecret, req.UserId, logr); err != nil {
+				return err
+			}
+			continue
+		}
+		if !viper.GetBool(validateRegistryCredentialsKey) {
+			continue
+		}
+		err := learner.CheckRegistryCredentials(registryClient, location)
+		if err == learner.ErrInvalidRegistryCredentials {
+			return fmt.Errorf("registry %s rejected the credentials of the training", location.Registry)
+		}
+		if err != nil {
+			//an unreachable registry is no proof of bad credentials, kubernetes reports pull failures later on
+			logr.WithError(err).Warnf("Could not check the credentials of registry %s", location.Registry)
+		}
+	}
+	return nil
+}
+
+//label of the secrets and claims users create for their trainings, its value is the id of the user
+const ownerLabel = "user_id"
+
+//checkSecretOwner rejects secrets lcm created for a training and secrets that were not labelled for the user of the request,
+//a training must not read the credentials of another user
+func checkSecretOwner(secret *v1core.Secret, userID string) error {
+	for _, label := range []string{"training_id", requestCredentialsLabel} {
+		if _, ok := secret.Labels[label]; ok {
+			return fmt.Errorf("secret %s belongs to a training", secret.Name)
+		}
+	}
+	if owner := secret.Labels[ownerLabel]; owner == "" || owner != userID {
+		return fmt.Errorf("secret %s is not labelled %s=%s", secret.Name, ownerLabel, userID)
+	}
+	return nil
+}
+
+//checkInputDatasetSecrets rejects credentials secrets of input datasets that are missing from the learner namespace, are
+//not owned by the user, lack the access-key and secret-key the mount providers and data brokers read or are not of the type of the s3fs flex driver
+func (s *lcmService) checkInputDatasetSecrets(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) error {
+	namespace := config.GetLearnerNamespace()
+	for _, dataset := range req.InputDatasets {
+		if dataset.CredentialsSecret == "" {
+			continue
+		}
+		secret, err := s.getSecret(namespace, dataset.CredentialsSecret, logr)
+		if err != nil {
+			return err
+		}
+		if secret == nil {
+			return fmt.Errorf("credentials secret %s of input dataset %s does not exist in namespace %s", dataset.CredentialsSecret, dataset.Name, namespace)
+		}
+		if err := checkSecretOwner(secret, req.UserId); err != nil {
+			return fmt.Errorf("credentials secret of input dataset %s can not be used: %s", dataset.Name, err)
+		}
+		for _, key := range []string{learner.CredentialsAccessKey, learner.CredentialsSecretKey} {
+			if _, ok := secret.Data[key]; !ok {
+				return fmt.Errorf("credentials secret %s of input dataset %s has no %s", dataset.CredentialsSecret, dataset.Name, key)
+			}
+		}
+		if dataset.Type == cosMountType && secret.Type != learner.S3FSFlexSecretType {
+			return fmt.Errorf("credentials secret %s of input dataset %s is of type %s, not %s", dataset.CredentialsSecret, dataset.Name, secret.Type, learner.S3FSFlexSecretType)
+		}
+	}
+	return nil
+}
+
+//checkPullSecret rejects pull secrets that are missing, not owned by the user or no docker registry secrets
+func (s *lcmService) checkPullSecret(namespace string, name string, userID string, logr *logger.LocLoggingEntry) error {
+	secret, err := s.getSecret(namespace, name, logr)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return fmt.Errorf("pull secret %s does not exist in namespace %s", name, namespace)
+	}
+	if err := checkSecretOwner(secret, userID); err != nil {
+		return fmt.Errorf("pull secret can not be used: %s", err)
+	}
+	if secret.Type != v1core.SecretTypeDockerConfigJson && secret.Type != v1core.SecretTypeDockercfg {
+		return fmt.Errorf("pull secret %s is of type %s, not a docker registry secret", name, secret.Type)
+	}
+	return nil
+}
+
+//getSecret is the secret of the namespace, nil if it does not exist
+func (s *lcmService) getSecret(namespace string, name string, logr *logger.LocLoggingEntry) (*v1core.Secret, error) {
+	var secret *v1core.Secret
+	var notFound bool
+	err := backoff.RetryNotify(func() error {
+		var err error
+		secret, err = s.k8sClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			notFound = true
+			return nil
+		}
+		return err
+	}, k8sRequestBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed in getting secret %s", name)
+		k8sFailureCounter.With(component, "secret").Add(1)
+	})
+	if err != nil || notFound {
+		return nil, err
+	}
+	return secret, nil
+}
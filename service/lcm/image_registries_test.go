@@ -0,0 +1,46 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckPullSecret(t *testing.T) {
+	logr := logger.LocLogger(InitLogger("training-1", "user-1"))
+	namespace := config.GetLearnerNamespace()
+	pullSecret := func(labels map[string]string) *lcmService {
+		return &lcmService{k8sClient: fake.NewSimpleClientset(&v1core.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: namespace, Labels: labels},
+			Type:       v1core.SecretTypeDockerConfigJson,
+		})}
+	}
+
+	assert.NoError(t, pullSecret(map[string]string{ownerLabel: "user-1"}).checkPullSecret(namespace, "registry", "user-1", logr))
+	assert.Error(t, pullSecret(nil).checkPullSecret(namespace, "registry", "user-1", logr))
+	assert.Error(t, pullSecret(map[string]string{ownerLabel: "user-2"}).checkPullSecret(namespace, "registry", "user-1", logr))
+	//the customimage secrets lcm creates for a training
+	assert.Error(t, pullSecret(map[string]string{ownerLabel: "user-1", "training_id": "training-2"}).checkPullSecret(namespace, "registry", "user-1", logr))
+	assert.Error(t, pullSecret(map[string]string{ownerLabel: "user-1"}).checkPullSecret(namespace, "missing", "user-1", logr))
+}
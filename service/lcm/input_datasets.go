@@ -0,0 +1,232 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/lcmconfig"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/databrokers"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	v1core "k8s.io/api/core/v1"
+)
+
+//where the learners find mounted input datasets, next to the mounted DATA_STORE_ buckets
+const inputDatasetMountDir = "/mnt/data"
+
+//names of input datasets end up in env var, volume and container names
+var inputDatasetNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+//inputDatasetEnvVar is the DATA_DIR_<NAME> variable of the dataset, the name in upper case with dashes replaced
+func inputDatasetEnvVar(dataset *service.InputDataset) string {
+	return "DATA_DIR_" + strings.ToUpper(strings.Replace(dataset.Name, "-", "_", -1))
+}
+
+//inputDatasetPath is where the learners find the dataset, below the data mounts or the job directory
+func inputDatasetPath(dataset *service.InputDataset) string {
+	directory := dataset.Directory
+	if directory == "" {
+		directory = dataset.Name
+	}
+	if mountProvider(dataset.Type) != nil {
+		return path.Join(inputDatasetMountDir, directory)
+	}
+	return path.Join(PodLevelJobDir, directory)
+}
+
+//inputDatasetSecretName is the secret with the credentials of the dataset, the existing secret the dataset names or one of the job
+func inputDatasetSecretName(req *service.JobDeploymentRequest, i int) string {
+	if secret := req.InputDatasets[i].CredentialsSecret; secret != "" {
+		return secret
+	}
+	return fmt.Sprintf("cossecretdataset-%d-%s", i, req.Name)
+}
+
+//the load data container of a downloaded dataset, it is also the name of the exit file the load-data container waits for
+func inputDatasetContainerName(i int) string {
+	return fmt.Sprintf("%s-%d", loadDataContainerName, i)
+}
+
+//downloadsInputDatasets is true if the helper downloads at least one input dataset
+func downloadsInputDatasets(req *service.JobDeploymentRequest) bool {
+	for _, dataset := range req.InputDatasets {
+		if mountProvider(dataset.Type) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+//inputDatasetEnvVars tells the learners where to find the input datasets
+func inputDatasetEnvVars(req *service.JobDeploymentRequest) []v1core.EnvVar {
+	var vars []v1core.EnvVar
+	for _, dataset := range req.InputDatasets {
+		vars = append(vars, v1core.EnvVar{Name: inputDatasetEnvVar(dataset), Value: inputDatasetPath(dataset)})
+	}
+	return vars
+}
+
+//addInputDatasetSecrets adds a secret with the credentials of every input dataset without a credentials secret of its own,
+//in the format of the mount provider for mounted ones
+func addInputDatasetSecrets(req *service.JobDeploymentRequest, secrets *learner.Secrets) {
+	for i, dataset := range req.InputDatasets {
+		if dataset.CredentialsSecret != "" {
+			continue
+		}
+		secret := &learner.COSVolumeSecret{ID: inputDatasetSecretName(req, i), TrainingID: req.TrainingId, Username: dataset.Username, APIKey: dataset.Apikey}
+		if provider := mountProvider(dataset.Type); provider != nil {
+			secret.Provider = provider
+			secrets.DatasetSecrets = append(secrets.DatasetSecrets, secret)
+		} else {
+			secrets.CredentialsSecrets = append(secrets.CredentialsSecrets, secret)
+		}
+	}
+}
+
+//inputDatasetVolumes mounts the input datasets of mount types into the learners, each with its own cache
+func inputDatasetVolumes(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) []*learner.COSVolume {
+	var volumes []*learner.COSVolume
+	for i, dataset := range req.InputDatasets {
+		provider := mountProvider(dataset.Type)
+		if provider == nil {
+			continue
+		}
+		region := dataset.Region
+		if region == "" {
+			region = "us-standard"
+		}
+		cacheSize := int(dataset.CacheSizeGb)
+		if cacheSize <= 0 {
+			cacheSize = mountCacheSize(req, logr)
+		}
+		volumes = append(volumes, &learner.COSVolume{
+			ID:        fmt.Sprintf("cosdataset-%d-%s", i, req.Name),
+			Region:    region,
+			Bucket:    dataset.Bucket,
+			Endpoint:  dataset.Endpoint,
+			SecretRef: inputDatasetSecretName(req, i),
+			MountSpec: learner.VolumeMountSpec{
+				MountPath: inputDatasetPath(dataset),
+				SubPath:   "",
+			},
+			CacheSize: strconv.Itoa(cacheSize),
+			DiskFree:  strconv.Itoa(mountDiskFree(cacheSize)),
+			Provider:  provider,
+		})
+	}
+	return volumes
+}
+
+//constructLoadInputDatasetContainers downloads each input dataset which is not mounted with the data broker of its type.
+//The containers start with load-data and write their own exit file, load-data waits for them.
+func constructLoadInputDatasetContainers(req *service.JobDeploymentRequest, sharedVolumeMount v1core.VolumeMount, resources v1core.ResourceRequirements) []v1core.Container {
+	var containers []v1core.Container
+	for i, dataset := range req.InputDatasets {
+		if mountProvider(dataset.Type) != nil {
+			continue
+		}
+		broker, _ := databrokers.Lookup(dataset.Type)
+		secretName := inputDatasetSecretName(req, i)
+		envVar := func(suffix, value string) v1core.EnvVar {
+			return v1core.EnvVar{Name: broker.EnvVarName(databrokers.LoadData, suffix), Value: value}
+		}
+		secretEnvVar := func(suffix, key string) v1core.EnvVar {
+			return v1core.EnvVar{Name: broker.EnvVarName(databrokers.LoadData, suffix), ValueFrom: &v1core.EnvVarSource{
+				SecretKeyRef: &v1core.SecretKeySelector{LocalObjectReference: v1core.LocalObjectReference{Name: secretName}, Key: key},
+			}}
+		}
+		vars := []v1core.EnvVar{
+			envVar("TYPE", dataset.Type),
+			envVar("AUTHURL", dataset.Endpoint),
+			envVar("REGION", dataset.Region),
+			envVar("OBJECTID", dataset.Bucket),
+			secretEnvVar("USERNAME", learner.CredentialsAccessKey),
+			secretEnvVar("APIKEY", learner.CredentialsSecretKey),
+			{Name: "DATA_DIR", Value: inputDatasetPath(dataset)},
+		}
+
+		name := inputDatasetContainerName(i)
+		command := fmt.Sprintf(`load.sh |tee -a %s/%s.log`, PodLevelLogDir, name)
+		cmd := wrapCommandWithExitFile(command, loadDataContainerName, name, sharedVolumeMount.MountPath, false)
+		containers = append(containers, v1core.Container{
+			Name:            name,
+			Image:           dataBrokerImageName(broker),
+			Command:         []string{"sh", "-c", cmd},
+			Resources:       resources,
+			VolumeMounts:    []v1core.VolumeMount{sharedVolumeMount},
+			Env:             vars,
+			ImagePullPolicy: lcmconfig.GetImagePullPolicy(),
+		})
+	}
+	return containers
+}
+
+//validateInputDatasets rejects input datasets without a usable name, bucket or type and datasets the learners could not tell apart
+func validateInputDatasets(req *service.JobDeploymentRequest) error {
+	envVars := make(map[string]bool)
+	for name := range req.EnvVars {
+		envVars[name] = true
+	}
+	paths := make(map[string]bool)
+	for i, dataset := range req.InputDatasets {
+		if !inputDatasetNameRegexp.MatchString(dataset.Name) {
+			return fmt.Errorf("input dataset %d has an invalid name %q", i, dataset.Name)
+		}
+		if dataset.Bucket == "" {
+			return fmt.Errorf("input dataset %s has no bucket", dataset.Name)
+		}
+		if dataset.CacheSizeGb < 0 {
+			return fmt.Errorf("input dataset %s has a negative cache size", dataset.Name)
+		}
+		if path.IsAbs(dataset.Directory) || strings.HasPrefix(path.Clean(dataset.Directory), "..") {
+			return fmt.Errorf("the directory %s of input dataset %s is not below the data directory", dataset.Directory, dataset.Name)
+		}
+		if dataset.CredentialsSecret != "" && (dataset.Username != "" || dataset.Apikey != "") {
+			return fmt.Errorf("input dataset %s has both a credentials secret and credentials", dataset.Name)
+		}
+		if dataset.CredentialsSecret != "" && dataset.Type == csiMountType {
+			//the claim secret of a csi mount needs the credentials next to the bucket
+			return fmt.Errorf("input dataset %s of type %s needs credentials instead of a credentials secret", dataset.Name, csiMountType)
+		}
+		if mountProvider(dataset.Type) == nil {
+			broker, known := databrokers.Lookup(dataset.Type)
+			if !known {
+				return fmt.Errorf("there is no data broker for the type %s of input dataset %s", dataset.Type, dataset.Name)
+			}
+			if !broker.Supports(databrokers.LoadData) {
+				return fmt.Errorf("the %s data broker of input dataset %s does not support %s", broker.Type, dataset.Name, databrokers.LoadData)
+			}
+		}
+		envVar := inputDatasetEnvVar(dataset)
+		if envVars[envVar] {
+			return fmt.Errorf("the %s env var of input dataset %s is already set", envVar, dataset.Name)
+		}
+		envVars[envVar] = true
+		datasetPath := inputDatasetPath(dataset)
+		if paths[datasetPath] {
+			return fmt.Errorf("the directory %s of input dataset %s is used twice", datasetPath, dataset.Name)
+		}
+		paths[datasetPath] = true
+	}
+	return nil
+}
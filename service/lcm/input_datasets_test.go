@@ -0,0 +1,181 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func inputDatasetsRequest() *service.JobDeploymentRequest {
+	return &service.JobDeploymentRequest{
+		Name:       "training-1",
+		TrainingId: "training-1",
+		UserId:     "user-1",
+		EnvVars:    map[string]string{"DATA_DIR": "data"},
+		InputDatasets: []*service.InputDataset{
+			{Name: "imagenet", Type: "mount_cos", Endpoint: "https://s3.eu.example.com", Region: "eu", Bucket: "imagenet", Username: "user1", Apikey: "key1", CacheSizeGb: 30},
+			{Name: "word-vectors", Type: "s3_datastore", Endpoint: "https://s3.us.example.com", Bucket: "glove", Username: "user2", Apikey: "key2", Directory: "embeddings"},
+		},
+	}
+}
+
+func TestValidateInputDatasets(t *testing.T) {
+	req := inputDatasetsRequest()
+	assert.NoError(t, validateInputDatasets(req))
+
+	req.InputDatasets[1].Name = "imagenet"
+	assert.Error(t, validateInputDatasets(req))
+
+	req.InputDatasets[1].Name = "word_vectors"
+	req.EnvVars["DATA_DIR_WORD_VECTORS"] = "vectors"
+	assert.Error(t, validateInputDatasets(req))
+
+	req = inputDatasetsRequest()
+	req.InputDatasets[1].Directory = "../logs"
+	assert.Error(t, validateInputDatasets(req))
+
+	req = inputDatasetsRequest()
+	req.InputDatasets[1].Type = "ftp"
+	assert.Error(t, validateInputDatasets(req))
+
+	req = inputDatasetsRequest()
+	req.InputDatasets[0].Bucket = ""
+	assert.Error(t, validateInputDatasets(req))
+
+	req = inputDatasetsRequest()
+	req.InputDatasets[1].CredentialsSecret = "glove-credentials"
+	assert.Error(t, validateInputDatasets(req))
+	req.InputDatasets[1].Username, req.InputDatasets[1].Apikey = "", ""
+	assert.NoError(t, validateInputDatasets(req))
+	req.InputDatasets[1].Type = csiMountType
+	assert.Error(t, validateInputDatasets(req))
+}
+
+func TestInputDatasetCredentialsSecret(t *testing.T) {
+	req := inputDatasetsRequest()
+	req.InputDatasets[1].Username, req.InputDatasets[1].Apikey = "", ""
+	req.InputDatasets[1].CredentialsSecret = "glove-credentials"
+
+	secrets := learner.Secrets{}
+	addInputDatasetSecrets(req, &secrets)
+	specs := learner.CreateVolumeSecretsSpec(secrets)
+	assert.Len(t, specs, 1)
+	assert.Equal(t, "cossecretdataset-0-training-1", specs[0].Name)
+
+	containers := constructLoadInputDatasetContainers(req, v1core.VolumeMount{Name: "jobdata", MountPath: "/job"}, v1core.ResourceRequirements{})
+	for _, ev := range containers[0].Env {
+		if ev.Name == "DATA_STORE_USERNAME" {
+			assert.Equal(t, "glove-credentials", ev.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+
+	logr := logger.LocLogger(InitLogger("training-1", "user-1"))
+	s := &lcmService{k8sClient: fake.NewSimpleClientset()}
+	assert.Error(t, s.checkInputDatasetSecrets(req, logr))
+
+	s = &lcmService{k8sClient: fake.NewSimpleClientset(&v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "glove-credentials", Namespace: config.GetLearnerNamespace()},
+		Data:       map[string][]byte{learner.CredentialsAccessKey: []byte("user2")},
+	})}
+	assert.Error(t, s.checkInputDatasetSecrets(req, logr))
+
+	credentials := map[string][]byte{learner.CredentialsAccessKey: []byte("user2"), learner.CredentialsSecretKey: []byte("key2")}
+	s = &lcmService{k8sClient: fake.NewSimpleClientset(&v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "glove-credentials", Namespace: config.GetLearnerNamespace()},
+		Data:       credentials,
+	})}
+	assert.Error(t, s.checkInputDatasetSecrets(req, logr))
+
+	//secrets of other users and of trainings are not handed out
+	s = &lcmService{k8sClient: fake.NewSimpleClientset(&v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "glove-credentials", Namespace: config.GetLearnerNamespace(), Labels: map[string]string{ownerLabel: "user-2"}},
+		Data:       credentials,
+	})}
+	assert.Error(t, s.checkInputDatasetSecrets(req, logr))
+	s = &lcmService{k8sClient: fake.NewSimpleClientset(&v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "glove-credentials", Namespace: config.GetLearnerNamespace(), Labels: map[string]string{ownerLabel: "user-1", "training_id": "training-2"}},
+		Data:       credentials,
+	})}
+	assert.Error(t, s.checkInputDatasetSecrets(req, logr))
+
+	s = &lcmService{k8sClient: fake.NewSimpleClientset(&v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "glove-credentials", Namespace: config.GetLearnerNamespace(), Labels: map[string]string{ownerLabel: "user-1"}},
+		Data:       credentials,
+	})}
+	assert.NoError(t, s.checkInputDatasetSecrets(req, logr))
+}
+
+func TestInputDatasetsOfLearner(t *testing.T) {
+	req := inputDatasetsRequest()
+	assert.True(t, dataStoreUnused(req))
+	assert.True(t, downloadsInputDatasets(req))
+
+	vars := inputDatasetEnvVars(req)
+	assert.Equal(t, "/mnt/data/imagenet", getValue(vars, "DATA_DIR_IMAGENET"))
+	assert.Equal(t, "/job/embeddings", getValue(vars, "DATA_DIR_WORD_VECTORS"))
+
+	volumes := inputDatasetVolumes(req, nil)
+	assert.Len(t, volumes, 1)
+	assert.Equal(t, "imagenet", volumes[0].Bucket)
+	assert.Equal(t, "eu", volumes[0].Region)
+	assert.Equal(t, "cossecretdataset-0-training-1", volumes[0].SecretRef)
+	assert.Equal(t, "30", volumes[0].CacheSize)
+	assert.Equal(t, "10000", volumes[0].DiskFree)
+
+	secrets := learner.Secrets{}
+	addInputDatasetSecrets(req, &secrets)
+	specs := learner.CreateVolumeSecretsSpec(secrets)
+	assert.Len(t, specs, 2)
+	assert.Equal(t, "cossecretdataset-0-training-1", specs[0].Name)
+	assert.Equal(t, "cossecretdataset-1-training-1", specs[1].Name)
+	assert.Equal(t, v1core.SecretTypeOpaque, specs[1].Type)
+	assert.Equal(t, "user2", specs[1].StringData[learner.CredentialsAccessKey])
+}
+
+func TestLoadInputDatasetContainers(t *testing.T) {
+	req := inputDatasetsRequest()
+	mount := v1core.VolumeMount{Name: "jobdata", MountPath: "/job"}
+	containers := constructLoadInputDatasetContainers(req, mount, defaultHelperResourceProfiles().containerResources(req, loadDataContainerName))
+	assert.Len(t, containers, 1)
+	container := containers[0]
+	assert.Equal(t, "load-data-1", container.Name)
+	assert.Contains(t, container.Image, "databroker_s3:")
+	assert.Contains(t, container.Command[2], "/job/load-data.start")
+	assert.Contains(t, container.Command[2], "/job/load-data-1.exit")
+	assert.Equal(t, "glove", getValue(container.Env, "DATA_STORE_BUCKET"))
+	assert.Equal(t, "/job/embeddings", getValue(container.Env, "DATA_DIR"))
+	for _, ev := range container.Env {
+		if ev.Name == "DATA_STORE_USERNAME" {
+			assert.Equal(t, "cossecretdataset-1-training-1", ev.ValueFrom.SecretKeyRef.Name)
+		}
+		assert.NotEqual(t, "key2", ev.Value)
+	}
+
+	loadData := constructLoadTrainingDataContainer(mount, nil, false, []string{container.Name}, v1core.ResourceRequirements{})
+	assert.NotContains(t, loadData.Command[2], "load.sh")
+	assert.Contains(t, loadData.Command[2], "for exit in load-data-1;")
+	assert.Contains(t, loadData.Command[2], "/job/load-data.exit")
+}
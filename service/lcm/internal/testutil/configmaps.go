@@ -0,0 +1,44 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//Package testutil holds helpers for the tests of lcm and its packages
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+//OverrideConfigMap points configPath at a temporary file with the content, as if the configmap was mounted,
+//until restore is called
+func OverrideConfigMap(configPath *string, content string) (restore func(), err error) {
+	dir, err := ioutil.TempDir("", "configmap")
+	if err != nil {
+		return nil, err
+	}
+	previous := *configPath
+	file := path.Join(dir, path.Base(previous))
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	*configPath = file
+	return func() {
+		*configPath = previous
+		os.RemoveAll(dir)
+	}, nil
+}
@@ -18,6 +18,7 @@ package lcm
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/AISphere/ffdl-commons/config"
 	"github.com/AISphere/ffdl-lcm/lcmconfig"
@@ -71,6 +72,14 @@ func populateJobMonitorEnvVariablesAndLabels(req *service.JobDeploymentRequest,
 			Name:  "NUM_LEARNERS",
 			Value: strconv.Itoa(numLearners),
 		},
+		v1core.EnvVar{
+			Name:  "REPLICA_GROUPS",
+			Value: replicaGroupsEnvValue(req),
+		},
+		v1core.EnvVar{
+			Name:  "COMPLETION_ROLES",
+			Value: strings.Join(completionRoles(req), ","),
+		},
 		v1core.EnvVar{
 			Name:  "DLAAS_PUSH_METRICS_ENABLED",
 			Value: strconv.FormatBool(true),
@@ -246,6 +255,7 @@ func defineJobMonitorDeployment(req *service.JobDeploymentRequest, envVars []v1c
 			},
 		},
 	}
+	applyPodSecurity(&deploySpec.Spec.Template.Spec, jobMonitorPodRole, req.Name)
 
 	return deploySpec
 }
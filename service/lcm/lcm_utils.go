@@ -18,14 +18,11 @@ package lcm
 
 import (
 	"fmt"
-	"io/ioutil"
-	"math/rand"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff"
-	"gopkg.in/yaml.v2"
 
 	"github.com/AISphere/ffdl-commons/config"
 
@@ -47,7 +44,7 @@ func createEtcdNodes(lcm *lcmService, jobName string, userID string, trainingID
 		trainingID + "/" + zkNotes:                             "",
 		trainingID + "/" + zkUserID:                            userID,
 		trainingID + "/" + zkFramework:                         framework,
-		trainingID + "/" + zkLearners + "/" + zkTotLearners:    string(numOfLearners),
+		trainingID + "/" + zkLearners + "/" + zkTotLearners:    strconv.Itoa(numOfLearners),
 		trainingID + "/" + zkJobName:                           jobName,
 		trainingID + "/" + zkLearners + "/" + zkLearnerLock:    "",
 		trainingID + "/" + zkLearners + "/" + zkLearnerCounter: "1",
@@ -94,19 +91,13 @@ func constructLearnerVolumeClaimName(learnerID int, jobName string) string {
 	return constructLearnerName(learnerID, jobName)
 }
 
-//helper function to construct a parameter server name from job name
-func constructPSName(jobName string) string {
-	psName := "grpc-ps-" + jobName
-	return psName
-}
-
 // Get the disk size (in bytes) requested for a job.
 func getStorageSize(r *service.ResourceRequirements) int64 {
 	// The default size for all jobs
 	size := config.GetVolumeSize()
 
 	// Use the requested volume size if it's specified
-	if r.Storage > 0 {
+	if r.GetStorage() > 0 {
 		storageSizeInBytes := int64(calcStorage(r) * 1024 * 1024)
 		size = storageSizeInBytes
 	}
@@ -114,64 +105,17 @@ func getStorageSize(r *service.ResourceRequirements) int64 {
 	return size
 }
 
-// Return the name of a volume to use for a job.
-func getStaticVolume(zone string, logr *logger.LocLoggingEntry) string {
-	type Items struct {
-		Name         string `yaml:"name"`
-		Status       string `yaml:"status"`
-		StorageClass string `yaml:"storage_class"`
-		Zone         string `yaml:"zone"`
-	}
-
-	type Volumes struct {
-		Volumes []Items `yaml:"static-volumes-v2"`
-	}
-
-	var staticVolumes Volumes
-	pvcConfigMap := "/etc/static-volumes-v2/PVCs-v2.yaml"
-	bytes, err := ioutil.ReadFile(pvcConfigMap)
-	if err != nil {
-		logr.Warnf("Unable to load %s: %s", pvcConfigMap, err)
-		return ""
-	}
-	err = yaml.Unmarshal(bytes, &staticVolumes)
-	if err != nil {
-		return ""
-	}
-
-	if len(staticVolumes.Volumes) > 0 {
-		// If configmap contains zone, find co-located static volumes
-		if staticVolumes.Volumes[0].Zone != "" && zone != "" {
-			logr.Debugf("searching for static volumes with zone %s", zone)
-			var colocatedVolumeNames []string
-			for _, vol := range staticVolumes.Volumes {
-				if vol.Zone == zone {
-					colocatedVolumeNames = append(colocatedVolumeNames, vol.Name)
-				}
-			}
-			n := rand.Int() % len(colocatedVolumeNames)
-			return colocatedVolumeNames[n]
-		}
-
-		// TODO: Remove this `if/else` logic when all configmaps contain zone
-		// No zone found, assume single region zone; use any static volume
-		logr.Debugf("no zone found in configmap, using any static volume")
-		n := rand.Int() % len(staticVolumes.Volumes)
-		return staticVolumes.Volumes[n].Name
-	}
-	return ""
-}
-
 func handleDeploymentFailure(s *lcmService, dlaasJobName string, tID string,
-	userID string, component string, logr *logger.LocLoggingEntry) {
+	userID string, component string, errorCode string, logr *logger.LocLoggingEntry) {
 
 	logr.Errorf("updating status to FAILED")
-	if errUpd := updateJobStatus(tID, grpc_trainer_v2.Status_FAILED, userID, service.StatusMessages_INTERNAL_ERROR.String(), client.ErrCodeFailedDeploy, logr); errUpd != nil {
+	if errUpd := updateJobStatus(tID, grpc_trainer_v2.Status_FAILED, userID, service.StatusMessages_INTERNAL_ERROR.String(), errorCode, logr); errUpd != nil {
 		logr.WithError(errUpd).Errorf("after failed %s, error while calling Trainer service client update", component)
 	}
 
 	//Cleaning up resources out of an abundance of caution
 	logr.Errorf("training FAILED so going ahead and cleaning up resources")
+	s.forgetDeploymentRequest(tID, logr)
 	if errKill := s.killDeployedJob(dlaasJobName, tID, userID); errKill != nil {
 		logr.WithError(errKill).Errorf("after failed %s, problem calling KillDeployedJob for job ", component)
 	}
@@ -283,6 +227,14 @@ func k8sInteractionBackoff() *backoff.ExponentialBackOff {
 	return back
 }
 
+//k8sRequestBackoff bounds the retries of kubernetes calls made while a deployment request is waiting for its response
+func k8sRequestBackoff() *backoff.ExponentialBackOff {
+	back := backoff.NewExponentialBackOff()
+	back.MaxElapsedTime = 15 * time.Second
+	back.MaxInterval = 5 * time.Second
+	return back
+}
+
 func etdInteractionBackoff(maxElapsedTime, maxInterval time.Duration) *backoff.ExponentialBackOff {
 	back := backoff.NewExponentialBackOff()
 	back.MaxElapsedTime = maxElapsedTime
@@ -307,10 +259,8 @@ func coordinator(logr *logger.LocLoggingEntry) (coord.Coordinator, error) {
 	return instance, err
 }
 
-func isSplitMode(zone string, logr *logger.LocLoggingEntry) bool {
-	staticVolumeName := getStaticVolume(zone, logr)
-	useSplitLearner := len(staticVolumeName) > 0
-	return useSplitLearner
+func isSplitMode(req *service.JobDeploymentRequest) bool {
+	return req.Labels[staticVolumeLabel] != ""
 }
 
 // Armada/IKS clusters will look like GitVersion:"v1.8.15+IKS"
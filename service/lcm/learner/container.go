@@ -32,6 +32,8 @@ type Container struct {
 	VolumeMounts  []v1core.VolumeMount
 	Name, Command string //FIXME eventually get rid of command as well
 	EnvVars       []v1core.EnvVar
+	//Ports in addition to ssh and the tensorflow port, e.g. of a DistributedAdapter
+	Ports []v1core.ContainerPort
 }
 
 //Resources ...
@@ -44,7 +46,9 @@ func CreateContainerSpec(container Container, major string, minor string) v1core
 	image := GetLearnerImageForFramework(container.Image)
 	resources := generateResourceRequirements(container.CPUs, container.Memory, container.GPUs, major, minor)
 	mounts := container.VolumeMounts
-	return generateContainerSpec(container.Name, image, container.Command, container.EnvVars, resources, mounts)
+	spec := generateContainerSpec(container.Name, image, container.Command, container.EnvVars, resources, mounts)
+	spec.Ports = append(spec.Ports, container.Ports...)
+	return spec
 }
 
 func generateContainerSpec(name, image, cmd string, vars []v1core.EnvVar, resourceRequirements v1core.ResourceRequirements, mounts []v1core.VolumeMount) v1core.Container {
@@ -80,7 +84,7 @@ func generateContainerSpec(name, image, cmd string, vars []v1core.EnvVar, resour
 		Env:             vars,
 		Ports: []v1core.ContainerPort{
 			v1core.ContainerPort{ContainerPort: int32(22), Protocol: v1core.ProtocolTCP},
-			v1core.ContainerPort{ContainerPort: int32(2222), Protocol: v1core.ProtocolTCP},
+			v1core.ContainerPort{ContainerPort: TensorFlowWorkerPort, Protocol: v1core.ProtocolTCP},
 		},
 		Resources:       resourceRequirements,
 		VolumeMounts:    mounts,
@@ -0,0 +1,288 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	v1core "k8s.io/api/core/v1"
+)
+
+//ErrImageNotFound ... the registry has no manifest for the tag of an image
+var ErrImageNotFound = errors.New("image not found")
+
+//images without a registry are pulled from docker hub, whose v2 api is not served on docker.io
+const (
+	defaultRegistry    = "docker.io"
+	defaultRegistryAPI = "registry-1.docker.io"
+)
+
+//ErrDigestUnknown ... the registry did not tell the digest of a manifest and it can not be computed from the manifest
+var ErrDigestUnknown = errors.New("manifest digest unknown")
+
+//schema 1 manifests are signed, their digest is the hash of the manifest without its signatures
+const schema1MediaType = "application/vnd.docker.distribution.manifest.v1+prettyjws"
+
+//the manifest types kubernetes can pull, manifest lists and indexes get the digest of the list so every platform still works
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	schema1MediaType,
+}
+
+//RegistryAuth ... credentials of a registry
+type RegistryAuth struct {
+	Username string
+	Password string
+}
+
+//ImageReference ... an image name split into the parts the registry v2 api needs
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+//ParseImageReference ... splits registry/repository:tag@digest, the registry defaults to docker hub and the tag to latest
+func ParseImageReference(image string) ImageReference {
+	var ref ImageReference
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	//the first part is a registry if it looks like a host
+	if i := strings.Index(name, "/"); i >= 0 && (strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		ref.Registry = name[:i]
+		name = name[i+1:]
+	} else {
+		ref.Registry = defaultRegistry
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+	return ref
+}
+
+//PinImage ... the image name with its tag replaced by the digest
+func PinImage(image string, digest string) string {
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return name + "@" + digest
+}
+
+//SecretRegistryAuths ... the credentials per registry of a kubernetes.io/dockerconfigjson or kubernetes.io/dockercfg pull secret
+func SecretRegistryAuths(secret *v1core.Secret) map[string]RegistryAuth {
+	entries := make(map[string]dockerConfigEntry)
+	switch secret.Type {
+	case v1core.SecretTypeDockerConfigJson:
+		var content dockerConfigJSON
+		if err := json.Unmarshal(secret.Data[v1core.DockerConfigJsonKey], &content); err == nil {
+			entries = content.Auths
+		}
+	case v1core.SecretTypeDockercfg:
+		json.Unmarshal(secret.Data[v1core.DockerConfigKey], &entries)
+	}
+
+	auths := make(map[string]RegistryAuth)
+	for server, entry := range entries {
+		auth := RegistryAuth{Username: entry.Username, Password: entry.Password}
+		if auth.Username == "" && entry.Auth != "" {
+			if decoded, err := base64.StdEncoding.DecodeString(entry.Auth); err == nil {
+				if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+					auth = RegistryAuth{Username: parts[0], Password: parts[1]}
+				}
+			}
+		}
+		auths[RegistryHost(server)] = auth
+	}
+	return auths
+}
+
+//RegistryHost ... the registry of a docker config key or image location, which may be urls like https://index.docker.io/v1/
+func RegistryHost(server string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	if host == "index.docker.io" || host == defaultRegistryAPI {
+		return defaultRegistry
+	}
+	return host
+}
+
+//registryToken ... a pull token for the repository from the token service of a bearer challenge, see checkTokenRealm
+func registryToken(client *http.Client, registryBase string, challenge string, auth RegistryAuth, repository string) (string, error) {
+	realm, params, ok := bearerChallenge(challenge)
+	if !ok {
+		return "", ErrInvalidRegistryCredentials
+	}
+	if err := checkTokenRealm(registryBase, realm); err != nil {
+		return "", err
+	}
+	query := url.Values{"scope": {"repository:" + repository + ":pull"}}
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	request, err := http.NewRequest(http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if auth.Username != "" {
+		request.SetBasicAuth(auth.Username, auth.Password)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		return "", ErrInvalidRegistryCredentials
+	}
+	if response.StatusCode >= 300 {
+		return "", fmt.Errorf("token service %s responded with %s", realm, response.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+//ResolveImageDigest ... the digest of the manifest an image tag points to, ErrImageNotFound if the registry does not know the tag.
+//Images that already carry a digest are returned as they are.
+func ResolveImageDigest(client *http.Client, image string, auth RegistryAuth) (string, error) {
+	ref := ParseImageReference(image)
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	host := ref.Registry
+	if host == defaultRegistry {
+		host = defaultRegistryAPI
+	}
+	base := registryURL(host)
+	manifestURL := base + "/v2/" + ref.Repository + "/manifests/" + ref.Tag
+
+	fetch := func(method string, authorization string) (*http.Response, error) {
+		request, err := http.NewRequest(method, manifestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		} else if auth.Username != "" {
+			request.SetBasicAuth(auth.Username, auth.Password)
+		}
+		return client.Do(request)
+	}
+
+	var authorization string
+	response, err := fetch(http.MethodHead, authorization)
+	if err != nil {
+		return "", err
+	}
+	response.Body.Close()
+	if response.StatusCode == http.StatusUnauthorized {
+		token, err := registryToken(client, base, response.Header.Get("WWW-Authenticate"), auth, ref.Repository)
+		if err != nil {
+			return "", err
+		}
+		authorization = "Bearer " + token
+		if response, err = fetch(http.MethodHead, authorization); err != nil {
+			return "", err
+		}
+		response.Body.Close()
+	}
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return "", ErrImageNotFound
+	case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+		return "", ErrInvalidRegistryCredentials
+	case response.StatusCode >= 300:
+		return "", fmt.Errorf("registry %s responded with %s for %s", ref.Registry, response.Status, image)
+	}
+	if digest := response.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	//not every registry sets the digest header on HEAD, the digest is the hash of the manifest
+	if response, err = fetch(http.MethodGet, authorization); err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return "", fmt.Errorf("registry %s responded with %s for %s", ref.Registry, response.Status, image)
+	}
+	if digest := response.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	if !hashableManifest(response.Header.Get("Content-Type")) {
+		return "", ErrDigestUnknown
+	}
+	manifest, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)), nil
+}
+
+//hashableManifest ... manifests of the content type are pulled by the hash of their content, which is not the case for
+//signed schema 1 manifests and manifests of unknown types
+func hashableManifest(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	if mediaType == schema1MediaType {
+		return false
+	}
+	for _, t := range manifestMediaTypes {
+		if t == mediaType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+)
+
+func TestParseImageReference(t *testing.T) {
+	assert.Equal(t, ImageReference{Registry: "docker.io", Repository: "library/busybox", Tag: "latest"}, ParseImageReference("busybox"))
+	assert.Equal(t, ImageReference{Registry: "docker.io", Repository: "tensorflow/tensorflow", Tag: "1.5"}, ParseImageReference("tensorflow/tensorflow:1.5"))
+	assert.Equal(t, ImageReference{Registry: "registry.ng.bluemix.net", Repository: "dlaas_dev/controller", Tag: "master-97"}, ParseImageReference("registry.ng.bluemix.net/dlaas_dev/controller:master-97"))
+	assert.Equal(t, ImageReference{Registry: "localhost:5000", Repository: "learner", Tag: "latest"}, ParseImageReference("localhost:5000/learner"))
+	assert.Equal(t, ImageReference{Registry: "quay.io", Repository: "ns/img", Digest: "sha256:abc"}, ParseImageReference("quay.io/ns/img@sha256:abc"))
+}
+
+func TestPinImage(t *testing.T) {
+	assert.Equal(t, "localhost:5000/learner@sha256:abc", PinImage("localhost:5000/learner:1.0", "sha256:abc"))
+	assert.Equal(t, "busybox@sha256:abc", PinImage("busybox", "sha256:abc"))
+	assert.Equal(t, "quay.io/ns/img@sha256:abc", PinImage("quay.io/ns/img@sha256:old", "sha256:abc"))
+}
+
+func TestSecretRegistryAuths(t *testing.T) {
+	secret := &v1core.Secret{
+		Type: v1core.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{v1core.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"u","password":"p"}}}`)},
+	}
+	assert.Equal(t, map[string]RegistryAuth{"docker.io": {Username: "u", Password: "p"}}, SecretRegistryAuths(secret))
+
+	auth := base64.StdEncoding.EncodeToString([]byte("token:secret"))
+	secret = &v1core.Secret{
+		Type: v1core.SecretTypeDockercfg,
+		Data: map[string][]byte{v1core.DockerConfigKey: []byte(`{"registry.ng.bluemix.net":{"auth":"` + auth + `"}}`)},
+	}
+	assert.Equal(t, map[string]RegistryAuth{"registry.ng.bluemix.net": {Username: "token", Password: "secret"}}, SecretRegistryAuths(secret))
+}
+
+//registry stand-in with a single manifest of the media type, behind a token service if bearer is set. Images name no scheme, so it serves https
+func newTestManifestRegistry(bearer bool, digestHeader bool, mediaType string) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if bearer && r.Header.Get("Authorization") != "Bearer t" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v2/ns/learner/manifests/1.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if digestHeader {
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+		}
+		w.Header().Set("Content-Type", mediaType)
+		w.Write([]byte("manifest"))
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if u, p, _ := r.BasicAuth(); u != "user" || p != "pass" || r.URL.Query().Get("scope") != "repository:ns/learner:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"token":"t"}`))
+	})
+	return server
+}
+
+func TestResolveImageDigest(t *testing.T) {
+	auth := RegistryAuth{Username: "user", Password: "pass"}
+
+	registry := newTestManifestRegistry(true, true, schema1MediaType)
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+
+	digest, err := ResolveImageDigest(registry.Client(), host+"/ns/learner:1.0", auth)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:abc", digest)
+
+	_, err = ResolveImageDigest(registry.Client(), host+"/ns/learner:2.0", auth)
+	assert.Equal(t, ErrImageNotFound, err)
+
+	_, err = ResolveImageDigest(registry.Client(), host+"/ns/learner:1.0", RegistryAuth{Username: "user", Password: "wrong"})
+	assert.Equal(t, ErrInvalidRegistryCredentials, err)
+
+	digest, err = ResolveImageDigest(registry.Client(), host+"/ns/learner@sha256:def", auth)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:def", digest)
+
+	//without the digest header the digest is the hash of the manifest
+	plain := newTestManifestRegistry(false, false, "application/vnd.docker.distribution.manifest.v2+json")
+	defer plain.Close()
+	digest, err = ResolveImageDigest(plain.Client(), strings.TrimPrefix(plain.URL, "https://")+"/ns/learner:1.0", auth)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:05b3abf2579a5eb66403cd78be557fd860633a1fe2103c7642030defe32c657f", digest)
+
+	//the hash of a signed schema 1 manifest is not its digest
+	signed := newTestManifestRegistry(false, false, schema1MediaType)
+	defer signed.Close()
+	_, err = ResolveImageDigest(signed.Client(), strings.TrimPrefix(signed.URL, "https://")+"/ns/learner:1.0", auth)
+	assert.Equal(t, ErrDigestUnknown, err)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1core "k8s.io/api/core/v1"
+)
+
+const (
+	//PyTorchAdapter wires the learners together through the torch.distributed env:// init method
+	PyTorchAdapter = "pytorch"
+
+	pytorchMasterPort int32 = 29500
+)
+
+//Cluster ... the learners of a distributed training, learner N is pod <statefulset>-N of the headless service
+type Cluster struct {
+	StatefulSetName, ServiceName string
+	NumLearners, GPUsPerLearner  int
+	//Strategy and NumPS select the layout of the tensorflow cluster
+	Strategy string
+	NumPS    int
+	//Groups replace the single statefulset for trainings with heterogeneous replica groups, Group is the one being deployed
+	Groups []Group
+	Group  string
+}
+
+//Group ... a replica group of a training, deployed as its own statefulset governed by a headless service of the same name
+type Group struct {
+	Name, StatefulSetName string
+	Replicas              int
+}
+
+//HostName is the stable DNS name of the learner with the statefulset ordinal
+func (c Cluster) HostName(ordinal int) string {
+	if len(c.Groups) > 0 {
+		return c.Groups[0].HostName(ordinal)
+	}
+	return fmt.Sprintf("%s-%d.%s", c.StatefulSetName, ordinal, c.ServiceName)
+}
+
+//HostName is the stable DNS name of the replica of the group with the statefulset ordinal
+func (g Group) HostName(ordinal int) string {
+	return fmt.Sprintf("%s-%d.%s", g.StatefulSetName, ordinal, g.StatefulSetName)
+}
+
+//Hosts of all replicas of the group
+func (g Group) Hosts() []string {
+	var hosts []string
+	for i := 0; i < g.Replicas; i++ {
+		hosts = append(hosts, g.HostName(i))
+	}
+	return hosts
+}
+
+//rankOffset is the number of learners in the groups before the group being deployed
+func (c Cluster) rankOffset() int {
+	offset := 0
+	for _, g := range c.Groups {
+		if g.Name == c.Group {
+			break
+		}
+		offset += g.Replicas
+	}
+	return offset
+}
+
+//HostsEnvVars ... <GROUP>_HOSTS with the comma separated hosts of every replica group
+func (c Cluster) HostsEnvVars() []v1core.EnvVar {
+	var envVars []v1core.EnvVar
+	for _, g := range c.Groups {
+		name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(g.Name)) + "_HOSTS"
+		envVars = append(envVars, v1core.EnvVar{Name: name, Value: strings.Join(g.Hosts(), ",")})
+	}
+	return envVars
+}
+
+//processesPerLearner is one process per GPU, or a single process on CPU only learners
+func (c Cluster) processesPerLearner() int {
+	if c.GPUsPerLearner < 1 {
+		return 1
+	}
+	return c.GPUsPerLearner
+}
+
+//DistributedAdapter ... tells the learners of a framework where to find their peers
+type DistributedAdapter interface {
+	//EnvVars are the same for every learner of the statefulset
+	EnvVars() []v1core.EnvVar
+	//Command is evaluated by bash in each learner before train.sh, for everything that depends on the statefulset ordinal
+	Command() string
+	//Ports the learners listen on for their peers
+	Ports() []v1core.ContainerPort
+}
+
+//NewDistributedAdapter returns the adapter with the name from the framework registry, nil for single learner trainings or unknown adapters
+func NewDistributedAdapter(name string, cluster Cluster) DistributedAdapter {
+	if cluster.NumLearners < 2 {
+		return nil
+	}
+	switch name {
+	case PyTorchAdapter:
+		return pytorchAdapter{cluster}
+	case TensorFlowAdapter:
+		return tensorflowAdapter{cluster}
+	}
+	return nil
+}
+
+//ServicePorts of the adapter for the headless service governing the statefulset
+func ServicePorts(adapter DistributedAdapter) []v1core.ServicePort {
+	if adapter == nil {
+		return nil
+	}
+	var ports []v1core.ServicePort
+	for _, p := range adapter.Ports() {
+		ports = append(ports, v1core.ServicePort{Name: p.Name, Protocol: p.Protocol, Port: p.ContainerPort})
+	}
+	return ports
+}
+
+type pytorchAdapter struct {
+	Cluster
+}
+
+func (a pytorchAdapter) EnvVars() []v1core.EnvVar {
+	nproc := a.processesPerLearner()
+	return []v1core.EnvVar{
+		{Name: "MASTER_ADDR", Value: a.HostName(0)},
+		{Name: "MASTER_PORT", Value: strconv.Itoa(int(pytorchMasterPort))},
+		{Name: "WORLD_SIZE", Value: strconv.Itoa(a.NumLearners * nproc)},
+		{Name: "NPROC_PER_NODE", Value: strconv.Itoa(nproc)},
+		{Name: "LOCAL_RANK", Value: "0"},
+	}
+}
+
+//RANK is the global rank of the first process of the learner, torch.distributed.launch adds LOCAL_RANK for the others
+func (a pytorchAdapter) Command() string {
+	if offset := a.rankOffset(); offset > 0 {
+		return fmt.Sprintf(`export NODE_RANK=$((${DOWNWARD_API_POD_NAME##*-} + %d)) ; export RANK=$((NODE_RANK * NPROC_PER_NODE)) ;`, offset)
+	}
+	return `export NODE_RANK=${DOWNWARD_API_POD_NAME##*-} ; export RANK=$((NODE_RANK * NPROC_PER_NODE)) ;`
+}
+
+func (a pytorchAdapter) Ports() []v1core.ContainerPort {
+	return []v1core.ContainerPort{
+		{Name: "torch-master", ContainerPort: pytorchMasterPort, Protocol: v1core.ProtocolTCP},
+	}
+}
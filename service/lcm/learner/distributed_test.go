@@ -0,0 +1,133 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v1core "k8s.io/api/core/v1"
+)
+
+func envVarValue(envVars []v1core.EnvVar, name string) string {
+	for _, e := range envVars {
+		if e.Name == name {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+func TestPyTorchAdapter(t *testing.T) {
+	cluster := Cluster{StatefulSetName: "learner-job1", ServiceName: "learner-job1", NumLearners: 3, GPUsPerLearner: 2}
+	adapter := NewDistributedAdapter(PyTorchAdapter, cluster)
+	assert.NotNil(t, adapter)
+
+	envVars := adapter.EnvVars()
+	assert.Equal(t, "learner-job1-0.learner-job1", envVarValue(envVars, "MASTER_ADDR"))
+	assert.Equal(t, "29500", envVarValue(envVars, "MASTER_PORT"))
+	assert.Equal(t, "6", envVarValue(envVars, "WORLD_SIZE"))
+	assert.Equal(t, "2", envVarValue(envVars, "NPROC_PER_NODE"))
+	assert.Contains(t, adapter.Command(), "export RANK=")
+
+	service := CreateServiceSpec("learner-job1", "training-1", ServicePorts(adapter)...)
+	assert.Len(t, service.Spec.Ports, 3)
+	assert.Equal(t, int32(29500), service.Spec.Ports[2].Port)
+}
+
+func TestPyTorchAdapterCPUOnly(t *testing.T) {
+	adapter := NewDistributedAdapter(PyTorchAdapter, Cluster{StatefulSetName: "l", ServiceName: "l", NumLearners: 2})
+	assert.Equal(t, "2", envVarValue(adapter.EnvVars(), "WORLD_SIZE"))
+	assert.Equal(t, "1", envVarValue(adapter.EnvVars(), "NPROC_PER_NODE"))
+}
+
+func TestNoAdapterForSingleLearner(t *testing.T) {
+	assert.Nil(t, NewDistributedAdapter(PyTorchAdapter, Cluster{StatefulSetName: "l", ServiceName: "l", NumLearners: 1}))
+	assert.Nil(t, NewDistributedAdapter("", Cluster{StatefulSetName: "l", ServiceName: "l", NumLearners: 4}))
+	assert.Nil(t, ServicePorts(nil))
+}
+
+func TestTensorFlowAdapterMultiWorkerMirrored(t *testing.T) {
+	cluster := Cluster{StatefulSetName: "learner-job1", ServiceName: "learner-job1", NumLearners: 3}
+	adapter := NewDistributedAdapter(TensorFlowAdapter, cluster)
+	assert.NotNil(t, adapter)
+	assert.JSONEq(t, `{"chief": ["learner-job1-0.learner-job1:2222"],
+		"worker": ["learner-job1-1.learner-job1:2222", "learner-job1-2.learner-job1:2222"]}`,
+		envVarValue(adapter.EnvVars(), "TF_CLUSTER_SPEC"))
+	assert.Contains(t, adapter.Command(), "export TF_CONFIG=")
+	assert.Contains(t, adapter.Command(), "-le 2 ]")
+	assert.Empty(t, ServicePorts(adapter))
+}
+
+func TestTensorFlowAdapterParameterServer(t *testing.T) {
+	cluster := Cluster{StatefulSetName: "l", ServiceName: "s", NumLearners: 4, Strategy: TFStrategyParameterServer}
+	adapter := NewDistributedAdapter(TensorFlowAdapter, cluster)
+	assert.JSONEq(t, `{"chief": ["l-0.s:2222"], "worker": ["l-1.s:2222", "l-2.s:2222"], "ps": ["l-3.s:2222"]}`,
+		envVarValue(adapter.EnvVars(), "TF_CLUSTER_SPEC"))
+	assert.Contains(t, adapter.Command(), "-le 2 ]")
+	assert.Contains(t, adapter.Command(), "$((ORDINAL - 3))")
+
+	//there is always a chief, the remaining learners are capped to parameter servers
+	cluster.NumPS = 5
+	adapter = NewDistributedAdapter(TensorFlowAdapter, cluster)
+	assert.JSONEq(t, `{"chief": ["l-0.s:2222"], "ps": ["l-1.s:2222", "l-2.s:2222", "l-3.s:2222"]}`,
+		envVarValue(adapter.EnvVars(), "TF_CLUSTER_SPEC"))
+}
+
+func TestValidateTensorFlowLayout(t *testing.T) {
+	assert.NoError(t, ValidateTensorFlowLayout("", 0, 2))
+	assert.NoError(t, ValidateTensorFlowLayout(TFStrategyMultiWorkerMirrored, 0, 2))
+	assert.NoError(t, ValidateTensorFlowLayout(TFStrategyParameterServer, 1, 3))
+	assert.NoError(t, ValidateTensorFlowLayout(TFStrategyParameterServer, 2, 4))
+	//chief and ps, nobody trains
+	assert.Error(t, ValidateTensorFlowLayout(TFStrategyParameterServer, 1, 2))
+	assert.Error(t, ValidateTensorFlowLayout(TFStrategyParameterServer, 3, 4))
+	assert.Error(t, ValidateTensorFlowLayout(TFStrategyParameterServer, 0, 4))
+	assert.Error(t, ValidateTensorFlowLayout("mirrored", 0, 2))
+}
+
+func TestTensorFlowAdapterReplicaGroups(t *testing.T) {
+	groups := []Group{
+		{Name: "chief", StatefulSetName: "learner-chief-job1", Replicas: 1},
+		{Name: "worker", StatefulSetName: "learner-worker-job1", Replicas: 2},
+		{Name: "evaluator", StatefulSetName: "learner-evaluator-job1", Replicas: 1},
+	}
+	adapter := NewDistributedAdapter(TensorFlowAdapter, Cluster{NumLearners: 4, Groups: groups, Group: "worker"})
+	assert.JSONEq(t, `{"chief": ["learner-chief-job1-0.learner-chief-job1:2222"],
+		"worker": ["learner-worker-job1-0.learner-worker-job1:2222", "learner-worker-job1-1.learner-worker-job1:2222"]}`,
+		envVarValue(adapter.EnvVars(), "TF_CLUSTER_SPEC"))
+	assert.Contains(t, adapter.Command(), `\"type\": \"worker\"`)
+
+	hosts := Cluster{Groups: groups}.HostsEnvVars()
+	assert.Equal(t, "learner-worker-job1-0.learner-worker-job1,learner-worker-job1-1.learner-worker-job1", envVarValue(hosts, "WORKER_HOSTS"))
+	assert.Equal(t, "learner-evaluator-job1-0.learner-evaluator-job1", envVarValue(hosts, "EVALUATOR_HOSTS"))
+}
+
+func TestPyTorchAdapterReplicaGroups(t *testing.T) {
+	groups := []Group{
+		{Name: "master", StatefulSetName: "lm", Replicas: 1},
+		{Name: "worker", StatefulSetName: "lw", Replicas: 3},
+	}
+	adapter := NewDistributedAdapter(PyTorchAdapter, Cluster{NumLearners: 4, Groups: groups, Group: "worker"})
+	assert.Equal(t, "lm-0.lm", envVarValue(adapter.EnvVars(), "MASTER_ADDR"))
+	assert.Equal(t, "4", envVarValue(adapter.EnvVars(), "WORLD_SIZE"))
+	assert.Contains(t, adapter.Command(), "+ 1))")
+
+	adapter = NewDistributedAdapter(PyTorchAdapter, Cluster{NumLearners: 4, Groups: groups, Group: "master"})
+	assert.NotContains(t, adapter.Command(), "+ 1))")
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	//LearnersConfigMountPath is where the learners configmap of an elastic training is mounted into the learners
+	LearnersConfigMountPath = "/etc/learners"
+
+	learnersNumKey         = "num_learners"
+	learnersHostsKey       = "hosts"
+	learnersDiscoveryKey   = "discover_hosts.sh"
+	learnersConfigVolume   = "learners-config"
+	learnersDiscoveryPerms = 0755
+)
+
+//LearnerHosts has a line per learner with the slots of the learner, in the host:slots format of horovod host discovery
+func LearnerHosts(cluster Cluster) string {
+	var hosts bytes.Buffer
+	for i := 0; i < cluster.NumLearners; i++ {
+		fmt.Fprintf(&hosts, "%s:%d\n", cluster.HostName(i), cluster.processesPerLearner())
+	}
+	return hosts.String()
+}
+
+//LearnersConfigData is the content of the learners configmap, it is rewritten whenever the training is scaled
+func LearnersConfigData(cluster Cluster) map[string]string {
+	return map[string]string{
+		learnersNumKey:       strconv.Itoa(cluster.NumLearners),
+		learnersHostsKey:     LearnerHosts(cluster),
+		learnersDiscoveryKey: "#!/bin/sh\ncat " + path.Join(LearnersConfigMountPath, learnersHostsKey) + "\n",
+	}
+}
+
+//CreateLearnersConfigMap ... the current learners of an elastic training, labelled with the training id so it is deleted with the training
+func CreateLearnersConfigMap(name, trainingID string, cluster Cluster) *v1core.ConfigMap {
+	return &v1core.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"training_id": trainingID,
+			},
+		},
+		Data: LearnersConfigData(cluster),
+	}
+}
+
+//LearnersConfigVolume ... volume and mount of the learners configmap, the discovery script has to be executable
+func LearnersConfigVolume(configMapName string) (v1core.Volume, v1core.VolumeMount) {
+	var permissions int32 = learnersDiscoveryPerms
+	volume := v1core.Volume{
+		Name: learnersConfigVolume,
+		VolumeSource: v1core.VolumeSource{
+			ConfigMap: &v1core.ConfigMapVolumeSource{
+				LocalObjectReference: v1core.LocalObjectReference{Name: configMapName},
+				DefaultMode:          &permissions,
+			},
+		},
+	}
+	mount := v1core.VolumeMount{Name: learnersConfigVolume, MountPath: LearnersConfigMountPath, ReadOnly: true}
+	return volume, mount
+}
+
+//ElasticEnvVars ... the bounds of an elastic training, e.g. for torchrun --nnodes=$MIN_LEARNERS:$MAX_LEARNERS or
+//horovodrun --min-np/--max-np with --host-discovery-script $LEARNERS_DISCOVERY_SCRIPT.
+//Scaling does not restart the learners, so NUM_LEARNERS and WORLD_SIZE keep the number of learners the training was
+//deployed with. Learners of an elastic training read the current number from $NUM_LEARNERS_FILE instead
+func ElasticEnvVars(minLearners, maxLearners int) []v1core.EnvVar {
+	return []v1core.EnvVar{
+		{Name: "MIN_LEARNERS", Value: strconv.Itoa(minLearners)},
+		{Name: "MAX_LEARNERS", Value: strconv.Itoa(maxLearners)},
+		{Name: "LEARNERS_CONFIG", Value: LearnersConfigMountPath},
+		{Name: "LEARNERS_DISCOVERY_SCRIPT", Value: path.Join(LearnersConfigMountPath, learnersDiscoveryKey)},
+		{Name: "NUM_LEARNERS_FILE", Value: path.Join(LearnersConfigMountPath, learnersNumKey)},
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLearnersConfigMap(t *testing.T) {
+	cluster := Cluster{StatefulSetName: "learner-job1", ServiceName: "learner-job1", NumLearners: 2, GPUsPerLearner: 2}
+	configMap := CreateLearnersConfigMap("learners-job1", "training-1", cluster)
+	assert.Equal(t, "training-1", configMap.Labels["training_id"])
+	assert.Equal(t, "2", configMap.Data["num_learners"])
+	assert.Equal(t, "learner-job1-0.learner-job1:2\nlearner-job1-1.learner-job1:2\n", configMap.Data["hosts"])
+	assert.Contains(t, configMap.Data["discover_hosts.sh"], "cat /etc/learners/hosts")
+
+	cluster.NumLearners = 3
+	assert.Equal(t, "3", LearnersConfigData(cluster)["num_learners"])
+
+	volume, mount := LearnersConfigVolume("learners-job1")
+	assert.Equal(t, "learners-job1", volume.ConfigMap.Name)
+	assert.Equal(t, int32(0755), *volume.ConfigMap.DefaultMode)
+	assert.Equal(t, LearnersConfigMountPath, mount.MountPath)
+
+	envVars := ElasticEnvVars(1, 4)
+	assert.Equal(t, "1", envVarValue(envVars, "MIN_LEARNERS"))
+	assert.Equal(t, "4", envVarValue(envVars, "MAX_LEARNERS"))
+	assert.Equal(t, "/etc/learners/discover_hosts.sh", envVarValue(envVars, "LEARNERS_DISCOVERY_SCRIPT"))
+	assert.Equal(t, "/etc/learners/num_learners", envVarValue(envVars, "NUM_LEARNERS_FILE"))
+}
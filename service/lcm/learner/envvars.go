@@ -25,6 +25,9 @@ import (
 	v1core "k8s.io/api/core/v1"
 )
 
+//ResumeFromCheckpointEnvVar is true in the learners of a resumed training, they should restart from CHECKPOINT_DIR
+const ResumeFromCheckpointEnvVar = "RESUME_FROM_CHECKPOINT"
+
 //PopulateLearnerEnvVariablesAndLabels ... create envvars for learner from shared env vars. add learner specific envs vars + filter out what is not required
 func PopulateLearnerEnvVariablesAndLabels(existingEnvVars []v1core.EnvVar, trainingID string, numLearners int, statefulsetName string, mountTrainingDataStoreInLearner, mountResultsStoreInLearner bool) []v1core.EnvVar {
 
@@ -40,6 +43,7 @@ func PopulateLearnerEnvVariablesAndLabels(existingEnvVars []v1core.EnvVar, train
 	envVars = append(envVars, v1core.EnvVar{Name: "TRAINING_ID", Value: trainingID})
 	envVars = append(envVars, v1core.EnvVar{Name: "DLAAS_JOB_ID", Value: trainingID})
 	envVars = append(envVars, v1core.EnvVar{Name: "NUM_LEARNERS", Value: strconv.Itoa(numLearners)})
+	envVars = append(envVars, v1core.EnvVar{Name: ResumeFromCheckpointEnvVar, Value: "false"})
 
 	vars := generateLearnerContainerEnvVars(envVars, trainingID, mountTrainingDataStoreInLearner, mountResultsStoreInLearner)
 	return vars
@@ -61,6 +65,7 @@ func generateLearnerContainerEnvVars(envVars []v1core.EnvVar, trainingID string,
 		"TRAINING_COMMAND":           {},
 		"TRAINING_ID":                {},
 		"LEARNER_ID":                 {},
+		"LEARNER_ID_OFFSET":          {},
 		"GPU_COUNT":                  {},
 		"NUM_LEARNERS":               {},
 		"LEARNER_NAME_PREFIX":        {},
@@ -70,6 +75,7 @@ func generateLearnerContainerEnvVars(envVars []v1core.EnvVar, trainingID string,
 		"RESULT_STORE_APIKEY":        {},
 		"RESULT_STORE_AUTHURL":       {},
 		"RESULT_STORE_OBJECTID":      {},
+		ResumeFromCheckpointEnvVar:   {},
 	}
 
 	for _, envVar := range envVars {
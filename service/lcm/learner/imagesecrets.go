@@ -20,15 +20,31 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/AISphere/ffdl-commons/config"
 	"github.com/AISphere/ffdl-lcm/service"
 	"github.com/spf13/viper"
 	v1core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+//ErrInvalidRegistryCredentials ... the registry rejected the credentials of a job
+var ErrInvalidRegistryCredentials = errors.New("invalid registry credentials")
+
+//username of registries which only take an access token, like the IBM Cloud container registry
+const registryTokenUsername = "token"
+
+//token services the lcm sends credentials to although they are not on the host of the registry, like the one of docker hub
+const registryTokenHostsKey = "registry.token_hosts"
+
+var defaultRegistryTokenHosts = []string{"auth.docker.io"}
+
 type dockerConfigEntry struct {
 	Username string `json:"username,omitempty"`
 	Password string `json:"password,omitempty"`
@@ -36,71 +52,210 @@ type dockerConfigEntry struct {
 	Auth     string `json:"auth,omitempty"`
 }
 
-// GenerateImagePullSecret ... creates secret only for custom images; otherwise returns default secret name
-func GenerateImagePullSecret(k8sClient kubernetes.Interface, req *service.JobDeploymentRequest) ([]v1core.LocalObjectReference, error) {
+//format of the .dockerconfigjson key of kubernetes.io/dockerconfigjson secrets
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+//RegistryLocations ... the registry of the custom learner image followed by the other registries of the job
+func RegistryLocations(req *service.JobDeploymentRequest) []*service.ImageLocation {
+	var locations []*service.ImageLocation
+	if req.ImageLocation != nil {
+		locations = append(locations, req.ImageLocation)
+	}
+	return append(locations, req.Registries...)
+}
 
-	imagePullSecret := viper.GetString(config.LearnerImagePullSecretKey)
-
-	// if no custom image, then use our default pull secret
-	if req.ImageLocation == nil {
-		return []v1core.LocalObjectReference{
-			v1core.LocalObjectReference{
-				Name: imagePullSecret,
-			},
-		}, nil
-	}
-
-	// if no token specified, then use ours
-	if req.ImageLocation.AccessToken == "" {
-		return []v1core.LocalObjectReference{}, errors.New("Custom image access token is missing")
-	}
-
-	// build a custom secret
-	imagePullSecretCustom := "customimage-" + req.Name
-	trainingID := req.TrainingId
-	server := req.ImageLocation.Registry
-	token := req.ImageLocation.AccessToken
-	email := req.ImageLocation.Email
-	// format of the .dockercfg entry
-	entry := make(map[string]dockerConfigEntry)
-	entry[server] = dockerConfigEntry{
-		Username: "token",
-		Password: token,
-		Email:    email,
-		Auth:     base64.StdEncoding.EncodeToString([]byte("token:" + token)),
-	}
-	dockerCfgContent, _ := json.Marshal(entry)
-	// create Secret object
-	secret := v1core.Secret{
+//RegistryCredentials ... the username and password of a registry location, the access token is the password of
+//the token user unless a username is given
+func RegistryCredentials(location *service.ImageLocation) (string, string) {
+	if location.AccessToken != "" {
+		if location.Username != "" {
+			return location.Username, location.AccessToken
+		}
+		return registryTokenUsername, location.AccessToken
+	}
+	return location.Username, location.Password
+}
+
+//ValidateRegistryLocation ... every location needs a registry and either a pull secret, an access token or a username and password
+func ValidateRegistryLocation(location *service.ImageLocation) error {
+	if location.Registry == "" {
+		return errors.New("the registry of an image location is missing")
+	}
+	hasToken := location.AccessToken != ""
+	hasPassword := location.Password != ""
+	hasSecret := location.PullSecret != ""
+	switch {
+	case hasSecret && (hasToken || hasPassword):
+		return fmt.Errorf("registry %s has both a pull secret and credentials", location.Registry)
+	case hasToken && hasPassword:
+		return fmt.Errorf("registry %s has both an access token and a password", location.Registry)
+	case hasPassword && location.Username == "":
+		return fmt.Errorf("the username of registry %s is missing", location.Registry)
+	case !hasSecret && !hasToken && !hasPassword:
+		return fmt.Errorf("the access token, password or pull secret of registry %s is missing", location.Registry)
+	}
+	return nil
+}
+
+//CreateImagePullSecretSpec ... the dockerconfigjson secret with the credentials of all registry locations of the job which
+//do not reference a pull secret, nil if there are none
+func CreateImagePullSecretSpec(req *service.JobDeploymentRequest) *v1core.Secret {
+	auths := make(map[string]dockerConfigEntry)
+	for _, location := range RegistryLocations(req) {
+		if location.PullSecret != "" {
+			continue
+		}
+		username, password := RegistryCredentials(location)
+		auths[location.Registry] = dockerConfigEntry{
+			Username: username,
+			Password: password,
+			Email:    location.Email,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+		}
+	}
+	if len(auths) == 0 {
+		return nil
+	}
+	dockerConfigContent, _ := json.Marshal(dockerConfigJSON{Auths: auths})
+	return &v1core.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      imagePullSecretCustom,
+			Name:      "customimage-" + req.Name,
 			Namespace: config.GetLearnerNamespace(),
-			Labels:    map[string]string{"training_id": trainingID}, // this makes sure the secret is deleted with the other learner components
-		},
-		Type: v1core.SecretTypeDockercfg, // kubernetes.io/dockercfg
-		Data: map[string][]byte{},
-	}
-	// add the dockercfg content (as binary)
-	secret.Data[v1core.DockerConfigKey] = dockerCfgContent
-	// create the secret
-	if _, err := k8sClient.CoreV1().Secrets(secret.Namespace).Create(&secret); err != nil {
-		return []v1core.LocalObjectReference{
-			v1core.LocalObjectReference{
-				Name: imagePullSecret,
-			},
-			v1core.LocalObjectReference{
-				Name: imagePullSecretCustom,
-			},
-		}, err
-	}
-
-	// return its name
-	return []v1core.LocalObjectReference{
-		v1core.LocalObjectReference{
-			Name: imagePullSecret,
+			Labels:    map[string]string{"training_id": req.TrainingId}, // this makes sure the secret is deleted with the other learner components
 		},
+		Type: v1core.SecretTypeDockerConfigJson, // kubernetes.io/dockerconfigjson
+		Data: map[string][]byte{v1core.DockerConfigJsonKey: dockerConfigContent},
+	}
+}
+
+// GenerateImagePullSecret ... creates the secret of the custom registries of the job, the pull secrets are the default
+// secret, the created one and the pull secrets the registry locations reference
+func GenerateImagePullSecret(k8sClient kubernetes.Interface, req *service.JobDeploymentRequest) ([]v1core.LocalObjectReference, error) {
+
+	pullSecrets := []v1core.LocalObjectReference{
 		v1core.LocalObjectReference{
-			Name: imagePullSecretCustom,
+			Name: viper.GetString(config.LearnerImagePullSecretKey),
 		},
-	}, nil
+	}
+
+	for _, location := range RegistryLocations(req) {
+		if err := ValidateRegistryLocation(location); err != nil {
+			return pullSecrets, err
+		}
+		if location.PullSecret != "" {
+			pullSecrets = append(pullSecrets, v1core.LocalObjectReference{Name: location.PullSecret})
+		}
+	}
+
+	secret := CreateImagePullSecretSpec(req)
+	if secret == nil {
+		return pullSecrets, nil
+	}
+	pullSecrets = append(pullSecrets, v1core.LocalObjectReference{Name: secret.Name})
+	// replica groups share the secret of the job
+	if _, err := k8sClient.CoreV1().Secrets(secret.Namespace).Create(secret); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return pullSecrets, err
+	}
+	return pullSecrets, nil
+}
+
+//registryURL ... registries are reached with https unless the location has a scheme
+func registryURL(registry string) string {
+	if strings.HasPrefix(registry, "http://") || strings.HasPrefix(registry, "https://") {
+		return strings.TrimSuffix(registry, "/")
+	}
+	return "https://" + strings.TrimSuffix(registry, "/")
+}
+
+//bearer challenge of token authenticated registries, e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func bearerChallenge(header string) (string, map[string]string, bool) {
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", nil, false
+	}
+	params := make(map[string]string)
+	for _, param := range strings.Split(strings.TrimPrefix(header, "Bearer "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	realm, ok := params["realm"]
+	delete(params, "realm")
+	return realm, params, ok
+}
+
+//checkTokenRealm ... the lcm only follows bearer challenges to https token services on the host of the registry or on one of
+//the configured token hosts, registries are named by users and must not make the lcm send requests into the cluster network
+func checkTokenRealm(registryBase string, realm string) error {
+	realmURL, err := url.Parse(realm)
+	if err != nil || realmURL.Scheme != "https" {
+		return fmt.Errorf("token service %s of registry %s is not an https url", realm, registryBase)
+	}
+	if registryURL, err := url.Parse(registryBase); err == nil && registryURL.Host == realmURL.Host {
+		return nil
+	}
+	hosts := defaultRegistryTokenHosts
+	if viper.IsSet(registryTokenHostsKey) {
+		hosts = viper.GetStringSlice(registryTokenHostsKey)
+	}
+	for _, host := range hosts {
+		if realmURL.Host == host {
+			return nil
+		}
+	}
+	return fmt.Errorf("token service %s is not on the host of registry %s", realm, registryBase)
+}
+
+//CheckRegistryCredentials ... logs into the registry of a location with the docker registry v2 api, ErrInvalidRegistryCredentials
+//if the registry or its token service rejects the credentials. Locations with a pull secret and plain http registries are not checked.
+func CheckRegistryCredentials(client *http.Client, location *service.ImageLocation) error {
+	if location.PullSecret != "" {
+		return nil
+	}
+	username, password := RegistryCredentials(location)
+	get := func(target string) (*http.Response, error) {
+		request, err := http.NewRequest(http.MethodGet, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		request.SetBasicAuth(username, password)
+		return client.Do(request)
+	}
+
+	base := registryURL(location.Registry)
+	if !strings.HasPrefix(base, "https://") {
+		return fmt.Errorf("registry %s is not served with https, its credentials are not checked", location.Registry)
+	}
+	response, err := get(base + "/v2/")
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode == http.StatusUnauthorized {
+		realm, params, ok := bearerChallenge(response.Header.Get("WWW-Authenticate"))
+		if !ok {
+			return ErrInvalidRegistryCredentials
+		}
+		if err := checkTokenRealm(base, realm); err != nil {
+			return err
+		}
+		//the token service of the registry checks the credentials
+		query := url.Values{"account": {username}}
+		if service, ok := params["service"]; ok {
+			query.Set("service", service)
+		}
+		if response, err = get(realm + "?" + query.Encode()); err != nil {
+			return err
+		}
+		response.Body.Close()
+	}
+	switch {
+	case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+		return ErrInvalidRegistryCredentials
+	case response.StatusCode >= 300:
+		return fmt.Errorf("registry %s responded with %s", location.Registry, response.Status)
+	}
+	return nil
 }
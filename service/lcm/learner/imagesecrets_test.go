@@ -0,0 +1,158 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+//registry stand-in answering the v2 api with basic auth, or with a bearer challenge whose token service takes the credentials.
+//The challenge points to realm if it is set
+func newTestRegistry(username string, password string, bearer bool, realm string) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	authorized := func(r *http.Request) bool {
+		u, p, ok := r.BasicAuth()
+		return ok && u == username && p == password
+	}
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if bearer {
+			tokenService := server.URL + "/token"
+			if realm != "" {
+				tokenService = realm
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+tokenService+`",service="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) || r.URL.Query().Get("service") != "test-registry" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"token":"t"}`))
+	})
+	return server
+}
+
+func TestValidateRegistryLocation(t *testing.T) {
+	assert.NoError(t, ValidateRegistryLocation(&service.ImageLocation{Registry: "r", AccessToken: "t"}))
+	assert.NoError(t, ValidateRegistryLocation(&service.ImageLocation{Registry: "r", Username: "u", Password: "p"}))
+	assert.NoError(t, ValidateRegistryLocation(&service.ImageLocation{Registry: "r", PullSecret: "s"}))
+
+	assert.Error(t, ValidateRegistryLocation(&service.ImageLocation{AccessToken: "t"}))
+	assert.Error(t, ValidateRegistryLocation(&service.ImageLocation{Registry: "r"}))
+	assert.Error(t, ValidateRegistryLocation(&service.ImageLocation{Registry: "r", Password: "p"}))
+	assert.Error(t, ValidateRegistryLocation(&service.ImageLocation{Registry: "r", AccessToken: "t", Password: "p"}))
+	assert.Error(t, ValidateRegistryLocation(&service.ImageLocation{Registry: "r", PullSecret: "s", AccessToken: "t"}))
+}
+
+func TestCreateImagePullSecretSpec(t *testing.T) {
+	req := &service.JobDeploymentRequest{
+		Name:          "job",
+		TrainingId:    "training-1",
+		ImageLocation: &service.ImageLocation{Registry: "registry.ng.bluemix.net", AccessToken: "tok"},
+		Registries: []*service.ImageLocation{
+			{Registry: "docker.io", Username: "user", Password: "pass"},
+			{Registry: "quay.io", PullSecret: "quay-secret"},
+		},
+	}
+	secret := CreateImagePullSecretSpec(req)
+	assert.Equal(t, "customimage-job", secret.Name)
+	assert.Equal(t, "training-1", secret.Labels["training_id"])
+	assert.Equal(t, v1core.SecretTypeDockerConfigJson, secret.Type)
+
+	var content dockerConfigJSON
+	assert.NoError(t, json.Unmarshal(secret.Data[v1core.DockerConfigJsonKey], &content))
+	assert.Len(t, content.Auths, 2)
+	assert.Equal(t, "token", content.Auths["registry.ng.bluemix.net"].Username)
+	assert.Equal(t, "dG9rZW46dG9r", content.Auths["registry.ng.bluemix.net"].Auth)
+	assert.Equal(t, "dXNlcjpwYXNz", content.Auths["docker.io"].Auth)
+
+	assert.Nil(t, CreateImagePullSecretSpec(&service.JobDeploymentRequest{Name: "job"}))
+}
+
+func TestGenerateImagePullSecret(t *testing.T) {
+	req := &service.JobDeploymentRequest{
+		Name:          "job",
+		TrainingId:    "training-1",
+		ImageLocation: &service.ImageLocation{Registry: "registry.ng.bluemix.net", AccessToken: "tok"},
+		Registries:    []*service.ImageLocation{{Registry: "quay.io", PullSecret: "quay-secret"}},
+	}
+	clientSet := fake.NewSimpleClientset()
+
+	pullSecrets, err := GenerateImagePullSecret(clientSet, req)
+	assert.NoError(t, err)
+	assert.Len(t, pullSecrets, 3)
+	assert.Equal(t, "quay-secret", pullSecrets[1].Name)
+	assert.Equal(t, "customimage-job", pullSecrets[2].Name)
+
+	_, err = clientSet.CoreV1().Secrets(config.GetLearnerNamespace()).Get("customimage-job", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	//the statefulsets of replica groups share the secret
+	_, err = GenerateImagePullSecret(clientSet, req)
+	assert.NoError(t, err)
+
+	pullSecrets, err = GenerateImagePullSecret(clientSet, &service.JobDeploymentRequest{Name: "default"})
+	assert.NoError(t, err)
+	assert.Len(t, pullSecrets, 1)
+
+	_, err = GenerateImagePullSecret(clientSet, &service.JobDeploymentRequest{Name: "job2", ImageLocation: &service.ImageLocation{Registry: "r"}})
+	assert.Error(t, err)
+}
+
+func TestCheckRegistryCredentials(t *testing.T) {
+	for _, bearer := range []bool{false, true} {
+		registry := newTestRegistry("user", "pass", bearer, "")
+
+		assert.NoError(t, CheckRegistryCredentials(registry.Client(), &service.ImageLocation{Registry: registry.URL, Username: "user", Password: "pass"}))
+		assert.Equal(t, ErrInvalidRegistryCredentials, CheckRegistryCredentials(registry.Client(), &service.ImageLocation{Registry: registry.URL, Username: "user", Password: "wrong"}))
+		assert.Equal(t, ErrInvalidRegistryCredentials, CheckRegistryCredentials(registry.Client(), &service.ImageLocation{Registry: registry.URL, AccessToken: "pass"}))
+		assert.NoError(t, CheckRegistryCredentials(registry.Client(), &service.ImageLocation{Registry: registry.URL, PullSecret: "s"}))
+
+		registry.Close()
+	}
+
+	//the lcm does not follow challenges into the cluster network
+	for _, realm := range []string{"http://metadata.internal/token", "https://10.0.0.1/token"} {
+		registry := newTestRegistry("user", "pass", true, realm)
+		err := CheckRegistryCredentials(registry.Client(), &service.ImageLocation{Registry: registry.URL, Username: "user", Password: "pass"})
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrInvalidRegistryCredentials, err)
+		registry.Close()
+	}
+	assert.Error(t, CheckRegistryCredentials(http.DefaultClient, &service.ImageLocation{Registry: "http://10.0.0.1:5000", Username: "user", Password: "pass"}))
+
+	assert.NoError(t, checkTokenRealm("https://registry-1.docker.io", "https://auth.docker.io/token"))
+}
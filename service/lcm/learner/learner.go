@@ -22,8 +22,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-//CreatePodSpec ...
-func CreatePodSpec(containers []v1core.Container, volumes []v1core.Volume, labels map[string]string, nodeSelector map[string]string, imagePullSecret []v1core.LocalObjectReference, nodeAffinity *v1core.NodeAffinity, gpuToleration []v1core.Toleration, termGracePeriodSecs int64) v1core.PodTemplateSpec {
+//CreatePodSpec ... placement adds pod affinity or anti-affinity between the learners of the training
+func CreatePodSpec(containers []v1core.Container, volumes []v1core.Volume, labels map[string]string, nodeSelector map[string]string, imagePullSecret []v1core.LocalObjectReference, nodeAffinity *v1core.NodeAffinity, placement Placement, gpuToleration []v1core.Toleration, termGracePeriodSecs int64) v1core.PodTemplateSpec {
 	labels["service"] = "dlaas-learner" //label that denies ingress/egress
 	automountSeviceToken := false
 	return v1core.PodTemplateSpec{
@@ -42,9 +42,7 @@ func CreatePodSpec(containers []v1core.Container, volumes []v1core.Volume, label
 			Tolerations:                   gpuToleration,
 			NodeSelector:                  nodeSelector,
 			AutomountServiceAccountToken:  &automountSeviceToken,
-			Affinity: &v1core.Affinity{
-				NodeAffinity: nodeAffinity,
-			},
+			Affinity:                      placement.Affinity(nodeAffinity),
 		},
 	}
 }
@@ -69,8 +67,8 @@ func CreateStatefulSetSpecForLearner(name, servicename string, replicas int, pod
 	}
 }
 
-//CreateServiceSpec ... this service will govern the statefulset
-func CreateServiceSpec(name string, trainingID string) *v1core.Service {
+//CreateServiceSpec ... this service will govern the statefulset, extraPorts are added to ssh and the tensorflow port
+func CreateServiceSpec(name string, trainingID string, extraPorts ...v1core.ServicePort) *v1core.Service {
 
 	return &v1core.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -81,7 +79,7 @@ func CreateServiceSpec(name string, trainingID string) *v1core.Service {
 		},
 		Spec: v1core.ServiceSpec{
 			Selector: map[string]string{"training_id": trainingID},
-			Ports: []v1core.ServicePort{
+			Ports: append([]v1core.ServicePort{
 				v1core.ServicePort{
 					Name:     "ssh",
 					Protocol: v1core.ProtocolTCP,
@@ -90,9 +88,9 @@ func CreateServiceSpec(name string, trainingID string) *v1core.Service {
 				v1core.ServicePort{
 					Name:     "tf-distributed",
 					Protocol: v1core.ProtocolTCP,
-					Port:     2222,
+					Port:     TensorFlowWorkerPort,
 				},
-			},
+			}, extraPorts...),
 			ClusterIP: "None",
 		},
 	}
@@ -0,0 +1,265 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"fmt"
+
+	"github.com/AISphere/ffdl-commons/config"
+	v1core "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	//CredentialsAccessKey ... key of the username in mount and credentials secrets, the same for all providers
+	CredentialsAccessKey = "access-key"
+	//CredentialsSecretKey ... key of the apikey in mount and credentials secrets
+	CredentialsSecretKey = "secret-key"
+
+	//keys of the bucket in the secret of a csi claim, next to the credentials
+	csiSecretBucketKey   = "bucket"
+	csiSecretEndpointKey = "endpoint"
+	csiSecretRegionKey   = "region"
+
+	//where the fuse sidecar mounts the bucket, the learner sees it through mount propagation of the shared empty dir
+	fuseMountPath = "/mnt/fuse"
+	//how long the learner waits for the fuse sidecars to mount their buckets
+	fuseMountWaitSecs = 120
+)
+
+//MountProvider ... mounts an object store bucket into the learners, chosen by the DATA_STORE_TYPE or RESULT_STORE_TYPE of the job
+type MountProvider interface {
+	//Volume of the bucket, results volumes are tuned for writing checkpoints and logs
+	Volume(mount *COSVolume, results bool) v1core.Volume
+	VolumeMount(mount *COSVolume) v1core.VolumeMount
+	//Secret with the credentials of the store, referenced by the volume or sidecar through COSVolume.SecretRef
+	Secret(secret *COSVolumeSecret) *v1core.Secret
+	//Sidecar ... container the mount needs in every learner pod, nil if there is none
+	Sidecar(name string, mount *COSVolume) *v1core.Container
+	//Claim ... claim the volume is bound through, nil if there is none
+	Claim(mount *COSVolume, trainingID string) *v1core.PersistentVolumeClaim
+}
+
+//S3FSFlexProvider ... the ibm/ibmc-s3fs flex volume driver
+type S3FSFlexProvider struct{}
+
+//Volume ...
+func (S3FSFlexProvider) Volume(mount *COSVolume, results bool) v1core.Volume {
+	if results {
+		return generateCOSResultsVolume(mount.ID, mount.Region, mount.Bucket, mount.Endpoint, mount.SecretRef, mount.CacheSize, mount.DiskFree)
+	}
+	return generateCOSTrainingDataVolume(mount.ID, mount.Region, mount.Bucket, mount.Endpoint, mount.SecretRef, mount.CacheSize, mount.DiskFree)
+}
+
+//VolumeMount ...
+func (S3FSFlexProvider) VolumeMount(mount *COSVolume) v1core.VolumeMount {
+	return generateVolumeMount(mount.ID, mount.MountSpec.MountPath)
+}
+
+//Secret ...
+func (S3FSFlexProvider) Secret(secret *COSVolumeSecret) *v1core.Secret {
+	return generateCOSVolumeSecret(secret.ID, secret.TrainingID, secret.Username, secret.APIKey)
+}
+
+//Sidecar ...
+func (S3FSFlexProvider) Sidecar(name string, mount *COSVolume) *v1core.Container {
+	return nil
+}
+
+//Claim ...
+func (S3FSFlexProvider) Claim(mount *COSVolume, trainingID string) *v1core.PersistentVolumeClaim {
+	return nil
+}
+
+//CSIProvider ... a claim of a storage class whose csi driver mounts the bucket in the secret of the same name as the claim.
+//The storage class hands that secret to the driver through its parameters, e.g.
+//csi.storage.k8s.io/provisioner-secret-name and csi.storage.k8s.io/node-publish-secret-name set to ${pvc.name} and the
+//matching -namespace parameters set to ${pvc.namespace}.
+type CSIProvider struct {
+	StorageClass string
+}
+
+//Volume ...
+func (CSIProvider) Volume(mount *COSVolume, results bool) v1core.Volume {
+	return v1core.Volume{
+		Name: mount.ID,
+		VolumeSource: v1core.VolumeSource{
+			PersistentVolumeClaim: &v1core.PersistentVolumeClaimVolumeSource{ClaimName: mount.ID},
+		},
+	}
+}
+
+//VolumeMount ...
+func (CSIProvider) VolumeMount(mount *COSVolume) v1core.VolumeMount {
+	return generateVolumeMount(mount.ID, mount.MountSpec.MountPath)
+}
+
+//Secret ...
+func (CSIProvider) Secret(secret *COSVolumeSecret) *v1core.Secret {
+	return generateMountSecret(secret)
+}
+
+//Sidecar ...
+func (CSIProvider) Sidecar(name string, mount *COSVolume) *v1core.Container {
+	return nil
+}
+
+//Claim ... the size is nominal, buckets do not have one
+func (p CSIProvider) Claim(mount *COSVolume, trainingID string) *v1core.PersistentVolumeClaim {
+	class := p.StorageClass
+	return &v1core.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mount.ID,
+			Namespace: config.GetLearnerNamespace(),
+			Labels:    map[string]string{"training_id": trainingID},
+		},
+		Spec: v1core.PersistentVolumeClaimSpec{
+			AccessModes:      []v1core.PersistentVolumeAccessMode{v1core.ReadWriteMany},
+			StorageClassName: &class,
+			Resources: v1core.ResourceRequirements{
+				Requests: v1core.ResourceList{v1core.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+}
+
+//ClaimSecret ... the secret the storage class passes to the driver, the bucket of the claim with the credentials of the store
+func (CSIProvider) ClaimSecret(mount *COSVolume, credentials *COSVolumeSecret) *v1core.Secret {
+	return &v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mount.ID,
+			Namespace: config.GetLearnerNamespace(),
+			Labels:    map[string]string{"training_id": credentials.TrainingID},
+		},
+		Type: v1core.SecretTypeOpaque,
+		StringData: map[string]string{
+			CredentialsAccessKey: credentials.Username,
+			CredentialsSecretKey: credentials.APIKey,
+			csiSecretBucketKey:   mount.Bucket,
+			csiSecretEndpointKey: mount.Endpoint,
+			csiSecretRegionKey:   mount.Region,
+		},
+	}
+}
+
+//FUSEProvider ... a privileged sidecar running a fuse client like goofys or rclone, the command mounts $BUCKET at $MOUNT_PATH
+//in the foreground with the credentials in $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY
+type FUSEProvider struct {
+	Image, Command string
+}
+
+//Volume ... the empty dir the sidecar mounts the bucket on
+func (FUSEProvider) Volume(mount *COSVolume, results bool) v1core.Volume {
+	return v1core.Volume{
+		Name:         mount.ID,
+		VolumeSource: v1core.VolumeSource{EmptyDir: &v1core.EmptyDirVolumeSource{}},
+	}
+}
+
+//VolumeMount ...
+func (FUSEProvider) VolumeMount(mount *COSVolume) v1core.VolumeMount {
+	propagation := v1core.MountPropagationHostToContainer
+	return v1core.VolumeMount{Name: mount.ID, MountPath: mount.MountSpec.MountPath, MountPropagation: &propagation}
+}
+
+//Secret ...
+func (FUSEProvider) Secret(secret *COSVolumeSecret) *v1core.Secret {
+	return generateMountSecret(secret)
+}
+
+//Sidecar ...
+func (p FUSEProvider) Sidecar(name string, mount *COSVolume) *v1core.Container {
+	privileged := true
+	propagation := v1core.MountPropagationBidirectional
+	secretEnvVar := func(name, key string) v1core.EnvVar {
+		return v1core.EnvVar{
+			Name: name,
+			ValueFrom: &v1core.EnvVarSource{
+				SecretKeyRef: &v1core.SecretKeySelector{
+					LocalObjectReference: v1core.LocalObjectReference{Name: mount.SecretRef},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	return &v1core.Container{
+		Name:    name,
+		Image:   p.Image,
+		Command: []string{"sh", "-c", p.Command},
+		Env: []v1core.EnvVar{
+			{Name: "BUCKET", Value: mount.Bucket},
+			{Name: "ENDPOINT", Value: mount.Endpoint},
+			{Name: "REGION", Value: mount.Region},
+			{Name: "MOUNT_PATH", Value: fuseMountPath},
+			secretEnvVar("AWS_ACCESS_KEY_ID", CredentialsAccessKey),
+			secretEnvVar("AWS_SECRET_ACCESS_KEY", CredentialsSecretKey),
+		},
+		SecurityContext: &v1core.SecurityContext{Privileged: &privileged},
+		VolumeMounts:    []v1core.VolumeMount{{Name: mount.ID, MountPath: fuseMountPath, MountPropagation: &propagation}},
+		Lifecycle: &v1core.Lifecycle{
+			PreStop: &v1core.Handler{Exec: &v1core.ExecAction{Command: []string{"fusermount", "-u", fuseMountPath}}},
+		},
+	}
+}
+
+//Claim ...
+func (FUSEProvider) Claim(mount *COSVolume, trainingID string) *v1core.PersistentVolumeClaim {
+	return nil
+}
+
+//MountWaitCommand ... waits until the sidecar mounted the bucket over the empty dir, the learner goes on without it after
+//fuseMountWaitSecs and fails on the missing data itself
+func (FUSEProvider) MountWaitCommand(mount *COSVolume) string {
+	path := mount.MountSpec.MountPath
+	return fmt.Sprintf(`for i in $(seq %d); do grep -qs " %s fuse" /proc/mounts && break ; sleep 1 ; done ;
+			grep -qs " %s fuse" /proc/mounts || echo "bucket %s is not mounted at %s" ;`, fuseMountWaitSecs, path, path, mount.Bucket, path)
+}
+
+//claimSecretProvider ... providers whose claims need a secret of their own, see CSIProvider
+type claimSecretProvider interface {
+	ClaimSecret(mount *COSVolume, credentials *COSVolumeSecret) *v1core.Secret
+}
+
+//mountWaiter ... providers whose bucket is mounted by a sidecar after the learner started, see FUSEProvider
+type mountWaiter interface {
+	MountWaitCommand(mount *COSVolume) string
+}
+
+//the credentials of a bucket for providers that do not need a driver specific secret type
+func generateMountSecret(secret *COSVolumeSecret) *v1core.Secret {
+	return &v1core.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.ID,
+			Namespace: config.GetLearnerNamespace(),
+			Labels:    map[string]string{"training_id": secret.TrainingID},
+		},
+		Type: v1core.SecretTypeOpaque,
+		StringData: map[string]string{
+			CredentialsAccessKey: secret.Username,
+			CredentialsSecretKey: secret.APIKey,
+		},
+	}
+}
+
+//mountProviderOf is the provider of a mount, mounts without one use the s3fs flex driver
+func mountProviderOf(provider MountProvider) MountProvider {
+	if provider == nil {
+		return S3FSFlexProvider{}
+	}
+	return provider
+}
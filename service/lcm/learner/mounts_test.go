@@ -0,0 +1,95 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+)
+
+func testBucketMounts(provider MountProvider) (Volumes, Secrets) {
+	volumes := Volumes{
+		TrainingDataVolumes: []*COSVolume{{ID: "cosinputmount-data-job1", Bucket: "data", Endpoint: "https://s3.example.com", Region: "us",
+			SecretRef: "cossecretdata-job1", MountSpec: VolumeMountSpec{MountPath: "/mnt/data/data"}, Provider: provider}},
+		ResultsDir: &COSVolume{ID: "cosoutputmount-job1", Bucket: "results", SecretRef: "cossecretresults-job1",
+			MountSpec: VolumeMountSpec{MountPath: "/mnt/results/results"}, Provider: provider},
+	}
+	secrets := Secrets{TrainingDataSecret: &COSVolumeSecret{ID: "cossecretdata-job1", TrainingID: "training-1", Username: "user", APIKey: "key", Provider: provider}}
+	return volumes, secrets
+}
+
+func TestS3FSFlexMounts(t *testing.T) {
+	volumes, secrets := testBucketMounts(nil)
+	specs := volumes.CreateVolumeForLearner()
+	assert.Len(t, specs, 2)
+	assert.Equal(t, cosMountDriverName, specs[0].FlexVolume.Driver)
+	assert.Equal(t, "20", specs[0].FlexVolume.Options["parallel-count"])
+	assert.Equal(t, "5", specs[1].FlexVolume.Options["parallel-count"])
+	assert.Empty(t, volumes.CreateVolumeClaimsForLearner("training-1"))
+	assert.Empty(t, volumes.CreateSidecarsForLearner())
+
+	secretSpecs := CreateVolumeSecretsSpec(secrets)
+	assert.Equal(t, v1core.SecretType(cosMountDriverName), secretSpecs[0].Type)
+	assert.Equal(t, "key", secretSpecs[0].StringData[CredentialsSecretKey])
+}
+
+func TestCSIMounts(t *testing.T) {
+	volumes, secrets := testBucketMounts(CSIProvider{StorageClass: "csi-s3"})
+	specs := volumes.CreateVolumeForLearner()
+	assert.Equal(t, "cosinputmount-data-job1", specs[0].PersistentVolumeClaim.ClaimName)
+
+	claims := volumes.CreateVolumeClaimsForLearner("training-1")
+	assert.Len(t, claims, 2)
+	assert.Equal(t, "csi-s3", *claims[0].Spec.StorageClassName)
+	assert.Equal(t, "training-1", claims[0].Labels["training_id"])
+	assert.Empty(t, claims[0].Annotations)
+
+	secretSpecs := CreateVolumeSecretsSpec(secrets)
+	assert.Equal(t, v1core.SecretTypeOpaque, secretSpecs[0].Type)
+
+	//only the training data secret has credentials, the results mount has none to hand to the driver
+	claimSecrets := volumes.CreateClaimSecretsForLearner(secrets)
+	assert.Len(t, claimSecrets, 1)
+	assert.Equal(t, claims[0].Name, claimSecrets[0].Name)
+	assert.Equal(t, "data", claimSecrets[0].StringData[csiSecretBucketKey])
+	assert.Equal(t, "https://s3.example.com", claimSecrets[0].StringData[csiSecretEndpointKey])
+	assert.Equal(t, "key", claimSecrets[0].StringData[CredentialsSecretKey])
+	assert.Empty(t, volumes.MountWaitCommand())
+}
+
+func TestFUSEMounts(t *testing.T) {
+	volumes, _ := testBucketMounts(FUSEProvider{Image: "goofys", Command: "goofys -f $BUCKET $MOUNT_PATH"})
+	specs := volumes.CreateVolumeForLearner()
+	assert.NotNil(t, specs[0].EmptyDir)
+
+	mounts := volumes.CreateVolumeMountsForLearner()
+	assert.Equal(t, "/mnt/data/data", mounts[0].MountPath)
+	assert.Equal(t, v1core.MountPropagationHostToContainer, *mounts[0].MountPropagation)
+
+	sidecars := volumes.CreateSidecarsForLearner()
+	assert.Len(t, sidecars, 2)
+	assert.Equal(t, "mount-0", sidecars[0].Name)
+	assert.True(t, *sidecars[0].SecurityContext.Privileged)
+	assert.Equal(t, v1core.MountPropagationBidirectional, *sidecars[0].VolumeMounts[0].MountPropagation)
+	assert.Equal(t, "data", envVarValue(sidecars[0].Env, "BUCKET"))
+	assert.Equal(t, "results", envVarValue(sidecars[1].Env, "BUCKET"))
+	assert.Empty(t, volumes.CreateVolumeClaimsForLearner("training-1"))
+	assert.Contains(t, volumes.MountWaitCommand(), `grep -qs " /mnt/data/data fuse" /proc/mounts`)
+	assert.Contains(t, volumes.MountWaitCommand(), `grep -qs " /mnt/results/results fuse" /proc/mounts`)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	//SSHCertsMountPath is where the per training ssh key pair is mounted into the learners
+	SSHCertsMountPath = "/etc/ssh-certs"
+	//MPIHostfileMountPath is where the hostfile configmap is mounted into the launcher
+	MPIHostfileMountPath = "/etc/mpi"
+
+	mpiHostfileKey    = "hostfile"
+	mpiHostfileVolume = "mpi-hostfile"
+)
+
+//the ssh key pair of the training lets the launcher log into every worker
+const sshSetupCommand = `mkdir -p $HOME/.ssh ;
+			cp ` + SSHCertsMountPath + `/ssh-privatekey $HOME/.ssh/id_rsa ;
+			cp ` + SSHCertsMountPath + `/ssh-publickey $HOME/.ssh/authorized_keys ;
+			chmod 700 $HOME/.ssh ; chmod 600 $HOME/.ssh/id_rsa $HOME/.ssh/authorized_keys ;
+			printf "Host *\n  StrictHostKeyChecking no\n  UserKnownHostsFile /dev/null\n" > $HOME/.ssh/config ;`
+
+//env vars of the training that are forwarded by mpirun to the processes on the workers
+var mpiForwardedEnvVars = []string{"PATH", "LD_LIBRARY_PATH", "PYTHONPATH", "MODEL_DIR", "DATA_DIR", "RESULT_DIR", "LOG_DIR", "CHECKPOINT_DIR", "TRAINING_ID", "JOB_STATE_DIR", ResumeFromCheckpointEnvVar}
+
+//forwards the DATA_DIR_<NAME> env vars of the input datasets, their names are only known in the launcher
+const mpiForwardedDataDirs = `$(env | sed -n 's/^\(DATA_DIR_[A-Za-z0-9_]*\)=.*/-x \1/p')`
+
+//MPIHostfile has a line per worker with a slot for each of its GPUs
+func MPIHostfile(cluster Cluster) string {
+	var hostfile bytes.Buffer
+	for i := 0; i < cluster.NumLearners; i++ {
+		fmt.Fprintf(&hostfile, "%s slots=%d\n", cluster.HostName(i), cluster.processesPerLearner())
+	}
+	return hostfile.String()
+}
+
+//MPIProcesses is the number of processes mpirun starts across the workers
+func MPIProcesses(cluster Cluster) int {
+	return cluster.NumLearners * cluster.processesPerLearner()
+}
+
+//CreateMPIHostfileConfigMap ... the hostfile of the workers, labelled with the training id so it is deleted with the training
+func CreateMPIHostfileConfigMap(name, trainingID string, cluster Cluster) *v1core.ConfigMap {
+	return &v1core.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"training_id": trainingID,
+			},
+		},
+		Data: MPIHostfileData(cluster),
+	}
+}
+
+//MPIHostfileData is the content of the hostfile configmap, it is rewritten when an elastic training is scaled
+func MPIHostfileData(cluster Cluster) map[string]string {
+	return map[string]string{
+		mpiHostfileKey: MPIHostfile(cluster),
+	}
+}
+
+//MPIHostfileVolume ... volume and mount of the hostfile configmap in the launcher
+func MPIHostfileVolume(configMapName string) (v1core.Volume, v1core.VolumeMount) {
+	volume := v1core.Volume{
+		Name: mpiHostfileVolume,
+		VolumeSource: v1core.VolumeSource{
+			ConfigMap: &v1core.ConfigMapVolumeSource{
+				LocalObjectReference: v1core.LocalObjectReference{Name: configMapName},
+			},
+		},
+	}
+	mount := v1core.VolumeMount{Name: mpiHostfileVolume, MountPath: MPIHostfileMountPath, ReadOnly: true}
+	return volume, mount
+}
+
+//MPIWorkerCommand only runs sshd, the training processes are started by the launcher through mpirun
+func MPIWorkerCommand() string {
+	return sshSetupCommand + `
+			mkdir -p /var/run/sshd ;
+			exec /usr/sbin/sshd -D -e`
+}
+
+//MPIEnvVars ... the hostfile, the number of processes and the processes per worker for the launcher
+func MPIEnvVars(cluster Cluster) []v1core.EnvVar {
+	return []v1core.EnvVar{
+		{Name: "MPI_HOSTFILE", Value: path.Join(MPIHostfileMountPath, mpiHostfileKey)},
+		{Name: "MPI_NUM_PROCESSES", Value: strconv.Itoa(MPIProcesses(cluster))},
+		{Name: "MPI_SLOTS_PER_LEARNER", Value: strconv.Itoa(cluster.processesPerLearner())},
+	}
+}
+
+//MPILauncherCommand is the training command of the launcher, it waits for the sshd of every worker and
+//then runs the training command of the job through mpirun over all slots of the hostfile.
+//mpirun only reads the hostfile once, elastic trainings are run through horovodrun which follows the workers
+//in the learners configmap with its host discovery script and keeps running when workers come and go
+func MPILauncherCommand(trainingCommand string, elastic bool) string {
+	quoted := strings.Replace(trainingCommand, "'", `'\''`, -1)
+	launch := fmt.Sprintf(`mpirun --allow-run-as-root -np $MPI_NUM_PROCESSES --hostfile $MPI_HOSTFILE -bind-to none -map-by slot %s -wdir "$MODEL_DIR" bash -c '%s'`,
+		mpiForwardedFlags(), quoted)
+	if elastic {
+		launch = fmt.Sprintf(`cd "$MODEL_DIR" && horovodrun -np $MPI_NUM_PROCESSES --min-np $((MIN_LEARNERS * MPI_SLOTS_PER_LEARNER)) --max-np $((MAX_LEARNERS * MPI_SLOTS_PER_LEARNER)) --host-discovery-script $LEARNERS_DISCOVERY_SCRIPT bash -c '%s'`,
+			quoted)
+	}
+	return sshSetupCommand + `
+			for host in $(cut -d' ' -f1 $MPI_HOSTFILE); do
+				until ssh -o ConnectTimeout=5 $host true; do echo "waiting for $host" ; sleep 5; done ;
+			done ;
+			` + launch
+}
+
+//the -x flags of the env vars mpirun forwards to the workers
+func mpiForwardedFlags() string {
+	var forwarded []string
+	for _, name := range mpiForwardedEnvVars {
+		forwarded = append(forwarded, "-x "+name)
+	}
+	forwarded = append(forwarded, mpiForwardedDataDirs)
+	return strings.Join(forwarded, " ")
+}
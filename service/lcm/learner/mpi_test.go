@@ -0,0 +1,51 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMPIHostfile(t *testing.T) {
+	cluster := Cluster{StatefulSetName: "learner-job1", ServiceName: "learner-job1", NumLearners: 2, GPUsPerLearner: 4}
+	assert.Equal(t, "learner-job1-0.learner-job1 slots=4\nlearner-job1-1.learner-job1 slots=4\n", MPIHostfile(cluster))
+	assert.Equal(t, 8, MPIProcesses(cluster))
+	assert.Equal(t, "8", envVarValue(MPIEnvVars(cluster), "MPI_NUM_PROCESSES"))
+
+	configMap := CreateMPIHostfileConfigMap("mpi-hostfile-job1", "training-1", cluster)
+	assert.Equal(t, "training-1", configMap.Labels["training_id"])
+	assert.Equal(t, MPIHostfile(cluster), configMap.Data["hostfile"])
+
+	//cpu only workers get a single slot
+	cluster.GPUsPerLearner = 0
+	assert.Equal(t, "learner-job1-0.learner-job1 slots=1\nlearner-job1-1.learner-job1 slots=1\n", MPIHostfile(cluster))
+}
+
+func TestMPILauncherCommand(t *testing.T) {
+	cmd := MPILauncherCommand(`python train.py --name 'job'`, false)
+	assert.Contains(t, cmd, "mpirun --allow-run-as-root -np $MPI_NUM_PROCESSES --hostfile $MPI_HOSTFILE")
+	assert.Contains(t, cmd, `bash -c 'python train.py --name '\''job'\'''`)
+	assert.Contains(t, cmd, "-x "+ResumeFromCheckpointEnvVar+" "+mpiForwardedDataDirs+" -wdir")
+	assert.Contains(t, cmd, SSHCertsMountPath+"/ssh-privatekey")
+	assert.Contains(t, MPIWorkerCommand(), "sshd -D")
+
+	cmd = MPILauncherCommand(`python train.py`, true)
+	assert.NotContains(t, cmd, "mpirun")
+	assert.Contains(t, cmd, "horovodrun -np $MPI_NUM_PROCESSES --min-np $((MIN_LEARNERS * MPI_SLOTS_PER_LEARNER)) --max-np $((MAX_LEARNERS * MPI_SLOTS_PER_LEARNER)) --host-discovery-script $LEARNERS_DISCOVERY_SCRIPT")
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"fmt"
+	"strings"
+
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	//PlacementPack ... learners on as few nodes as possible, preferring nodes and racks that already run learners of the training
+	PlacementPack = "pack"
+	//PlacementSpread ... at most one learner of the training per node, so a lost node only takes one learner with it
+	PlacementSpread = "spread"
+	//PlacementTopology ... all learners of the training on nodes that share the value of the topology label
+	PlacementTopology = "topology"
+
+	hostnameTopologyKey = "kubernetes.io/hostname"
+
+	preferNodeWeight     = 100
+	preferTopologyWeight = 50
+)
+
+//Placement ... how the learners of a training are placed relative to each other, the zero value leaves it to the scheduler
+type Placement struct {
+	Policy      string
+	TopologyKey string
+	TrainingID  string
+}
+
+//legacyPlacementPolicies ... schedpolicy values of earlier releases and the policy they stand for
+var legacyPlacementPolicies = map[string]string{
+	"dense": PlacementPack,
+}
+
+//PlacementPolicy ... the placement policy of a schedpolicy, legacy values map to the policy they stand for.
+//Unknown values leave the placement to the scheduler, known is false for them.
+func PlacementPolicy(schedpolicy string) (policy string, known bool) {
+	policy = strings.ToLower(schedpolicy)
+	switch policy {
+	case "", PlacementPack, PlacementSpread, PlacementTopology:
+		return policy, true
+	}
+	if policy, ok := legacyPlacementPolicies[policy]; ok {
+		return policy, true
+	}
+	return "", false
+}
+
+//ValidatePlacement checks the topology policy has a topology label to co-locate on
+func ValidatePlacement(policy, topologyKey string) error {
+	if policy == PlacementTopology && topologyKey == "" {
+		return fmt.Errorf("placement policy %s needs a topology label", PlacementTopology)
+	}
+	return nil
+}
+
+//Affinity ... the zone node affinity of the learners with the pod affinity or anti-affinity of the placement policy
+func (p Placement) Affinity(nodeAffinity *v1core.NodeAffinity) *v1core.Affinity {
+	affinity := &v1core.Affinity{NodeAffinity: nodeAffinity}
+	switch p.Policy {
+	case PlacementPack:
+		preferred := []v1core.WeightedPodAffinityTerm{{Weight: preferNodeWeight, PodAffinityTerm: p.learnersOn(hostnameTopologyKey)}}
+		if p.TopologyKey != "" {
+			preferred = append(preferred, v1core.WeightedPodAffinityTerm{Weight: preferTopologyWeight, PodAffinityTerm: p.learnersOn(p.TopologyKey)})
+		}
+		affinity.PodAffinity = &v1core.PodAffinity{PreferredDuringSchedulingIgnoredDuringExecution: preferred}
+	case PlacementSpread:
+		affinity.PodAntiAffinity = &v1core.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []v1core.PodAffinityTerm{p.learnersOn(hostnameTopologyKey)},
+		}
+	case PlacementTopology:
+		affinity.PodAffinity = &v1core.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []v1core.PodAffinityTerm{p.learnersOn(p.TopologyKey)},
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1core.WeightedPodAffinityTerm{
+				{Weight: preferNodeWeight, PodAffinityTerm: p.learnersOn(hostnameTopologyKey)},
+			},
+		}
+	}
+	return affinity
+}
+
+//the learner pods of the training within one domain of topologyKey
+func (p Placement) learnersOn(topologyKey string) v1core.PodAffinityTerm {
+	return v1core.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"training_id": p.TrainingID,
+				"service":     "dlaas-learner",
+			},
+		},
+		TopologyKey: topologyKey,
+	}
+}
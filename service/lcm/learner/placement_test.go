@@ -0,0 +1,64 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlacementAffinity(t *testing.T) {
+	affinity := Placement{TrainingID: "training-1"}.Affinity(nil)
+	assert.Nil(t, affinity.PodAffinity)
+	assert.Nil(t, affinity.PodAntiAffinity)
+
+	affinity = Placement{Policy: PlacementPack, TopologyKey: "rack", TrainingID: "training-1"}.Affinity(nil)
+	preferred := affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Len(t, preferred, 2)
+	assert.Equal(t, hostnameTopologyKey, preferred[0].PodAffinityTerm.TopologyKey)
+	assert.Equal(t, "rack", preferred[1].PodAffinityTerm.TopologyKey)
+	assert.Equal(t, "training-1", preferred[0].PodAffinityTerm.LabelSelector.MatchLabels["training_id"])
+
+	affinity = Placement{Policy: PlacementSpread, TrainingID: "training-1"}.Affinity(nil)
+	assert.Nil(t, affinity.PodAffinity)
+	assert.Equal(t, hostnameTopologyKey, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey)
+
+	affinity = Placement{Policy: PlacementTopology, TopologyKey: "rack", TrainingID: "training-1"}.Affinity(nil)
+	assert.Equal(t, "rack", affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey)
+}
+
+func TestValidatePlacement(t *testing.T) {
+	assert.NoError(t, ValidatePlacement("", ""))
+	assert.NoError(t, ValidatePlacement(PlacementSpread, ""))
+	assert.NoError(t, ValidatePlacement(PlacementTopology, "rack"))
+	assert.Error(t, ValidatePlacement(PlacementTopology, ""))
+}
+
+func TestPlacementPolicy(t *testing.T) {
+	policy, known := PlacementPolicy("Spread")
+	assert.True(t, known)
+	assert.Equal(t, PlacementSpread, policy)
+
+	policy, known = PlacementPolicy("dense")
+	assert.True(t, known)
+	assert.Equal(t, PlacementPack, policy)
+
+	policy, known = PlacementPolicy("scatter")
+	assert.False(t, known)
+	assert.Equal(t, "", policy)
+}
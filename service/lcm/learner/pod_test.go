@@ -58,7 +58,7 @@ func createPodSpecForTesting() v1core.PodTemplateSpec {
 		},
 	}
 	termGracePeriodSecs := int64(0)
-	return CreatePodSpec([]v1core.Container{learnerContainer}, volumes, labelsMap, map[string]string{}, imagePullSecret, nodeAffinity, gpuToleration, termGracePeriodSecs)
+	return CreatePodSpec([]v1core.Container{learnerContainer}, volumes, labelsMap, map[string]string{}, imagePullSecret, nodeAffinity, Placement{}, gpuToleration, termGracePeriodSecs)
 
 }
 
@@ -83,7 +83,7 @@ func createNonSplitPodSpecForTesting() v1core.PodTemplateSpec {
 		},
 	}
 	termGracePeriodSecs := int64(0)
-	return CreatePodSpec([]v1core.Container{learnerContainer}, volumes, labelsMap, map[string]string{}, imagePullSecret, nodeAffinity, gpuToleration, termGracePeriodSecs)
+	return CreatePodSpec([]v1core.Container{learnerContainer}, volumes, labelsMap, map[string]string{}, imagePullSecret, nodeAffinity, Placement{}, gpuToleration, termGracePeriodSecs)
 }
 
 func createNonSplitSinglerLearnerContainer() v1core.Container {
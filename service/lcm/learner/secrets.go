@@ -23,9 +23,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-//COSVolumeSecret ...
+//COSVolumeSecret ... credentials of a bucket in the format of its provider, the s3fs flex driver if Provider is nil
 type COSVolumeSecret struct {
 	ID, TrainingID, Username, APIKey string
+	Provider                         MountProvider
 }
 
 //SSHVolumeSecret ...
@@ -38,6 +39,10 @@ type Secrets struct {
 	TrainingDataSecret *COSVolumeSecret
 	SSHVolumeSecret    *SSHVolumeSecret
 	ResultsDirSecret   *COSVolumeSecret
+	//DatasetSecrets ... of mounted input datasets, in the format of their provider
+	DatasetSecrets []*COSVolumeSecret
+	//CredentialsSecrets ... of downloaded input datasets, the helpers read the credentials keys of these opaque secrets
+	CredentialsSecrets []*COSVolumeSecret
 }
 
 //CreateVolumeSecretsSpec ...
@@ -46,12 +51,20 @@ func CreateVolumeSecretsSpec(secrets Secrets) []*v1core.Secret {
 	var secretSpecs []*v1core.Secret
 	if secrets.TrainingDataSecret != nil {
 		cosTrainingDataVolumeSecretParams := secrets.TrainingDataSecret
-		secretSpecs = append(secretSpecs, generateCOSVolumeSecret(cosTrainingDataVolumeSecretParams.ID, cosTrainingDataVolumeSecretParams.TrainingID, cosTrainingDataVolumeSecretParams.Username, cosTrainingDataVolumeSecretParams.APIKey))
+		secretSpecs = append(secretSpecs, mountProviderOf(cosTrainingDataVolumeSecretParams.Provider).Secret(cosTrainingDataVolumeSecretParams))
 	}
 
 	if secrets.ResultsDirSecret != nil {
 		cosResultDirVolumeSecretParams := secrets.ResultsDirSecret
-		secretSpecs = append(secretSpecs, generateCOSVolumeSecret(cosResultDirVolumeSecretParams.ID, cosResultDirVolumeSecretParams.TrainingID, cosResultDirVolumeSecretParams.Username, cosResultDirVolumeSecretParams.APIKey))
+		secretSpecs = append(secretSpecs, mountProviderOf(cosResultDirVolumeSecretParams.Provider).Secret(cosResultDirVolumeSecretParams))
+	}
+
+	for _, secret := range secrets.DatasetSecrets {
+		secretSpecs = append(secretSpecs, mountProviderOf(secret.Provider).Secret(secret))
+	}
+
+	for _, secret := range secrets.CredentialsSecrets {
+		secretSpecs = append(secretSpecs, generateMountSecret(secret))
 	}
 
 	if secrets.SSHVolumeSecret != nil {
@@ -72,8 +85,8 @@ func generateCOSVolumeSecret(id, trainingID, username, apikey string) *v1core.Se
 		},
 		Type: cosMountDriverName,
 		StringData: map[string]string{
-			"access-key": username,
-			"secret-key": apikey,
+			CredentialsAccessKey: username,
+			CredentialsSecretKey: apikey,
 		},
 	}
 
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package learner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1core "k8s.io/api/core/v1"
+)
+
+const (
+	//TensorFlowAdapter generates TF_CONFIG for tf.distribute strategies
+	TensorFlowAdapter = "tensorflow"
+
+	//TensorFlowWorkerPort every task of the tensorflow cluster listens on
+	TensorFlowWorkerPort int32 = 2222
+
+	//TFStrategyMultiWorkerMirrored learner-0 is the chief, all other learners are workers
+	TFStrategyMultiWorkerMirrored = "multi_worker_mirrored"
+	//TFStrategyParameterServer learner-0 is the chief, the last NumPS learners are parameter servers and the rest workers
+	TFStrategyParameterServer = "parameter_server"
+
+	tfEvaluatorTask = "evaluator"
+)
+
+type tensorflowAdapter struct {
+	Cluster
+}
+
+//ValidateTensorFlowLayout rejects strategies tasks does not know and parameter server layouts without a chief, a worker
+//and the parameter servers, an empty strategy is multi worker mirrored
+func ValidateTensorFlowLayout(strategy string, numPS int, numLearners int) error {
+	switch strategy {
+	case "", TFStrategyMultiWorkerMirrored:
+		return nil
+	case TFStrategyParameterServer:
+		if numPS < 1 {
+			return fmt.Errorf("the %s strategy needs at least one parameter server, not %d", strategy, numPS)
+		}
+		if numLearners < numPS+2 {
+			return fmt.Errorf("the %s strategy with %d parameter servers needs at least %d learners for the chief and a worker, not %d",
+				strategy, numPS, numPS+2, numLearners)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown tensorflow distribution strategy %q, use %s or %s", strategy, TFStrategyMultiWorkerMirrored, TFStrategyParameterServer)
+}
+
+//tasks returns the hosts of the chief, worker and ps tasks of the layout
+func (a tensorflowAdapter) tasks() (chief, workers, ps []string) {
+	numPS := 0
+	if a.Strategy == TFStrategyParameterServer {
+		numPS = a.NumPS
+		if numPS < 1 {
+			numPS = 1
+		}
+		if numPS > a.NumLearners-1 {
+			numPS = a.NumLearners - 1
+		}
+	}
+	numWorkers := a.NumLearners - 1 - numPS
+	for i := 0; i < a.NumLearners; i++ {
+		host := fmt.Sprintf("%s:%d", a.HostName(i), TensorFlowWorkerPort)
+		switch {
+		case i == 0:
+			chief = append(chief, host)
+		case i <= numWorkers:
+			workers = append(workers, host)
+		default:
+			ps = append(ps, host)
+		}
+	}
+	return chief, workers, ps
+}
+
+func (a tensorflowAdapter) clusterSpec() string {
+	if len(a.Groups) > 0 {
+		return a.groupClusterSpec()
+	}
+	chief, workers, ps := a.tasks()
+	cluster := map[string][]string{"chief": chief}
+	if len(workers) > 0 {
+		cluster["worker"] = workers
+	}
+	if len(ps) > 0 {
+		cluster["ps"] = ps
+	}
+	spec, _ := json.Marshal(cluster) //cannot fail for a map of strings
+	return string(spec)
+}
+
+func (a tensorflowAdapter) EnvVars() []v1core.EnvVar {
+	return []v1core.EnvVar{
+		{Name: "TF_CLUSTER_SPEC", Value: a.clusterSpec()},
+	}
+}
+
+//every replica group is a task type, the evaluator is not part of the cluster spec as it does not take part in the training
+func (a tensorflowAdapter) groupClusterSpec() string {
+	cluster := map[string][]string{}
+	for _, g := range a.Groups {
+		if g.Name == tfEvaluatorTask {
+			continue
+		}
+		for _, host := range g.Hosts() {
+			cluster[g.Name] = append(cluster[g.Name], fmt.Sprintf("%s:%d", host, TensorFlowWorkerPort))
+		}
+	}
+	spec, _ := json.Marshal(cluster) //cannot fail for a map of strings
+	return string(spec)
+}
+
+//TF_CONFIG combines the cluster spec with the task of the learner, which depends on the statefulset ordinal
+func (a tensorflowAdapter) Command() string {
+	if len(a.Groups) > 0 {
+		return fmt.Sprintf(`TASK_INDEX=${DOWNWARD_API_POD_NAME##*-} ;
+			export TF_CONFIG="{\"cluster\": $TF_CLUSTER_SPEC, \"task\": {\"type\": \"%s\", \"index\": $TASK_INDEX}}" ;`, a.Group)
+	}
+	_, workers, _ := a.tasks()
+	return fmt.Sprintf(`ORDINAL=${DOWNWARD_API_POD_NAME##*-} ;
+			if [ $ORDINAL -eq 0 ]; then TASK_TYPE=chief ; TASK_INDEX=0 ;
+			elif [ $ORDINAL -le %d ]; then TASK_TYPE=worker ; TASK_INDEX=$((ORDINAL - 1)) ;
+			else TASK_TYPE=ps ; TASK_INDEX=$((ORDINAL - %d)) ; fi ;
+			export TF_CONFIG="{\"cluster\": $TF_CLUSTER_SPEC, \"task\": {\"type\": \"$TASK_TYPE\", \"index\": $TASK_INDEX}}" ;`,
+		len(workers), len(workers)+1)
+}
+
+//the worker port is exposed on every learner already, see generateContainerSpec
+func (a tensorflowAdapter) Ports() []v1core.ContainerPort {
+	return nil
+}
@@ -17,16 +17,22 @@
 package learner
 
 import (
+	"fmt"
+
 	v1core "k8s.io/api/core/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
 )
 
 const cosMountDriverName = "ibm/ibmc-s3fs"
 
-//COSVolume ...
+//S3FSFlexSecretType ... the type of the secrets the s3fs flex driver accepts
+const S3FSFlexSecretType = v1core.SecretType(cosMountDriverName)
+
+//COSVolume ... a bucket mounted by its provider, the s3fs flex driver if Provider is nil
 type COSVolume struct {
 	ID, Region, Bucket, Endpoint, SecretRef, CacheSize, DiskFree string
 	MountSpec                                                    VolumeMountSpec
+	Provider                                                     MountProvider
 }
 
 //SSHVolume ...
@@ -42,9 +48,17 @@ type SHMVolume struct {
 	MountSpec VolumeMountSpec
 }
 
+//DatasetVolume ... an existing PVC or NFS export with training data, either ClaimName or NFSServer and NFSPath are set
+type DatasetVolume struct {
+	ID, ClaimName, NFSServer, NFSPath string
+	ReadOnly                          bool
+	MountSpec                         VolumeMountSpec
+}
+
 //Volumes ...
 type Volumes struct {
 	TrainingDataVolumes []*COSVolume
+	DatasetVolumes      []*DatasetVolume
 	ResultsDir          *COSVolume
 	SSHVolume           *SSHVolume
 	SHMVolume           *SHMVolume
@@ -69,14 +83,15 @@ func (volumes Volumes) CreateVolumeForLearner() []v1core.Volume {
 	if volumes.TrainingDataVolumes != nil {
 		trainingDataParams := volumes.TrainingDataVolumes
 		for _, trainingDataParam := range trainingDataParams {
-			volumeSpecs = append(volumeSpecs, generateCOSTrainingDataVolume(trainingDataParam.ID, trainingDataParam.Region, trainingDataParam.Bucket,
-				trainingDataParam.Endpoint, trainingDataParam.SecretRef, trainingDataParam.CacheSize, trainingDataParam.DiskFree))
+			volumeSpecs = append(volumeSpecs, mountProviderOf(trainingDataParam.Provider).Volume(trainingDataParam, false))
 		}
 	}
+	for _, datasetVolume := range volumes.DatasetVolumes {
+		volumeSpecs = append(volumeSpecs, generateDatasetVolume(datasetVolume))
+	}
 	if volumes.ResultsDir != nil {
 		resultDirParams := volumes.ResultsDir
-		volumeSpecs = append(volumeSpecs, generateCOSResultsVolume(resultDirParams.ID, resultDirParams.Region, resultDirParams.Bucket,
-			resultDirParams.Endpoint, resultDirParams.SecretRef, resultDirParams.CacheSize, resultDirParams.DiskFree))
+		volumeSpecs = append(volumeSpecs, mountProviderOf(resultDirParams.Provider).Volume(resultDirParams, true))
 	}
 
 	return volumeSpecs
@@ -95,15 +110,84 @@ func (volumes Volumes) CreateVolumeMountsForLearner() []v1core.VolumeMount {
 	if volumes.TrainingDataVolumes != nil {
 		trainingDataParams := volumes.TrainingDataVolumes
 		for _, trainingDataVolume := range trainingDataParams {
-			mounts = append(mounts, generateVolumeMount(trainingDataVolume.ID, trainingDataVolume.MountSpec.MountPath))
+			mounts = append(mounts, mountProviderOf(trainingDataVolume.Provider).VolumeMount(trainingDataVolume))
 		}
 	}
+	for _, datasetVolume := range volumes.DatasetVolumes {
+		mounts = append(mounts, v1core.VolumeMount{
+			Name:      datasetVolume.ID,
+			MountPath: datasetVolume.MountSpec.MountPath,
+			SubPath:   datasetVolume.MountSpec.SubPath,
+			ReadOnly:  datasetVolume.ReadOnly,
+		})
+	}
+	if volumes.ResultsDir != nil {
+		mounts = append(mounts, mountProviderOf(volumes.ResultsDir.Provider).VolumeMount(volumes.ResultsDir))
+	}
+	return mounts
+}
+
+//the buckets of the training data and the results
+func (volumes Volumes) bucketMounts() []*COSVolume {
+	mounts := append([]*COSVolume{}, volumes.TrainingDataVolumes...)
 	if volumes.ResultsDir != nil {
-		mounts = append(mounts, generateVolumeMount(volumes.ResultsDir.ID, volumes.ResultsDir.MountSpec.MountPath))
+		mounts = append(mounts, volumes.ResultsDir)
 	}
 	return mounts
 }
 
+//CreateVolumeClaimsForLearner ... claims the mount providers bind their volumes through, created before the learners
+func (volumes Volumes) CreateVolumeClaimsForLearner(trainingID string) []*v1core.PersistentVolumeClaim {
+	var claims []*v1core.PersistentVolumeClaim
+	for _, mount := range volumes.bucketMounts() {
+		if claim := mountProviderOf(mount.Provider).Claim(mount, trainingID); claim != nil {
+			claims = append(claims, claim)
+		}
+	}
+	return claims
+}
+
+//CreateClaimSecretsForLearner ... secrets of the claims of the mount providers, with the credentials of the secret each mount refers to
+func (volumes Volumes) CreateClaimSecretsForLearner(secrets Secrets) []*v1core.Secret {
+	credentials := make(map[string]*COSVolumeSecret)
+	for _, secret := range append([]*COSVolumeSecret{secrets.TrainingDataSecret, secrets.ResultsDirSecret}, secrets.DatasetSecrets...) {
+		if secret != nil {
+			credentials[secret.ID] = secret
+		}
+	}
+	var claimSecrets []*v1core.Secret
+	for _, mount := range volumes.bucketMounts() {
+		provider, ok := mountProviderOf(mount.Provider).(claimSecretProvider)
+		if !ok || credentials[mount.SecretRef] == nil {
+			continue
+		}
+		claimSecrets = append(claimSecrets, provider.ClaimSecret(mount, credentials[mount.SecretRef]))
+	}
+	return claimSecrets
+}
+
+//MountWaitCommand ... shell commands the learner runs first to wait for the buckets the sidecars of the mount providers mount
+func (volumes Volumes) MountWaitCommand() string {
+	var cmd string
+	for _, mount := range volumes.bucketMounts() {
+		if waiter, ok := mountProviderOf(mount.Provider).(mountWaiter); ok {
+			cmd += waiter.MountWaitCommand(mount)
+		}
+	}
+	return cmd
+}
+
+//CreateSidecarsForLearner ... containers the mount providers need next to the learner container
+func (volumes Volumes) CreateSidecarsForLearner() []v1core.Container {
+	var sidecars []v1core.Container
+	for i, mount := range volumes.bucketMounts() {
+		if sidecar := mountProviderOf(mount.Provider).Sidecar(fmt.Sprintf("mount-%d", i), mount); sidecar != nil {
+			sidecars = append(sidecars, *sidecar)
+		}
+	}
+	return sidecars
+}
+
 func generateSSHVolume(id, secretName string) v1core.Volume {
 	//defining SSH cert as volume
 	var permissions int32
@@ -198,6 +282,30 @@ func generateCOSResultsVolume(id, region, bucket, endpoint, secretRef, cacheSize
 	return cosOutputVolume
 }
 
+func generateDatasetVolume(dataset *DatasetVolume) v1core.Volume {
+	if dataset.ClaimName != "" {
+		return v1core.Volume{
+			Name: dataset.ID,
+			VolumeSource: v1core.VolumeSource{
+				PersistentVolumeClaim: &v1core.PersistentVolumeClaimVolumeSource{
+					ClaimName: dataset.ClaimName,
+					ReadOnly:  dataset.ReadOnly,
+				},
+			},
+		}
+	}
+	return v1core.Volume{
+		Name: dataset.ID,
+		VolumeSource: v1core.VolumeSource{
+			NFS: &v1core.NFSVolumeSource{
+				Server:   dataset.NFSServer,
+				Path:     dataset.NFSPath,
+				ReadOnly: dataset.ReadOnly,
+			},
+		},
+	}
+}
+
 func generateVolumeMount(id, bucket string) v1core.VolumeMount {
 	return v1core.VolumeMount{
 		Name:      id,
@@ -24,9 +24,9 @@ import (
 
 	"github.com/spf13/viper"
 
-	"github.com/AISphere/ffdl-lcm/service/lcm/policies"
 
 	"github.com/AISphere/ffdl-lcm/service/lcm/certs"
+	"github.com/AISphere/ffdl-lcm/service/lcm/frameworks"
 	"github.com/AISphere/ffdl-lcm/service/lcm/helper"
 	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
 	"github.com/sirupsen/logrus"
@@ -63,6 +63,7 @@ type training struct {
 	k8sClient  kubernetes.Interface
 	req        *service.JobDeploymentRequest
 	trainingID string
+	images     *jobImages
 	learner    learnerDefinition
 	helper     helperDefinition
 	logr       *logger.LocLoggingEntry
@@ -71,15 +72,18 @@ type training struct {
 type splitTrainingBOM struct {
 	secrets              []*v1core.Secret
 	networkPolicy        *v1networking.NetworkPolicy
-	service              *v1core.Service
+	services             []*v1core.Service
+	configMaps           []*v1core.ConfigMap
 	sharedVolumeClaimBOM *v1core.PersistentVolumeClaim
-	learnerBOM           *v1beta1.StatefulSet
+	volumeClaims         []*v1core.PersistentVolumeClaim
+	learnerBOMs          []*v1beta1.StatefulSet
 	helperBOM            *v1beta1.Deployment
 	numLearners          int
 }
 
 type nonSplitTrainingBOM struct {
 	secrets       []*v1core.Secret
+	volumeClaims  []*v1core.PersistentVolumeClaim
 	networkPolicy *v1networking.NetworkPolicy
 	service       *v1core.Service
 	learnerBOM    *v1beta1.StatefulSet
@@ -98,9 +102,13 @@ type learnerDefinition struct {
 	secrets                                                                             []*v1core.Secret
 	networkingPolicy                                                                    *v1networking.NetworkPolicy
 	volumes                                                                             []v1core.Volume
+	volumeClaims                                                                        []*v1core.PersistentVolumeClaim
 	volumeMounts                                                                        []v1core.VolumeMount
+	mountWaitCommand                                                                    string
 	envVars                                                                             []v1core.EnvVar
+	sidecars                                                                            []v1core.Container
 	mountTrainingDataStoreInLearner, mountResultsStoreInLearner, mountSSHCertsInLearner bool
+	dataStoreUnused                                                                     bool
 	numberOfLearners                                                                    int
 	name                                                                                string
 }
@@ -114,22 +122,18 @@ type helperDefinition struct {
 	sharedVolumeMount   v1core.VolumeMount
 	sharedEnvVars       []v1core.EnvVar
 	sharedVolumeClaim   *v1core.PersistentVolumeClaim
+	resources           helperResourceProfiles
 	name                string
 }
 
 //NewTraining ...
-func NewTraining(ctx context.Context, k8sClient kubernetes.Interface, req *service.JobDeploymentRequest, log *logger.LocLoggingEntry) Training {
-	const cosMountDriverName = "ibm/ibmc-s3fs"
-	const cosMountType = "mount_cos"
+func NewTraining(ctx context.Context, k8sClient kubernetes.Interface, req *service.JobDeploymentRequest, images *jobImages, log *logger.LocLoggingEntry) Training {
 	learnerName := fmt.Sprintf("learner-%s", req.Name)
 	helperName := fmt.Sprintf("lhelper-%s", req.Name)
-	numLearners := int(req.GetResources().Learners)
-	if numLearners < 1 {
-		numLearners = 1
-	}
+	numLearners := totalLearners(req)
 
-	mountTrainingDataStoreInLearner := req.EnvVars["DATA_STORE_TYPE"] == cosMountType
-	mountResultsStoreInLearner := req.EnvVars["RESULT_STORE_TYPE"] == cosMountType
+	mountTrainingDataStoreInLearner := mountProvider(req.EnvVars["DATA_STORE_TYPE"]) != nil
+	mountResultsStoreInLearner := mountProvider(req.EnvVars["RESULT_STORE_TYPE"]) != nil
 	mountSSHCertsInLearner := certs.NeedsMountedSSHCerts(req.Framework, req.Version)
 
 	logr := log.WithFields(logrus.Fields{
@@ -144,6 +148,11 @@ func NewTraining(ctx context.Context, k8sClient kubernetes.Interface, req *servi
 	envVarsFromDeploymentRequest := extractEnvVarsFromDeploymentRequest(req) //shared across all containers of training
 	envvarsForLearner := envVarsForDeployingLearner(envVarsFromDeploymentRequest, req.TrainingId,
 		numLearners, learnerName, mountTrainingDataStoreInLearner, mountResultsStoreInLearner) //only for learner
+	if adapter := distributedAdapterForLearners(req); adapter != nil {
+		envvarsForLearner = append(envvarsForLearner, adapter.EnvVars()...)
+	}
+	envvarsForLearner = append(envvarsForLearner, learner.Cluster{Groups: replicaGroupsOfCluster(req)}.HostsEnvVars()...)
+	envvarsForLearner = append(envvarsForLearner, inputDatasetEnvVars(req)...)
 
 	learnerVolumes := volumesForLearner(req, envvarsForLearner, mountTrainingDataStoreInLearner, mountResultsStoreInLearner, logr)
 	if config.IsFfDLExtendedEnabled() {
@@ -151,17 +160,28 @@ func NewTraining(ctx context.Context, k8sClient kubernetes.Interface, req *servi
 		learnerVolumeSpecs = extendLearnerVolumes(learnerVolumeSpecs, logr)
 	}
 	learnerDefn := learnerDefinition{
-		secrets:                         secretsForDeployingLearner(req, mountTrainingDataStoreInLearner, mountResultsStoreInLearner),
-		networkingPolicy:                networkPoliciesForDistributedLearners(numLearners, req),
+		secrets:                         secretsForDeployingLearner(req, learnerVolumes, mountTrainingDataStoreInLearner, mountResultsStoreInLearner),
+		networkingPolicy:                networkPolicyForLearners(req),
 		volumes:                         learnerVolumes.CreateVolumeForLearner(),
+		volumeClaims:                    learnerVolumes.CreateVolumeClaimsForLearner(req.TrainingId),
 		volumeMounts:                    learnerVolumes.CreateVolumeMountsForLearner(),
+		mountWaitCommand:                learnerVolumes.MountWaitCommand(),
 		envVars:                         envvarsForLearner,
+		sidecars:                        learnerVolumes.CreateSidecarsForLearner(),
 		numberOfLearners:                numLearners,
 		mountTrainingDataStoreInLearner: mountTrainingDataStoreInLearner,
 		mountResultsStoreInLearner:      mountResultsStoreInLearner,
 		mountSSHCertsInLearner:          mountSSHCertsInLearner,
+		dataStoreUnused:                 dataStoreUnused(req),
 		name:                            learnerName,
 	}
+	if minLearners, maxLearners, elastic := elasticBounds(req); elastic {
+		//the learners find the current number of learners and their hosts in the learners configmap
+		configVolume, configVolumeMount := learner.LearnersConfigVolume(learnersConfigName(req.Name))
+		learnerDefn.envVars = append(learnerDefn.envVars, learner.ElasticEnvVars(minLearners, maxLearners)...)
+		learnerDefn.volumes = append(learnerDefn.volumes, configVolume)
+		learnerDefn.volumeMounts = append(learnerDefn.volumeMounts, configVolumeMount)
+	}
 
 	helperVolumes := volumesForHelper(req, logr)
 	helperDefn := helperDefinition{
@@ -173,41 +193,50 @@ func NewTraining(ctx context.Context, k8sClient kubernetes.Interface, req *servi
 		sharedVolume:        helperVolumes.CreateDataVolume(req.Name),
 		sharedVolumeMount:   helperVolumes.CreateDataVolumeMount(),
 		sharedVolumeClaim:   helperVolumes.DynamicPVCReference(),
+		resources:           loadHelperResourceProfiles(),
 		name:                helperName,
 	}
 
 	if helperVolumes.SharedNonSplitLearnerHelperVolume != nil {
 		//this should not be the default case, we should be running in split mode by default
 		logr.Warnf("starting deploying learner infra for non split learning, this is not expected")
-		return nonSplitTraining{&training{ctx, k8sClient, req, req.TrainingId, learnerDefn, helperDefn, logr}}
+		return nonSplitTraining{&training{ctx, k8sClient, req, req.TrainingId, images, learnerDefn, helperDefn, logr}}
 	}
 	logr.Infof("starting deploying learner infra for split learning")
-	return splitTraining{&training{ctx, k8sClient, req, req.TrainingId, learnerDefn, helperDefn, logr}}
+	return splitTraining{&training{ctx, k8sClient, req, req.TrainingId, images, learnerDefn, helperDefn, logr}}
 }
 
 ///-------
 
-func secretsForDeployingLearner(req *service.JobDeploymentRequest, mountTrainingDataStoreInLearner, mountResultsStoreInLearner bool) []*v1core.Secret {
+func secretsForDeployingLearner(req *service.JobDeploymentRequest, learnerVolumes learner.Volumes, mountTrainingDataStoreInLearner, mountResultsStoreInLearner bool) []*v1core.Secret {
 	//irrespective of split/non split learners these secrets need to be created
 
 	secretsStruct := learner.Secrets{}
 
 	if mountTrainingDataStoreInLearner {
 		trainingMountSecretName := "cossecretdata-" + req.Name
-		secretsStruct.TrainingDataSecret = &learner.COSVolumeSecret{ID: trainingMountSecretName, TrainingID: req.TrainingId, Username: req.EnvVars["DATA_STORE_USERNAME"], APIKey: req.EnvVars["DATA_STORE_APIKEY"]}
+		secretsStruct.TrainingDataSecret = &learner.COSVolumeSecret{ID: trainingMountSecretName, TrainingID: req.TrainingId, Username: req.EnvVars["DATA_STORE_USERNAME"], APIKey: req.EnvVars["DATA_STORE_APIKEY"],
+			Provider: mountProvider(req.EnvVars["DATA_STORE_TYPE"])}
 	}
 
 	if mountResultsStoreInLearner {
 		resultsMountSecretName := "cossecretresults-" + req.Name
-		secretsStruct.ResultsDirSecret = &learner.COSVolumeSecret{ID: resultsMountSecretName, TrainingID: req.TrainingId, Username: req.EnvVars["RESULT_STORE_USERNAME"], APIKey: req.EnvVars["RESULT_STORE_APIKEY"]}
+		secretsStruct.ResultsDirSecret = &learner.COSVolumeSecret{ID: resultsMountSecretName, TrainingID: req.TrainingId, Username: req.EnvVars["RESULT_STORE_USERNAME"], APIKey: req.EnvVars["RESULT_STORE_APIKEY"],
+			Provider: mountProvider(req.EnvVars["RESULT_STORE_TYPE"])}
 	}
 
+	addInputDatasetSecrets(req, &secretsStruct)
+
 	if certs.NeedsMountedSSHCerts(req.Framework, req.Version) {
 		sshSecretName := "jobsshcert-" + req.Name
 		secretsStruct.SSHVolumeSecret = &learner.SSHVolumeSecret{ID: sshSecretName, TrainingID: req.TrainingId, Framework: req.Framework, Version: req.Version}
 	}
 
 	secretSpecs := learner.CreateVolumeSecretsSpec(secretsStruct)
+	secretSpecs = append(secretSpecs, learnerVolumes.CreateClaimSecretsForLearner(secretsStruct)...)
+	if secret := credentialsSecret(req); secret != nil {
+		secretSpecs = append(secretSpecs, secret)
+	}
 
 	return secretSpecs
 }
@@ -216,31 +245,22 @@ func volumesForLearner(req *service.JobDeploymentRequest, learnerEnvVars []v1cor
 	volumesStruct := learner.Volumes{}
 	if certs.NeedsMountedSSHCerts(req.Framework, req.Version) {
 		volumesStruct.SSHVolume = &learner.SSHVolume{ID: "sshcertmount-" + req.Name, SecretName: "jobsshcert-" + req.Name,
-			MountSpec: learner.VolumeMountSpec{MountPath: "/etc/ssh-certs", SubPath: ""}}
+			MountSpec: learner.VolumeMountSpec{MountPath: learner.SSHCertsMountPath, SubPath: ""}}
 	}
 
 	shmVolumeSize := getSHMVolumeSize(req.Framework, req.Version)
 	if shmVolumeSize > 0 {
 		volumesStruct.SHMVolume = &learner.SHMVolume{ID: "shmvolume-" + req.Name, Size: shmVolumeSize, MountSpec: learner.VolumeMountSpec{MountPath: "/dev/shm"}}
 	}
+	volumesStruct.DatasetVolumes = learnerDatasetVolumes(req)
 
 	if mountTrainingDataStoreInLearner {
 		region := req.EnvVars["DATA_STORE_REGION"]
 		if region == "" {
 			region = "us-standard"
 		}
-		configValStr := config.GetString("MOUNTCOS_GB_CACHE_PER_GPU")
-		cacheSize, err := strconv.Atoi(configValStr)
-		if err != nil {
-			cacheSize = 6
-			logr.Warnf("DLAAS_MOUNTCOS_GB_CACHE_PER_GPU value %s is not an integer.  Defaulting to %dGB/GPU", configValStr, cacheSize)
-		}
-		cacheSize = cacheSize * int(req.Resources.Gpus)
-		// reserve 1/3 of cache for prefetching, up to a limit (diskFree is specified in MB, cache in GB)
-		diskFree := (cacheSize * 1024) / 3
-		if diskFree > 10000 {
-			diskFree = 10000
-		}
+		cacheSize := mountCacheSize(req, logr)
+		diskFree := mountDiskFree(cacheSize)
 
 		buckets := getDatastoreBuckets(req.EnvVars)
 		cacheSizePerBucket := cacheSize / len(buckets)
@@ -260,10 +280,12 @@ func volumesForLearner(req *service.JobDeploymentRequest, learnerEnvVars []v1cor
 					},
 					CacheSize: strconv.Itoa(cacheSizePerBucket),
 					DiskFree:  strconv.Itoa(diskFree),
+					Provider:  mountProvider(req.EnvVars["DATA_STORE_TYPE"]),
 				})
 			}
 		}
 	}
+	volumesStruct.TrainingDataVolumes = append(volumesStruct.TrainingDataVolumes, inputDatasetVolumes(req, logr)...)
 	if mountResultsStoreInLearner {
 		region := req.EnvVars["RESULT_STORE_REGION"]
 		if region == "" {
@@ -284,12 +306,33 @@ func volumesForLearner(req *service.JobDeploymentRequest, learnerEnvVars []v1cor
 			},
 			CacheSize: "0",
 			DiskFree:  "2048",
+			Provider:  mountProvider(req.EnvVars["RESULT_STORE_TYPE"]),
 		}
 	}
 
 	return volumesStruct
 }
 
+//mountCacheSize is the cache of the mounted training data in GB, MOUNTCOS_GB_CACHE_PER_GPU for every GPU of a learner
+func mountCacheSize(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) int {
+	configValStr := config.GetString("MOUNTCOS_GB_CACHE_PER_GPU")
+	cacheSize, err := strconv.Atoi(configValStr)
+	if err != nil {
+		cacheSize = 6
+		logr.Warnf("DLAAS_MOUNTCOS_GB_CACHE_PER_GPU value %s is not an integer.  Defaulting to %dGB/GPU", configValStr, cacheSize)
+	}
+	return cacheSize * int(req.Resources.Gpus)
+}
+
+//mountDiskFree reserves 1/3 of cache for prefetching, up to a limit (diskFree is specified in MB, cache in GB)
+func mountDiskFree(cacheSize int) int {
+	diskFree := (cacheSize * 1024) / 3
+	if diskFree > 10000 {
+		diskFree = 10000
+	}
+	return diskFree
+}
+
 func getDatastoreBuckets(envVars map[string]string) map[string]string {
 	buckets := make(map[string]string)
 	buckets["DATA_STORE_OBJECTID"] = envVars["DATA_STORE_OBJECTID"]
@@ -301,6 +344,13 @@ func getDatastoreBuckets(envVars map[string]string) map[string]string {
 	return buckets
 }
 
+//nonSplitLearners is true if the learners run the helper containers themselves, trainings without a shared job volume
+//and all trainings while fluentd collects the evaluation metrics
+func nonSplitLearners(req *service.JobDeploymentRequest) bool {
+	useSplitLearner := getStorageSize(req.Resources) > 0 || req.Labels[staticVolumeLabel] != ""
+	return !useSplitLearner || viper.GetBool(config.LcmFluentdEmetricsEnable)
+}
+
 func volumesForHelper(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) helper.Volumes {
 	volumesStruct := helper.Volumes{}
 
@@ -310,15 +360,13 @@ func volumesForHelper(req *service.JobDeploymentRequest, logr *logger.LocLogging
 	logr.Debugf("Requested storage for job of size %d bytes", volumeSize)
 	useDynamicExternalVolume := volumeSize > 0
 
-	staticVolumeName := getStaticVolume(req.Labels["deploy_zone"], logr)
+	staticVolumeName := req.Labels[staticVolumeLabel] //leased in deployTrainingJobComponents
 	logr.Debugf("Static volume for job: %s", staticVolumeName)
 	useStaticExternalVolume := len(staticVolumeName) > 0
 
 	logr.Infof("DLAAS_LCM_FLUENTD_EMETRICS_ENABLE is set to %v", viper.GetBool(config.LcmFluentdEmetricsEnable))
 
-	useSplitLearner := useDynamicExternalVolume || useStaticExternalVolume
-
-	if !useSplitLearner || viper.GetBool(config.LcmFluentdEmetricsEnable) {
+	if nonSplitLearners(req) {
 		logr.Infof("Starting training %s with NON SPLIT MODE %d", req.TrainingId, volumeSize)
 		volumesStruct.SharedNonSplitLearnerHelperVolume = &helper.LocalVolume{
 			Name: "jobdata",
@@ -334,7 +382,12 @@ func volumesForHelper(req *service.JobDeploymentRequest, logr *logger.LocLogging
 				MountSpec: helper.VolumeMountSpec{MountPath: PodLevelJobDir, SubPath: req.TrainingId}}
 
 		} else if useDynamicExternalVolume {
-			sharedVolumeClaim := constructVolumeClaim(req.Name, config.GetLearnerNamespace(), volumeSize, map[string]string{"training_id": req.TrainingId})
+			profile, err := storageProfileFor(req) //validated in DeployTrainingJob, the profiles may have changed since
+			if err != nil {
+				logr.WithError(err).Warnf("Using the built-in default storage profile for training %s", req.TrainingId)
+				profile = defaultStorageProfile
+			}
+			sharedVolumeClaim := constructVolumeClaim(req.Name, config.GetLearnerNamespace(), profile, volumeSize, map[string]string{"training_id": req.TrainingId})
 			logr.Infof("Using dynamic external volume for Training %s with name %s", req.TrainingId, sharedVolumeClaim.Name)
 			volumesStruct.SharedSplitLearnerHelperVolume = &helper.SharedNFSVolume{Name: "jobdata", PVCClaimName: sharedVolumeClaim.Name, PVC: sharedVolumeClaim,
 				MountSpec: helper.VolumeMountSpec{MountPath: PodLevelJobDir, SubPath: req.TrainingId}}
@@ -347,6 +400,10 @@ func volumesForHelper(req *service.JobDeploymentRequest, logr *logger.LocLogging
 func extractEnvVarsFromDeploymentRequest(req *service.JobDeploymentRequest) []v1core.EnvVar {
 	var envVars []v1core.EnvVar
 	for k, v := range req.EnvVars {
+		if isCredentialEnvVar(k) {
+			envVars = append(envVars, credentialEnvVar(k, req.Name))
+			continue
+		}
 		envVars = append(envVars, v1core.EnvVar{
 			Name:  k,
 			Value: v,
@@ -362,20 +419,49 @@ func envVarsForDeployingLearner(existingEnvVars []v1core.EnvVar, trainingID stri
 
 }
 
-func networkPoliciesForDistributedLearners(numberOfLearners int, req *service.JobDeploymentRequest) *v1networking.NetworkPolicy {
-	if numberOfLearners > 1 { //network policies are only applicable for distributed learners
-		return policies.DefineNetworkPoliciesForTrainingID(req.Name, req.TrainingId)
-	}
-	return nil
+//the statefulset and its governing service are both named after the learner, see NewTraining
+//for trainings with replica groups req is the request of the group being deployed, see replicaGroupRequest
+func distributedAdapterForLearners(req *service.JobDeploymentRequest) learner.DistributedAdapter {
+	fw, _ := frameworks.Lookup(req.Framework, req.Version)
+	learnerName := fmt.Sprintf("learner-%s", req.Name)
+	numPS, _ := strconv.Atoi(req.EnvVars["TF_NUM_PS"])
+	return learner.NewDistributedAdapter(fw.Adapter, learner.Cluster{
+		StatefulSetName: learnerName,
+		ServiceName:     learnerName,
+		NumLearners:     totalLearners(req),
+		GPUsPerLearner:  int(req.GetResources().Gpus),
+		Strategy:        req.EnvVars["TF_DISTRIBUTION_STRATEGY"],
+		NumPS:           numPS,
+		Groups:          replicaGroupsOfCluster(req),
+		Group:           req.EnvVars[replicaGroupEnvVar],
+	})
+}
 
+//validateTensorFlowLayout checks TF_DISTRIBUTION_STRATEGY and TF_NUM_PS of tensorflow trainings, replica groups
+//name their tasks themselves and ignore both
+func validateTensorFlowLayout(req *service.JobDeploymentRequest) error {
+	fw, _ := frameworks.Lookup(req.Framework, req.Version)
+	if fw.Adapter != learner.TensorFlowAdapter || len(req.ReplicaGroups) > 0 {
+		return nil
+	}
+	numPS := 1
+	if v, ok := req.EnvVars["TF_NUM_PS"]; ok {
+		var err error
+		if numPS, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid TF_NUM_PS %q: %s", v, err)
+		}
+	}
+	return learner.ValidateTensorFlowLayout(req.EnvVars["TF_DISTRIBUTION_STRATEGY"], numPS, totalLearners(req))
 }
 
 func (t *training) constructAuxillaryContainers(isSplit bool) []v1core.Container {
 	learnerDefn := t.learner
 	helperDefn := t.helper
 	skipStoreResults := learnerDefn.mountResultsStoreInLearner || getValue(learnerDefn.envVars, "RESULT_STORE_OBJECTID") == noResultBucketTag
+	loadDataStore := !learnerDefn.mountTrainingDataStoreInLearner && !learnerDefn.dataStoreUnused
+	skipLoadData := !loadDataStore && !downloadsInputDatasets(t.req)
 	helperContainers := []v1core.Container{
-		constructControllerContainer(t.req.TrainingId, helperDefn.etcdVolumeMount, helperDefn.sharedVolumeMount, learnerDefn.mountTrainingDataStoreInLearner, skipStoreResults),
+		constructControllerContainer(t.req.TrainingId, helperDefn.etcdVolumeMount, helperDefn.sharedVolumeMount, skipLoadData, skipStoreResults, learnerDefn.mountResultsStoreInLearner, helperDefn.resources.containerResources(t.req, controllerContainerName)),
 	}
 	if useLogCollectors(t.k8sClient, t.logr) {
 		var sslCertsVolumeMount *v1core.VolumeMount = nil
@@ -384,27 +470,30 @@ func (t *training) constructAuxillaryContainers(isSplit bool) []v1core.Container
 			constructLogCollector(
 				sslCertsVolumeMount,
 				helperDefn.sharedVolumeMount,
-				t.k8sClient, t.req, helperDefn.sharedEnvVars, t.logr))
+				t.k8sClient, t.req, helperDefn.sharedEnvVars, helperDefn.resources.containerResources(t.req, logCollectorContainerName), t.logr))
 	}
 
-	if !learnerDefn.mountTrainingDataStoreInLearner {
-		helperContainers = append(helperContainers, constructLoadTrainingDataContainer(helperDefn.sharedVolumeMount, helperDefn.sharedEnvVars))
+	if !skipLoadData {
+		datasetContainers := constructLoadInputDatasetContainers(t.req, helperDefn.sharedVolumeMount, helperDefn.resources.containerResources(t.req, loadDataContainerName))
+		var datasetExits []string
+		for _, container := range datasetContainers {
+			datasetExits = append(datasetExits, container.Name)
+		}
+		helperContainers = append(helperContainers, constructLoadTrainingDataContainer(helperDefn.sharedVolumeMount, helperDefn.sharedEnvVars, loadDataStore, datasetExits, helperDefn.resources.containerResources(t.req, loadDataContainerName)))
+		helperContainers = append(helperContainers, datasetContainers...)
 	}
 	if !learnerDefn.mountResultsStoreInLearner && getValue(learnerDefn.envVars, "RESULT_STORE_OBJECTID") != noResultBucketTag {
-		helperContainers = append(helperContainers, constructLoadModelContainer(helperDefn.sharedVolumeMount, helperDefn.sharedEnvVars))
-		helperContainers = append(helperContainers, constructStoreResultsContainer(helperDefn.sharedVolumeMount, helperDefn.sharedEnvVars))
-		helperContainers = append(helperContainers, constructStoreLogsContainer(helperDefn.sharedVolumeMount, helperDefn.sharedEnvVars))
+		helperContainers = append(helperContainers, constructLoadModelContainer(helperDefn.sharedVolumeMount, helperDefn.sharedEnvVars, helperDefn.resources.containerResources(t.req, loadModelContainerName)))
+		helperContainers = append(helperContainers, constructStoreResultsContainer(helperDefn.sharedVolumeMount, helperDefn.sharedEnvVars, helperDefn.resources.containerResources(t.req, storeResultsContainerName)))
+		helperContainers = append(helperContainers, constructStoreLogsContainer(helperDefn.sharedVolumeMount, helperDefn.sharedEnvVars, helperDefn.resources.containerResources(t.req, storeLogsContainerName)))
 	}
 	return helperContainers
 }
 
 // Returns the amount of shared memory to give to the learner, in bytes.  A return value of 0 indicates that the default amount of memory should be used.
 func getSHMVolumeSize(framework, version string) int64 {
-	var result int64 // defaults to 0
-	if strings.EqualFold(framework, "pytorch") {
-		result = 4194304 // 4GB
-	}
-	return result
+	fw, _ := frameworks.Lookup(framework, version)
+	return fw.SHMSize
 }
 
 func getTolerations(gpuType string, tolerationSeconds int) []v1core.Toleration {
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+
+	"github.com/spf13/viper"
+)
+
+//the DATA_STORE_TYPE and RESULT_STORE_TYPE of buckets mounted into the learners instead of being copied by the helper
+const (
+	cosMountType  = "mount_cos"
+	csiMountType  = "mount_csi"
+	fuseMountType = "mount_fuse"
+)
+
+const (
+	//storage class of the csi driver that provisions volumes of buckets for mount_csi
+	mountCSIStorageClassKey = "mounts.csi.storage_class"
+	//image and command of the fuse sidecar for mount_fuse, see learner.FUSEProvider
+	mountFUSEImageKey   = "mounts.fuse.image"
+	mountFUSECommandKey = "mounts.fuse.command"
+
+	defaultFUSEImage   = "rclone/rclone:1.53.3"
+	defaultFUSECommand = `mkdir -p "$MOUNT_PATH" && exec rclone mount --s3-provider Other --s3-env-auth --s3-endpoint "$ENDPOINT" --s3-region "$REGION" ":s3:$BUCKET" "$MOUNT_PATH"`
+)
+
+func fuseMountProvider() learner.FUSEProvider {
+	provider := learner.FUSEProvider{Image: defaultFUSEImage, Command: defaultFUSECommand}
+	if viper.IsSet(mountFUSEImageKey) {
+		provider.Image = viper.GetString(mountFUSEImageKey)
+	}
+	if viper.IsSet(mountFUSECommandKey) {
+		provider.Command = viper.GetString(mountFUSECommandKey)
+	}
+	return provider
+}
+
+//mountProvider is the provider of a store type, nil if the store is not mounted
+func mountProvider(storeType string) learner.MountProvider {
+	switch storeType {
+	case cosMountType:
+		return learner.S3FSFlexProvider{}
+	case csiMountType:
+		return learner.CSIProvider{StorageClass: viper.GetString(mountCSIStorageClassKey)}
+	case fuseMountType:
+		return fuseMountProvider()
+	}
+	return nil
+}
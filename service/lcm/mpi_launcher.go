@@ -0,0 +1,134 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/frameworks"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+
+	"k8s.io/api/apps/v1beta1"
+	v1core "k8s.io/api/core/v1"
+)
+
+const (
+	//roles of the pods of an mpi training, the training ends when the launcher exits
+	mpiLauncherRole = "launcher"
+	mpiWorkerRole   = "worker"
+
+	//the launcher only runs mpirun, it does not need the resources of a worker
+	mpiLauncherCPUs      = 1
+	mpiLauncherMemoryMiB = 2048
+)
+
+//usesMPILauncher is true for frameworks whose learners are started through mpirun from a launcher pod
+func usesMPILauncher(req *service.JobDeploymentRequest) bool {
+	fw, _ := frameworks.Lookup(req.Framework, req.Version)
+	return fw.Launcher == frameworks.LauncherMPI
+}
+
+//learnerPods is the number of learner pods of a training, the launcher of an mpi training is one of them
+func learnerPods(req *service.JobDeploymentRequest) int {
+	if usesMPILauncher(req) {
+		return totalLearners(req) + 1
+	}
+	return totalLearners(req)
+}
+
+func mpiLauncherName(jobName string) string {
+	return fmt.Sprintf("mpi-launcher-%s", jobName)
+}
+
+func mpiHostfileName(jobName string) string {
+	return fmt.Sprintf("mpi-hostfile-%s", jobName)
+}
+
+//learnerCluster is the learner statefulset of a training without replica groups, the workers of an mpi training, see NewTraining
+func learnerCluster(req *service.JobDeploymentRequest) learner.Cluster {
+	learnerName := fmt.Sprintf("learner-%s", req.Name)
+	return learner.Cluster{
+		StatefulSetName: learnerName,
+		ServiceName:     learnerName,
+		NumLearners:     totalLearners(req),
+		GPUsPerLearner:  int(req.GetResources().Gpus),
+	}
+}
+
+//mpiLauncherRequest is the request of the launcher, a small cpu only learner running the training command through mpirun
+func mpiLauncherRequest(req *service.JobDeploymentRequest) *service.JobDeploymentRequest {
+	_, _, elastic := elasticBounds(req)
+	return replicaGroupRequest(req, &service.ReplicaGroup{
+		Name:     mpiLauncherRole,
+		Replicas: 1,
+		Resources: &service.ResourceRequirements{
+			Cpus:       mpiLauncherCPUs,
+			Memory:     mpiLauncherMemoryMiB,
+			MemoryUnit: service.ResourceRequirements_MiB,
+			GpuType:    req.GetResources().GpuType,
+		},
+		Command: learner.MPILauncherCommand(req.EnvVars["TRAINING_COMMAND"], elastic),
+	})
+}
+
+//deploys the workers of an mpi training which only run sshd, and the launcher which starts the training on them
+func (t splitTraining) startMPILauncher(logr *logger.LocLoggingEntry) error {
+	workers := learnerCluster(t.req)
+	hostfile := learner.CreateMPIHostfileConfigMap(mpiHostfileName(t.req.Name), t.req.TrainingId, workers)
+	serviceSpec := learner.CreateServiceSpec(t.learner.name, t.req.TrainingId)
+
+	workerReq := replicaGroupRequest(t.req, &service.ReplicaGroup{Name: mpiWorkerRole, Replicas: int32(workers.NumLearners)})
+	workerDefn := t.learner
+	workerDefn.envVars = replicaGroupEnvVars(t.learner.envVars, workerReq, t.learner.name)
+	workerSpec, err := t.statefulSetSpecForLearner(workerReq, workerDefn, serviceSpec.Name)
+	if err != nil {
+		logr.WithError(err).Errorf("Could not create statefulspec for mpi workers %s", workerDefn.name)
+		return err
+	}
+	workerSpec.Spec.Template.Spec.Containers[0].Command = []string{"bash", "-c", learner.MPIWorkerCommand()}
+	addElasticAnnotations(&workerSpec.ObjectMeta, t.req)
+
+	launcherReq := mpiLauncherRequest(t.req)
+	launcherName := mpiLauncherName(t.req.Name)
+	hostfileVolume, hostfileVolumeMount := learner.MPIHostfileVolume(hostfile.Name)
+	launcherDefn := t.learner
+	launcherDefn.name = launcherName
+	launcherDefn.numberOfLearners = 1
+	launcherDefn.envVars = append(replicaGroupEnvVars(t.learner.envVars, launcherReq, launcherName), learner.MPIEnvVars(workers)...)
+	launcherDefn.volumes = append(append([]v1core.Volume{}, t.learner.volumes...), hostfileVolume)
+	launcherDefn.volumeMounts = append(append([]v1core.VolumeMount{}, t.learner.volumeMounts...), hostfileVolumeMount)
+	launcherSpec, err := t.statefulSetSpecForLearner(launcherReq, launcherDefn, serviceSpec.Name)
+	if err != nil {
+		logr.WithError(err).Errorf("Could not create statefulspec for mpi launcher %s", launcherName)
+		return err
+	}
+
+	logr.Infof("deploying mpi launcher %s for %d workers with %d processes", launcherName, workers.NumLearners, learner.MPIProcesses(workers))
+	return t.CreateFromBOM(&splitTrainingBOM{
+		secrets:              t.learner.secrets,
+		networkPolicy:        t.learner.networkingPolicy,
+		services:             []*v1core.Service{serviceSpec},
+		configMaps:           append([]*v1core.ConfigMap{hostfile}, learnersConfigMaps(t.req)...),
+		sharedVolumeClaimBOM: t.helper.sharedVolumeClaim,
+		volumeClaims:         t.learner.volumeClaims,
+		learnerBOMs:          []*v1beta1.StatefulSet{workerSpec, launcherSpec},
+		helperBOM:            t.deploymentSpecForHelper(),
+		numLearners:          learnerPods(t.req),
+	})
+}
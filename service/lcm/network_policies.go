@@ -0,0 +1,98 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/configmaps"
+	"github.com/AISphere/ffdl-lcm/service/lcm/policies"
+	v1networking "k8s.io/api/networking/v1"
+)
+
+//networkPoliciesConfigPath is mounted from the lcm-network-policies configmap, only the default rules apply if it is absent
+var networkPoliciesConfigPath = "/etc/network-policies/network-policies.yaml"
+
+//networkPolicyRules ... ingress and egress rules of the learners next to the learner to learner traffic
+type networkPolicyRules struct {
+	//IsolateEgress opts into restricting the egress of the learners to the egress rules, egress is open otherwise
+	IsolateEgress bool            `yaml:"isolate_egress,omitempty"`
+	Ingress       []policies.Rule `yaml:"ingress,omitempty"`
+	Egress        []policies.Rule `yaml:"egress,omitempty"`
+}
+
+//the helper of the training reaches the learners. With isolated egress the learners and the helper containers of non-split
+//trainings still resolve names, reach object stores and registries over http(s) and the controller reaches etcd
+func defaultNetworkPolicyRules() networkPolicyRules {
+	return networkPolicyRules{
+		Ingress: []policies.Rule{
+			{Name: "helper", PodLabels: map[string]string{"service": "dlaas-lhelper"}, SameTraining: true},
+		},
+		Egress: []policies.Rule{
+			{Name: "dns", Ports: []policies.Port{{Protocol: "UDP", Port: 53}, {Protocol: "TCP", Port: 53}}},
+			{Name: "object-store", Ports: []policies.Port{{Port: 443}, {Port: 80}}},
+			{Name: "etcd", Ports: []policies.Port{{Port: 2379}}},
+		},
+	}
+}
+
+//mergeNetworkPolicyRules replaces the default rules with the configured rules of the same name and appends the others
+func mergeNetworkPolicyRules(defaults, configured []policies.Rule) []policies.Rule {
+	rules := append([]policies.Rule{}, defaults...)
+	for _, rule := range configured {
+		replaced := false
+		for i := range rules {
+			if rules[i].Name == rule.Name {
+				rules[i] = rule
+				replaced = true
+			}
+		}
+		if !replaced {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+//loadNetworkPolicyRules reads the rules from their configmap on every call
+func loadNetworkPolicyRules() networkPolicyRules {
+	rules := defaultNetworkPolicyRules()
+
+	var configured networkPolicyRules
+	if !configmaps.Load(networkPoliciesConfigPath, "network policies", &configured) {
+		return rules
+	}
+	rules.IsolateEgress = configured.IsolateEgress
+	rules.Ingress = mergeNetworkPolicyRules(rules.Ingress, configured.Ingress)
+	rules.Egress = mergeNetworkPolicyRules(rules.Egress, configured.Egress)
+	return rules
+}
+
+//networkPolicyForLearners isolates the learners of every training, single learners included, in the learner namespace.
+//The ports of the requested sidecars are open unless a configured rule of the same name replaces their rule.
+//Egress is only restricted if the configmap opts into isolate_egress.
+func networkPolicyForLearners(req *service.JobDeploymentRequest) *v1networking.NetworkPolicy {
+	rules := loadNetworkPolicyRules()
+	return policies.Policy{
+		Name:          req.Name,
+		Namespace:     config.GetLearnerNamespace(),
+		TrainingID:    req.TrainingId,
+		Ingress:       mergeNetworkPolicyRules(sidecarIngressRules(req), rules.Ingress),
+		Egress:        rules.Egress,
+		IsolateEgress: rules.IsolateEgress,
+	}.Build()
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	v1networking "k8s.io/api/networking/v1"
+)
+
+func TestDefaultNetworkPolicy(t *testing.T) {
+	req := &service.JobDeploymentRequest{Name: "training-1", TrainingId: "training-1", Resources: &service.ResourceRequirements{Learners: 1}}
+	policy := networkPolicyForLearners(req)
+	assert.Equal(t, "training-1", policy.Name)
+	assert.Equal(t, "training-1", policy.Labels["training_id"])
+	assert.Equal(t, map[string]string{"training_id": "training-1", "service": "dlaas-learner"}, policy.Spec.PodSelector.MatchLabels)
+
+	assert.Len(t, policy.Spec.Ingress, 2)
+	assert.Equal(t, map[string]string{"training_id": "training-1", "service": "dlaas-lhelper"}, policy.Spec.Ingress[1].From[0].PodSelector.MatchLabels)
+
+	//egress is open unless the configmap opts into isolating it
+	assert.Equal(t, []v1networking.PolicyType{v1networking.PolicyTypeIngress}, policy.Spec.PolicyTypes)
+	assert.Empty(t, policy.Spec.Egress)
+}
+
+func TestIsolatedEgressNetworkPolicy(t *testing.T) {
+	defer withConfigMap(t, &networkPoliciesConfigPath, `
+isolate_egress: true
+`)()
+	policy := networkPolicyForLearners(&service.JobDeploymentRequest{Name: "training-1", TrainingId: "training-1"})
+	assert.Contains(t, policy.Spec.PolicyTypes, v1networking.PolicyTypeEgress)
+	assert.Len(t, policy.Spec.Egress, 4)
+	dns := policy.Spec.Egress[1]
+	assert.Empty(t, dns.To)
+	assert.Len(t, dns.Ports, 2)
+	assert.Equal(t, v1core.ProtocolUDP, *dns.Ports[0].Protocol)
+	assert.Equal(t, 53, dns.Ports[0].Port.IntValue())
+	assert.Equal(t, 443, policy.Spec.Egress[2].Ports[0].Port.IntValue())
+	assert.Equal(t, 2379, policy.Spec.Egress[3].Ports[0].Port.IntValue())
+}
+
+func TestConfiguredNetworkPolicy(t *testing.T) {
+	defer withConfigMap(t, &networkPoliciesConfigPath, `
+isolate_egress: true
+ingress:
+- name: helper
+  disabled: true
+egress:
+- name: object-store
+  cidrs: [10.1.0.0/16, 161.26.0.0/16]
+  ports: [{port: 443}]
+- name: etcd
+  pod_labels: {service: etcd}
+- name: trainer
+  namespace_labels: {name: ffdl}
+`)()
+	policy := networkPolicyForLearners(&service.JobDeploymentRequest{Name: "training-1", TrainingId: "training-1"})
+	assert.Len(t, policy.Spec.Ingress, 1)
+	assert.Len(t, policy.Spec.Egress, 5)
+
+	store := policy.Spec.Egress[2]
+	assert.Len(t, store.To, 2)
+	assert.Equal(t, "161.26.0.0/16", store.To[1].IPBlock.CIDR)
+	assert.Equal(t, v1core.ProtocolTCP, *store.Ports[0].Protocol)
+	assert.Equal(t, 443, store.Ports[0].Port.IntValue())
+	assert.Equal(t, map[string]string{"service": "etcd"}, policy.Spec.Egress[3].To[0].PodSelector.MatchLabels)
+	assert.Equal(t, map[string]string{"name": "ffdl"}, policy.Spec.Egress[4].To[0].NamespaceSelector.MatchLabels)
+}
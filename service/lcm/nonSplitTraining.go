@@ -17,12 +17,28 @@
 package lcm
 
 import (
+	"fmt"
+
 	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-lcm/service/lcm/helper"
 	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	"github.com/AISphere/ffdl-trainer/client"
 )
 
 func (t nonSplitTraining) Start() error {
 
+	//every non split learner has its own controller and job directory, so the groups could not tell when the training is done
+	if len(t.req.ReplicaGroups) > 0 || usesMPILauncher(t.req) {
+		err := rejectDeployment(client.ErrInvalidResourceSpecs, fmt.Errorf("replica groups and mpi launchers need a shared job volume, training %s is not deployed in split mode", t.req.TrainingId))
+		t.logr.WithError(err).Errorf("Could not deploy the replica groups of %s", t.learner.name)
+		return err
+	}
+	if _, _, elastic := elasticBounds(t.req); elastic {
+		err := rejectDeployment(client.ErrInvalidResourceSpecs, fmt.Errorf("elastic trainings need a shared job volume, training %s is not deployed in split mode", t.req.TrainingId))
+		t.logr.WithError(err).Errorf("Could not deploy the elastic learners of %s", t.learner.name)
+		return err
+	}
+
 	gpus := make(map[string]string)
 	if t.req.Resources.Gpus > 0 {
 		gpus["ibm-cloud.kubernetes.io/gpu-type"] = t.req.Resources.GpuType
@@ -36,8 +52,11 @@ func (t nonSplitTraining) Start() error {
 
 	//now create the learner container
 	useLogCollector := useLogCollectors(t.k8sClient, t.logr)
-	learnerContainer := constructLearnerContainer(t.req, learnerDefn.envVars, learnerDefn.volumeMounts, helperDefn.sharedVolumeMount, learnerDefn.mountTrainingDataStoreInLearner, learnerDefn.mountResultsStoreInLearner, learnerDefn.mountSSHCertsInLearner, t.logr, useLogCollector)
-	helperContainers = append(helperContainers, learnerContainer)
+	learnerContainer := constructLearnerContainer(t.req, learnerDefn.envVars, learnerDefn.volumeMounts, learnerDefn.mountWaitCommand, helperDefn.sharedVolumeMount, learnerDefn.mountTrainingDataStoreInLearner, learnerDefn.mountResultsStoreInLearner, learnerDefn.mountSSHCertsInLearner, t.logr, useLogCollector)
+	helperContainers = append(append(helperContainers, learnerContainer), learnerDefn.sidecars...)
+	helperContainers = append(helperContainers, sidecarContainers(t.req, helperDefn.sharedVolumeMount, sidecarInLearner)...)
+	//the helper runs in the learner pods, its sidecars only in the first one
+	helperContainers = append(helperContainers, firstLearnerOnly(sidecarContainers(t.req, helperDefn.sharedVolumeMount, sidecarInHelper))...)
 
 	imagePullSecret, err := learner.GenerateImagePullSecret(t.k8sClient, t.req)
 	if err != nil {
@@ -62,14 +81,16 @@ func (t nonSplitTraining) Start() error {
 	if isCPUOnly(t.req.Resources.GpuType) {
 		gpus["gpu/nvidia"] = "NA"
 	}
-	nonSplitLearnerPodSpec := learner.CreatePodSpec(helperContainers, helperAndLearnerVolumes, labelsMap, gpus, imagePullSecret, nil, gpuTolerations, termGracePeriodSecs)
-	serviceSpec := learner.CreateServiceSpec(learnerDefn.name, t.req.TrainingId)
+	nonSplitLearnerPodSpec := learner.CreatePodSpec(helperContainers, helperAndLearnerVolumes, labelsMap, gpus, imagePullSecret, nil, learnerPlacement(t.req), gpuTolerations, termGracePeriodSecs)
+	applyPodSecurity(&nonSplitLearnerPodSpec.Spec, learnerPodRole, t.req.Name)
+	serviceSpec := learner.CreateServiceSpec(learnerDefn.name, t.req.TrainingId, learner.ServicePorts(distributedAdapterForLearners(t.req))...)
 	statefulSetSpec := learner.CreateStatefulSetSpecForLearner(learnerDefn.name, serviceSpec.Name, learnerDefn.numberOfLearners, nonSplitLearnerPodSpec)
 
 	numLearners := int(t.req.GetResources().Learners)
 
 	return t.CreateFromBOM(&nonSplitTrainingBOM{
 		learnerDefn.secrets,
+		learnerDefn.volumeClaims,
 		learnerDefn.networkingPolicy,
 		serviceSpec,
 		statefulSetSpec,
@@ -83,6 +104,10 @@ func (t nonSplitTraining) CreateFromBOM(bom *nonSplitTrainingBOM) error {
 	logr := t.logr
 	namespace := config.GetLearnerNamespace()
 
+	if err := t.images.pinPodSpec(&bom.learnerBOM.Spec.Template.Spec); err != nil {
+		return err
+	}
+
 	if bom.networkPolicy != nil {
 		logr.Infof("Applying network policy for training")
 		if _, err := t.k8sClient.NetworkingV1().NetworkPolicies(namespace).Create(bom.networkPolicy); err != nil {
@@ -99,6 +124,14 @@ func (t nonSplitTraining) CreateFromBOM(bom *nonSplitTrainingBOM) error {
 		}
 	}
 
+	for _, claim := range bom.volumeClaims {
+		//create the claims of mounted buckets
+		if err := helper.CreatePVCFromBOM(claim, t.k8sClient); err != nil {
+			logr.WithError(err).Errorf("Failed in creating volume claim %s while deploying for training ", claim.Name)
+			return err
+		}
+	}
+
 	if bom.numLearners > 1 {
 		//create service
 		if _, err := t.k8sClient.CoreV1().Services(namespace).Create(bom.service); err != nil {
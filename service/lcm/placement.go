@@ -0,0 +1,79 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	//node label the topology placement policy co-locates learners on when the job does not name one, e.g. a rack label
+	placementTopologyKey = "placement.topology_key"
+	//gpus of the largest learner nodes, defaults to maxGPUsPerNode
+	placementMaxGPUsPerNodeKey = "placement.max_gpus_per_node"
+)
+
+func maxGPUsPerLearnerNode() int {
+	if viper.IsSet(placementMaxGPUsPerNodeKey) {
+		return viper.GetInt(placementMaxGPUsPerNodeKey)
+	}
+	return maxGPUsPerNode
+}
+
+//learnerPlacement is the placement policy of the job from the schedpolicy and topology of its resources, an unknown
+//schedpolicy leaves the placement to the scheduler
+func learnerPlacement(req *service.JobDeploymentRequest) learner.Placement {
+	resources := req.GetResources()
+	topologyKey := resources.GetTopology()
+	if topologyKey == "" {
+		topologyKey = viper.GetString(placementTopologyKey)
+	}
+	policy, _ := learner.PlacementPolicy(resources.GetSchedpolicy())
+	return learner.Placement{
+		Policy:      policy,
+		TopologyKey: topologyKey,
+		TrainingID:  req.TrainingId,
+	}
+}
+
+//validatePlacement warns about unknown placement policies and rejects learners that need more gpus than a single node has
+func validatePlacement(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) error {
+	if _, known := learner.PlacementPolicy(req.GetResources().GetSchedpolicy()); !known {
+		logr.Warnf("ignoring unknown placement policy %s of training job %s, the scheduler places its learners", req.GetResources().GetSchedpolicy(), req.TrainingId)
+	}
+	placement := learnerPlacement(req)
+	if err := learner.ValidatePlacement(placement.Policy, placement.TopologyKey); err != nil {
+		return err
+	}
+
+	maxGPUs := maxGPUsPerLearnerNode()
+	if gpus := int(req.GetResources().GetGpus()); gpus > maxGPUs {
+		return fmt.Errorf("%d gpus per learner do not fit on a node with %d gpus, use more learners with fewer gpus each", gpus, maxGPUs)
+	}
+	for _, g := range req.ReplicaGroups {
+		if gpus := int(g.GetResources().GetGpus()); gpus > maxGPUs {
+			return fmt.Errorf("%d gpus per learner of replica group %s do not fit on a node with %d gpus", gpus, g.Name, maxGPUs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePlacement(t *testing.T) {
+	logr := logger.LocLogger(InitLogger("training-1", "user-1"))
+	req := &service.JobDeploymentRequest{
+		TrainingId: "training-1",
+		Resources:  &service.ResourceRequirements{Gpus: 2, Learners: 2, Schedpolicy: "pack"},
+	}
+	assert.NoError(t, validatePlacement(req, logr))
+
+	req.Resources.Gpus = maxGPUsPerNode + 1
+	assert.Error(t, validatePlacement(req, logr))
+
+	req.Resources.Gpus = 1
+	req.ReplicaGroups = []*service.ReplicaGroup{{Name: "ps", Replicas: 1, Resources: &service.ResourceRequirements{Gpus: maxGPUsPerNode + 1}}}
+	assert.Error(t, validatePlacement(req, logr))
+
+	req.ReplicaGroups = nil
+	req.Resources.Schedpolicy, req.Resources.Topology = "topology", "rack"
+	assert.NoError(t, validatePlacement(req, logr))
+	assert.Equal(t, "rack", learnerPlacement(req).TopologyKey)
+
+	req.Resources.Schedpolicy = "dense"
+	assert.NoError(t, validatePlacement(req, logr))
+	assert.Equal(t, learner.PlacementPack, learnerPlacement(req).Policy)
+
+	req.Resources.Schedpolicy = "scatter"
+	assert.NoError(t, validatePlacement(req, logr))
+	assert.Equal(t, "", learnerPlacement(req).Policy)
+}
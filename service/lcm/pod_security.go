@@ -0,0 +1,131 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"github.com/AISphere/ffdl-lcm/service/lcm/configmaps"
+	v1core "k8s.io/api/core/v1"
+)
+
+//roles of the pods of a training, each gets its own security context and service account
+const (
+	learnerPodRole    = "learner"
+	helperPodRole     = "helper"
+	jobMonitorPodRole = "jobmonitor"
+)
+
+//podSecurityConfigPath is mounted from the lcm-pod-security configmap, only the defaults apply if it is absent
+var podSecurityConfigPath = "/etc/pod-security/pod-security.yaml"
+
+//podSecurity ... security context of the pods of a role and of their containers
+type podSecurity struct {
+	RunAsUser    *int64 `yaml:"run_as_user,omitempty"`
+	RunAsNonRoot bool   `yaml:"run_as_non_root,omitempty"`
+	//FSGroup owns the volumes of the pods
+	FSGroup *int64 `yaml:"fs_group,omitempty"`
+	//DropCapabilities are dropped in every container, next to the capabilities a container drops itself
+	DropCapabilities         []string `yaml:"drop_capabilities,omitempty"`
+	AllowPrivilegeEscalation *bool    `yaml:"allow_privilege_escalation,omitempty"`
+	ReadOnlyRootFilesystem   bool     `yaml:"read_only_root_filesystem,omitempty"`
+}
+
+//the learner container drops the capabilities sshd does not need itself, helpers and the job monitor do not escalate
+//and the job monitor does not need any capability
+func defaultPodSecurity() map[string]podSecurity {
+	noEscalation := false
+	return map[string]podSecurity{
+		learnerPodRole:    {},
+		helperPodRole:     {AllowPrivilegeEscalation: &noEscalation},
+		jobMonitorPodRole: {AllowPrivilegeEscalation: &noEscalation, DropCapabilities: []string{"ALL"}},
+	}
+}
+
+//loadPodSecurity reads the security of the pod roles from their configmap on every call, a role in the configmap replaces its defaults
+func loadPodSecurity() map[string]podSecurity {
+	security := defaultPodSecurity()
+
+	var config map[string]podSecurity
+	if !configmaps.Load(podSecurityConfigPath, "pod security", &config) {
+		return security
+	}
+	for role, s := range config {
+		security[role] = s
+	}
+	return security
+}
+
+func (p podSecurity) podSecurityContext() *v1core.PodSecurityContext {
+	if p.FSGroup == nil {
+		return nil
+	}
+	return &v1core.PodSecurityContext{FSGroup: p.FSGroup}
+}
+
+//applyTo restricts the security context of a container, privileged containers like the fuse mount sidecar are left alone
+func (p podSecurity) applyTo(container *v1core.Container) {
+	if container.SecurityContext == nil {
+		container.SecurityContext = &v1core.SecurityContext{}
+	}
+	sc := container.SecurityContext
+	if sc.Privileged != nil && *sc.Privileged {
+		return
+	}
+	if p.RunAsUser != nil {
+		sc.RunAsUser = p.RunAsUser
+	}
+	if p.RunAsNonRoot {
+		sc.RunAsNonRoot = &p.RunAsNonRoot
+	}
+	if p.AllowPrivilegeEscalation != nil {
+		sc.AllowPrivilegeEscalation = p.AllowPrivilegeEscalation
+	}
+	if p.ReadOnlyRootFilesystem {
+		sc.ReadOnlyRootFilesystem = &p.ReadOnlyRootFilesystem
+	}
+	if len(p.DropCapabilities) > 0 {
+		if sc.Capabilities == nil {
+			sc.Capabilities = &v1core.Capabilities{}
+		}
+		for _, capability := range p.DropCapabilities {
+			if !containsCapability(sc.Capabilities.Drop, capability) {
+				sc.Capabilities.Drop = append(sc.Capabilities.Drop, v1core.Capability(capability))
+			}
+		}
+	}
+}
+
+func containsCapability(capabilities []v1core.Capability, capability string) bool {
+	for _, c := range capabilities {
+		if string(c) == capability {
+			return true
+		}
+	}
+	return false
+}
+
+//applyPodSecurity sets the security context of the role on a pod of the job and its containers,
+//and runs the pod with the service account of the role if jobs get their own service accounts
+func applyPodSecurity(spec *v1core.PodSpec, role string, jobName string) {
+	security := loadPodSecurity()[role]
+	spec.SecurityContext = security.podSecurityContext()
+	for i := range spec.Containers {
+		security.applyTo(&spec.Containers[i])
+	}
+	if jobServiceAccountsEnabled() {
+		spec.ServiceAccountName = jobServiceAccountName(role, jobName)
+	}
+}
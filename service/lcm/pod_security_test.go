@@ -0,0 +1,82 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+)
+
+func TestDefaultPodSecurity(t *testing.T) {
+	privileged := true
+	spec := v1core.PodSpec{Containers: []v1core.Container{
+		{Name: jobMonitorPodRole},
+		{Name: "mount-0", SecurityContext: &v1core.SecurityContext{Privileged: &privileged}},
+	}}
+	applyPodSecurity(&spec, jobMonitorPodRole, "training-1")
+	assert.Nil(t, spec.SecurityContext)
+	assert.Empty(t, spec.ServiceAccountName)
+	sc := spec.Containers[0].SecurityContext
+	assert.False(t, *sc.AllowPrivilegeEscalation)
+	assert.Equal(t, []v1core.Capability{"ALL"}, sc.Capabilities.Drop)
+	assert.Nil(t, spec.Containers[1].SecurityContext.AllowPrivilegeEscalation)
+}
+
+func TestConfiguredPodSecurity(t *testing.T) {
+	defer withConfigMap(t, &podSecurityConfigPath, `
+learner:
+  run_as_user: 1000
+  run_as_non_root: true
+  fs_group: 2000
+  drop_capabilities: [NET_RAW, SYS_ADMIN]
+`)()
+	viper.Set(jobServiceAccountsKey, true)
+	defer viper.Set(jobServiceAccountsKey, false)
+
+	spec := v1core.PodSpec{Containers: []v1core.Container{
+		{Name: "learner", SecurityContext: &v1core.SecurityContext{Capabilities: &v1core.Capabilities{Drop: []v1core.Capability{"NET_RAW"}}}},
+	}}
+	applyPodSecurity(&spec, learnerPodRole, "training-1")
+	assert.Equal(t, int64(2000), *spec.SecurityContext.FSGroup)
+	assert.Equal(t, "learner-sa-training-1", spec.ServiceAccountName)
+	sc := spec.Containers[0].SecurityContext
+	assert.Equal(t, int64(1000), *sc.RunAsUser)
+	assert.True(t, *sc.RunAsNonRoot)
+	assert.Equal(t, []v1core.Capability{"NET_RAW", "SYS_ADMIN"}, sc.Capabilities.Drop)
+
+	//roles missing in the configmap keep their defaults
+	assert.False(t, *loadPodSecurity()[helperPodRole].AllowPrivilegeEscalation)
+}
+
+func TestJobServiceAccounts(t *testing.T) {
+	accounts, roles, bindings := jobServiceAccounts(&service.JobDeploymentRequest{Name: "training-1", TrainingId: "training-1", UserId: "user"})
+	assert.Len(t, accounts, 3)
+	for _, account := range accounts {
+		assert.Equal(t, "training-1", account.Labels["training_id"])
+		assert.Equal(t, account.Name == "jobmonitor-sa-training-1", *account.AutomountServiceAccountToken)
+	}
+	assert.Len(t, roles, 1)
+	assert.Equal(t, "jobmonitor-sa-training-1", roles[0].Name)
+	assert.Equal(t, []string{"pods"}, roles[0].Rules[0].Resources)
+	assert.Len(t, bindings, 1)
+	assert.Equal(t, roles[0].Name, bindings[0].RoleRef.Name)
+	assert.Equal(t, "jobmonitor-sa-training-1", bindings[0].Subjects[0].Name)
+}
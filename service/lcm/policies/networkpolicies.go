@@ -17,65 +17,118 @@
 package policies
 
 import (
+	v1core "k8s.io/api/core/v1"
 	k8sv1networking "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-//DefineNetworkPoliciesForTrainingID ... network policies to only allow ingress egress from learner pods with same training id
-func DefineNetworkPoliciesForTrainingID(name, trainingID string) *k8sv1networking.NetworkPolicy {
-	networkPoliciesSpec := k8sv1networking.NetworkPolicy{
+//Port ... of a rule, TCP if the protocol is empty
+type Port struct {
+	Protocol string `yaml:"protocol,omitempty"`
+	Port     int    `yaml:"port"`
+}
+
+//Rule ... a named ingress or egress rule added to the learner to learner traffic. The peers of a rule are the CIDRs,
+//the pods with PodLabels in the namespace of the policy and the namespaces with NamespaceLabels, any peer if there are none.
+type Rule struct {
+	Name string `yaml:"name"`
+	//Disabled drops a default rule of the same name
+	Disabled bool `yaml:"disabled,omitempty"`
+	//CIDRs allow-lists ip blocks, e.g. of the object stores outside the cluster
+	CIDRs           []string          `yaml:"cidrs,omitempty"`
+	PodLabels       map[string]string `yaml:"pod_labels,omitempty"`
+	NamespaceLabels map[string]string `yaml:"namespace_labels,omitempty"`
+	//SameTraining restricts the pods of PodLabels to the pods of the training
+	SameTraining bool `yaml:"same_training,omitempty"`
+	//Ports of the rule, all ports if empty
+	Ports []Port `yaml:"ports,omitempty"`
+}
+
+//Policy ... the network policy of the learners of a training, learners with the same training id can always reach each other.
+//The egress rules only apply if IsolateEgress is set, egress is not restricted otherwise
+type Policy struct {
+	Name, Namespace, TrainingID string
+	Ingress, Egress             []Rule
+	IsolateEgress               bool
+}
+
+func (p Policy) learnerLabels() map[string]string {
+	return map[string]string{
+		"training_id": p.TrainingID,
+		"service":     "dlaas-learner",
+	}
+}
+
+func (p Policy) peers(rule Rule) []k8sv1networking.NetworkPolicyPeer {
+	var peers []k8sv1networking.NetworkPolicyPeer
+	for _, cidr := range rule.CIDRs {
+		peers = append(peers, k8sv1networking.NetworkPolicyPeer{IPBlock: &k8sv1networking.IPBlock{CIDR: cidr}})
+	}
+	if len(rule.PodLabels) > 0 {
+		labels := make(map[string]string)
+		for k, v := range rule.PodLabels {
+			labels[k] = v
+		}
+		if rule.SameTraining {
+			labels["training_id"] = p.TrainingID
+		}
+		peers = append(peers, k8sv1networking.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: labels}})
+	}
+	if len(rule.NamespaceLabels) > 0 {
+		peers = append(peers, k8sv1networking.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{MatchLabels: rule.NamespaceLabels}})
+	}
+	return peers
+}
+
+func ports(rule Rule) []k8sv1networking.NetworkPolicyPort {
+	var policyPorts []k8sv1networking.NetworkPolicyPort
+	for _, port := range rule.Ports {
+		protocol := v1core.ProtocolTCP
+		if port.Protocol != "" {
+			protocol = v1core.Protocol(port.Protocol)
+		}
+		number := intstr.FromInt(port.Port)
+		policyPorts = append(policyPorts, k8sv1networking.NetworkPolicyPort{Protocol: &protocol, Port: &number})
+	}
+	return policyPorts
+}
+
+//Build ... the network policy with the learner to learner rules followed by the enabled rules of the policy
+func (p Policy) Build() *k8sv1networking.NetworkPolicy {
+	learners := []k8sv1networking.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: p.learnerLabels()}}}
+	ingress := []k8sv1networking.NetworkPolicyIngressRule{{From: learners}}
+	for _, rule := range p.Ingress {
+		if !rule.Disabled {
+			ingress = append(ingress, k8sv1networking.NetworkPolicyIngressRule{From: p.peers(rule), Ports: ports(rule)})
+		}
+	}
+	policyTypes := []k8sv1networking.PolicyType{k8sv1networking.PolicyTypeIngress}
+	var egress []k8sv1networking.NetworkPolicyEgressRule
+	if p.IsolateEgress {
+		policyTypes = append(policyTypes, k8sv1networking.PolicyTypeEgress)
+		egress = append(egress, k8sv1networking.NetworkPolicyEgressRule{To: learners})
+		for _, rule := range p.Egress {
+			if !rule.Disabled {
+				egress = append(egress, k8sv1networking.NetworkPolicyEgressRule{To: p.peers(rule), Ports: ports(rule)})
+			}
+		}
+	}
+
+	return &k8sv1networking.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:      p.Name,
+			Namespace: p.Namespace,
 			Labels: map[string]string{
-				"training_id": trainingID,
+				"training_id": p.TrainingID,
 			},
 		},
 		Spec: k8sv1networking.NetworkPolicySpec{
-			//This policy applies to pods with the following label
-			PodSelector: metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"training_id": trainingID,
-					"service":     "dlaas-learner",
-				},
-			},
-			//We're adding ingress and egress polcies
-			PolicyTypes: []k8sv1networking.PolicyType{
-				k8sv1networking.PolicyTypeIngress,
-				k8sv1networking.PolicyTypeEgress,
-			},
-			//Allow ingress only from peers (pods) that have the following label
-			Ingress: []k8sv1networking.NetworkPolicyIngressRule{
-				k8sv1networking.NetworkPolicyIngressRule{
-					From: []k8sv1networking.NetworkPolicyPeer{
-						k8sv1networking.NetworkPolicyPeer{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									"training_id": trainingID,
-									"service":     "dlaas-learner",
-								},
-							},
-						},
-					},
-				},
-			},
-			//Allow egress only to peers (pods) that have the following label
-			Egress: []k8sv1networking.NetworkPolicyEgressRule{
-				k8sv1networking.NetworkPolicyEgressRule{
-					To: []k8sv1networking.NetworkPolicyPeer{
-						k8sv1networking.NetworkPolicyPeer{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									"training_id": trainingID,
-									"service":     "dlaas-learner",
-								},
-							},
-						},
-					},
-				},
-			},
+			//This policy applies to the learners of the training
+			PodSelector: metav1.LabelSelector{MatchLabels: p.learnerLabels()},
+			PolicyTypes: policyTypes,
+			Ingress:     ingress,
+			Egress:      egress,
 		},
 	}
-
-	return &networkPoliciesSpec
-
 }
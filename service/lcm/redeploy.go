@@ -0,0 +1,263 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/trainingjob"
+	"github.com/AISphere/ffdl-trainer/client"
+	"github.com/AISphere/ffdl-trainer/trainer/grpc_trainer_v2"
+
+	"github.com/cenkalti/backoff"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	//how often a training that failed with an infrastructure error is redeployed, 0 disables redeploys
+	redeployMaxAttemptsKey = "redeploy.max_attempts"
+	//comma separated error codes a training is redeployed on, defaults to defaultRedeployErrorCodes
+	redeployErrorCodesKey = "redeploy.error_codes"
+
+	//deployment requests are kept outside the etcd prefix of the training, which is reset on every redeploy
+	zkRedeploy = "redeploy"
+
+	//how long a redeploy waits for the pods of the failed attempt to go away
+	redeployTeardownTimeout = 5 * time.Minute
+)
+
+//server side errors which are not the fault of the user, failed pods are mostly lost or evicted nodes
+var defaultRedeployErrorCodes = []string{client.ErrCodeFailedDeploy, client.ErrFailedPodReasonUnknown,
+	client.ErrCodeK8SConnection, client.ErrCodeEtcdConnection}
+
+//redeployRecord ... the deployment request of a training as persisted at its first deploy, with the redeploys so far
+type redeployRecord struct {
+	Request  *service.JobDeploymentRequest `json:"request"`
+	Attempts int                           `json:"attempts"`
+}
+
+func redeployPath(trainingID string) string {
+	return zkRedeploy + "/" + trainingID
+}
+
+func redeployMaxAttempts() int {
+	return viper.GetInt(redeployMaxAttemptsKey)
+}
+
+//isRedeployable is true if the retry policy redeploys trainings that failed with errorCode
+func isRedeployable(errorCode string) bool {
+	codes := defaultRedeployErrorCodes
+	if configured := viper.GetString(redeployErrorCodesKey); configured != "" {
+		codes = strings.Split(configured, ",")
+	}
+	for _, code := range codes {
+		if strings.TrimSpace(code) == errorCode {
+			return true
+		}
+	}
+	return false
+}
+
+//the status message of a redeployed training, the trainer shows it while the training is pending again
+func redeployMessage(attempt int, errorCode string, reason string) string {
+	return fmt.Sprintf("redeploy attempt %d of %d after error %s: %s", attempt, redeployMaxAttempts(), errorCode, reason)
+}
+
+//persistDeploymentRequest keeps the request of a training for redeploys, nothing is kept if redeploys are disabled
+func (s *lcmService) persistDeploymentRequest(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) {
+	if redeployMaxAttempts() <= 0 {
+		return
+	}
+	record, err := json.Marshal(&redeployRecord{Request: req})
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to serialize the deployment request of training job %s, it will not be redeployed", req.TrainingId)
+		return
+	}
+	if _, err := s.etcdClient.Put(redeployPath(req.TrainingId), string(record), logr); err != nil {
+		logr.WithError(err).Errorf("Failed to persist the deployment request of training job %s, it will not be redeployed", req.TrainingId)
+	}
+}
+
+//forgetDeploymentRequest drops the persisted request and its credentials once a training is killed or failed for good
+func (s *lcmService) forgetDeploymentRequest(trainingID string, logr *logger.LocLoggingEntry) {
+	s.deleteRequestCredentials(trainingID, logr)
+	if _, err := s.etcdClient.DeleteKeyIfExists(redeployPath(trainingID), logr); err != nil {
+		logr.WithError(err).Errorf("Failed to delete the persisted deployment request of training job %s", trainingID)
+	}
+}
+
+//nextRedeployAttempt counts another redeploy of a training, false if the policy does not allow one or another
+//redeploy of the same failure is already on its way
+func (s *lcmService) nextRedeployAttempt(trainingID string, errorCode string, logr *logger.LocLoggingEntry) (*redeployRecord, bool) {
+	if redeployMaxAttempts() <= 0 || !isRedeployable(errorCode) {
+		return nil, false
+	}
+	path := redeployPath(trainingID)
+	response, err := s.etcdClient.Get(path, logr)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to read the persisted deployment request of training job %s", trainingID)
+		return nil, false
+	}
+	if len(response) == 0 {
+		logr.Warnf("No deployment request was persisted for training job %s, it can not be redeployed", trainingID)
+		return nil, false
+	}
+
+	record := &redeployRecord{}
+	if err := json.Unmarshal([]byte(response[0].Value), record); err != nil || record.Request == nil {
+		logr.WithError(err).Errorf("Failed to read the persisted deployment request of training job %s", trainingID)
+		return nil, false
+	}
+	if record.Attempts >= redeployMaxAttempts() {
+		logr.Infof("Training job %s was already redeployed %d times, not redeploying it again", trainingID, record.Attempts)
+		return nil, false
+	}
+
+	record.Attempts++
+	updated, err := json.Marshal(record)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to serialize the deployment request of training job %s", trainingID)
+		return nil, false
+	}
+	swapped, err := s.etcdClient.CompareAndSwap(path, string(updated), response[0].Value, logr)
+	if err != nil || !swapped {
+		logr.WithError(err).Warnf("Could not count redeploy attempt %d of training job %s, it is probably being redeployed already", record.Attempts, trainingID)
+		return nil, false
+	}
+	return record, true
+}
+
+//RedeployTrainingJob redeploys a training job that failed with an infrastructure error, the job monitor asks for it
+//before failing the job. The job has to be failed as before if the response says it was not redeployed
+func (s *lcmService) RedeployTrainingJob(ctx context.Context, req *service.JobRedeployRequest) (*service.JobRedeployResponse, error) {
+	logr := logger.LocLogger(InitLogger(req.TrainingId, req.UserId))
+	logr.Infof("Redeploy requested for training job %s after error %s: %s", req.TrainingId, req.ErrorCode, req.Reason)
+
+	record, ok := s.nextRedeployAttempt(req.TrainingId, req.ErrorCode, logr)
+	if !ok {
+		return &service.JobRedeployResponse{}, nil
+	}
+
+	//the job monitor which asked for the redeploy is torn down with the rest of the training
+	go s.redeploy(record, redeployMessage(record.Attempts, req.ErrorCode, req.Reason), req.ErrorCode, logr)
+	return &service.JobRedeployResponse{Redeployed: true, Attempt: int32(record.Attempts)}, nil
+}
+
+//redeployAfterFailure redeploys a training LCM failed to deploy, false if the caller has to fail the training
+func (s *lcmService) redeployAfterFailure(trainingID string, errorCode string, reason string, logr *logger.LocLoggingEntry) bool {
+	record, ok := s.nextRedeployAttempt(trainingID, errorCode, logr)
+	if !ok {
+		return false
+	}
+	s.redeploy(record, redeployMessage(record.Attempts, errorCode, reason), errorCode, logr)
+	return true
+}
+
+//redeploy tears a training down, resets its etcd prefix and deploys the persisted request again
+func (s *lcmService) redeploy(record *redeployRecord, message string, errorCode string, logr *logger.LocLoggingEntry) {
+	req := record.Request
+	logr.Infof("Redeploying training job %s: %s", req.TrainingId, message)
+
+	if err := updateJobStatus(req.TrainingId, grpc_trainer_v2.Status_PENDING, req.UserId, message, errorCode, logr); err != nil {
+		logr.WithError(err).Errorf("Failed to record redeploy attempt %d of training job %s", record.Attempts, req.TrainingId)
+	}
+
+	if err := s.killDeployedJob(req.Name, req.TrainingId, req.UserId); err != nil {
+		logr.WithError(err).Errorf("Failed to tear down training job %s before redeploying it", req.TrainingId)
+	}
+	s.waitForTeardown(req.TrainingId, logr)
+
+	//pods of the failed attempt may have written to etcd until they were gone
+	if err := s.etcdClient.DeleteKeyWithOpts(req.TrainingId, logr, clientv3.WithPrefix()); err != nil {
+		logr.WithError(err).Errorf("Failed to reset the etcd nodes of training job %s before redeploying it", req.TrainingId)
+	}
+
+	if s.trainingJobs != nil {
+		s.resetTrainingJob(req, message, errorCode, logr)
+		return
+	}
+	req, err := s.restoreRequestCredentials(req, logr)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to read the credentials of training job %s, it can not be redeployed", req.TrainingId)
+		errorCode := client.ErrCodeK8SConnection
+		if _, rejected := err.(*deploymentRejectedError); rejected {
+			errorCode = deploymentErrorCode(err)
+		}
+		handleDeploymentFailure(s, req.Name, req.TrainingId, req.UserId, "request credentials", errorCode, logr)
+		return
+	}
+	s.deployDistributedTrainingJob(context.Background(), req, logr)
+}
+
+//waits until the pods of a torn down training are gone, the redeployed objects reuse their names
+func (s *lcmService) waitForTeardown(trainingID string, logr *logger.LocLoggingEntry) {
+	waitForPods := backoff.NewExponentialBackOff()
+	waitForPods.MaxElapsedTime = redeployTeardownTimeout
+	waitForPods.MaxInterval = 30 * time.Second
+
+	err := backoff.Retry(func() error {
+		pods, err := s.k8sClient.CoreV1().Pods(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: "training_id==" + trainingID})
+		if err != nil {
+			return err
+		}
+		if len(pods.Items) > 0 {
+			return fmt.Errorf("%d pods of training job %s are still terminating", len(pods.Items), trainingID)
+		}
+		return nil
+	}, waitForPods)
+	if err != nil {
+		logr.WithError(err).Warnf("Pods of training job %s are still around, redeploying it anyway", trainingID)
+	}
+}
+
+//sets the TrainingJob resource of a redeployed training back to pending, the controller deploys it again
+func (s *lcmService) resetTrainingJob(req *service.JobDeploymentRequest, message string, errorCode string, logr *logger.LocLoggingEntry) {
+	err := backoff.RetryNotify(func() error {
+		job, err := s.trainingJobs.Get(req.TrainingId)
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		job.Status = trainingjob.TrainingJobStatus{
+			Phase:         trainingjob.PhasePending,
+			LearnersTotal: int32(learnerPods(req)),
+			ErrorCode:     errorCode,
+			Message:       message,
+		}
+		_, err = s.trainingJobs.UpdateStatus(job)
+		return err
+	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed to reset TrainingJob %s for its redeploy", req.TrainingId)
+		k8sFailureCounter.With(component, "trainingjob").Add(1)
+	})
+	if err != nil {
+		logr.WithError(err).Errorf("Gave up redeploying training job %s", req.TrainingId)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-trainer/client"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRedeployable(t *testing.T) {
+	defer viper.Set(redeployErrorCodesKey, "")
+
+	assert.True(t, isRedeployable(client.ErrCodeK8SConnection))
+	assert.True(t, isRedeployable(client.ErrCodeEtcdConnection))
+	assert.True(t, isRedeployable(client.ErrCodeFailedDeploy))
+	assert.False(t, isRedeployable(client.ErrCodeInsufficientResources))
+	assert.False(t, isRedeployable(client.ErrLearnerProcessCrash))
+
+	viper.Set(redeployErrorCodesKey, "S200, S103")
+	assert.True(t, isRedeployable(client.ErrCodeImagePull))
+	assert.True(t, isRedeployable(client.ErrCodeK8SConnection))
+	assert.False(t, isRedeployable(client.ErrCodeFailedDeploy))
+}
+
+func TestRedeployMessage(t *testing.T) {
+	defer viper.Set(redeployMaxAttemptsKey, 0)
+	viper.Set(redeployMaxAttemptsKey, 3)
+	assert.Equal(t, "redeploy attempt 2 of 3 after error S201: etcd unreachable", redeployMessage(2, client.ErrCodeEtcdConnection, "etcd unreachable"))
+}
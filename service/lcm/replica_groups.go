@@ -0,0 +1,247 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/frameworks"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+
+	v1core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+//the role that ends the training when no completion roles are requested and the job has one
+const chiefReplicaGroup = "chief"
+
+//env var of the group request telling constructLearnerContainer and the distributed adapter which group is deployed
+const replicaGroupEnvVar = "REPLICA_GROUP"
+
+//env var with the number of learners in the groups before the group of the learner, the ordinals of every group start at 0
+//so the learner-<id> folders of the results use it to keep the ids of all learners of the training apart
+const learnerIDOffsetEnvVar = "LEARNER_ID_OFFSET"
+
+//validateReplicaGroups rejects groups that cannot be turned into statefulsets
+func validateReplicaGroups(req *service.JobDeploymentRequest) error {
+	if len(req.ReplicaGroups) > 0 && usesMPILauncher(req) {
+		return fmt.Errorf("replica groups are not supported for framework %s, its learners are started by an mpi launcher", req.Framework)
+	}
+	seen := make(map[string]bool)
+	for _, g := range req.ReplicaGroups {
+		if errs := validation.IsDNS1123Label(g.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid replica group name %q: %s", g.Name, strings.Join(errs, ", "))
+		}
+		if seen[g.Name] {
+			return fmt.Errorf("replica group %s is defined more than once", g.Name)
+		}
+		seen[g.Name] = true
+		if g.Replicas < 1 {
+			return fmt.Errorf("replica group %s needs at least one replica", g.Name)
+		}
+	}
+	for _, role := range req.CompletionRoles {
+		if !seen[role] {
+			return fmt.Errorf("completion role %s is not a replica group of the training", role)
+		}
+	}
+	return nil
+}
+
+//validateDistribution rejects more than one learner for frameworks the registry has no launcher for, their learners
+//would all train on their own
+func validateDistribution(req *service.JobDeploymentRequest) error {
+	if fw, _ := frameworks.Lookup(req.Framework, req.Version); !fw.SupportsDistribution() && totalLearners(req) > 1 {
+		return fmt.Errorf("framework %s %s does not support distributed training, it can only be deployed with one learner", req.Framework, req.Version)
+	}
+	return nil
+}
+
+//totalLearners is the number of learner pods of a training across all of its replica groups
+func totalLearners(req *service.JobDeploymentRequest) int {
+	numLearners := 0
+	if len(req.ReplicaGroups) > 0 {
+		for _, g := range req.ReplicaGroups {
+			numLearners += int(g.Replicas)
+		}
+	} else {
+		numLearners = int(req.GetResources().Learners)
+	}
+	if numLearners < 1 {
+		numLearners = 1
+	}
+	return numLearners
+}
+
+//completionRoles end the training when they exit, defaults to the chief or the first group
+func completionRoles(req *service.JobDeploymentRequest) []string {
+	if usesMPILauncher(req) {
+		return []string{mpiLauncherRole}
+	}
+	if len(req.ReplicaGroups) == 0 {
+		return nil
+	}
+	if len(req.CompletionRoles) > 0 {
+		return req.CompletionRoles
+	}
+	for _, g := range req.ReplicaGroups {
+		if g.Name == chiefReplicaGroup {
+			return []string{chiefReplicaGroup}
+		}
+	}
+	return []string{req.ReplicaGroups[0].Name}
+}
+
+//statefulset and headless service of a replica group
+func replicaGroupLearnerName(jobName, group string) string {
+	return fmt.Sprintf("learner-%s-%s", group, jobName)
+}
+
+func replicaGroupsOfCluster(req *service.JobDeploymentRequest) []learner.Group {
+	var groups []learner.Group
+	for _, g := range req.ReplicaGroups {
+		groups = append(groups, learner.Group{Name: g.Name, StatefulSetName: replicaGroupLearnerName(req.Name, g.Name), Replicas: int(g.Replicas)})
+	}
+	return groups
+}
+
+//replicaGroupRequest is the deployment request of a single group, with the resources and command of the group
+func replicaGroupRequest(req *service.JobDeploymentRequest, group *service.ReplicaGroup) *service.JobDeploymentRequest {
+	groupReq := *req
+
+	resources := *req.GetResources()
+	if group.Resources != nil {
+		resources = *group.Resources
+	}
+	resources.Learners = group.Replicas
+	groupReq.Resources = &resources
+
+	groupReq.EnvVars = make(map[string]string, len(req.EnvVars)+2)
+	for k, v := range req.EnvVars {
+		groupReq.EnvVars[k] = v
+	}
+	groupReq.EnvVars[replicaGroupEnvVar] = group.Name
+	if group.Command != "" {
+		groupReq.EnvVars["TRAINING_COMMAND"] = group.Command
+	}
+	return &groupReq
+}
+
+//replicaGroupEnvVars replaces the job wide learner env vars that differ between groups
+func replicaGroupEnvVars(envVars []v1core.EnvVar, groupReq *service.JobDeploymentRequest, learnerName string) []v1core.EnvVar {
+	overrides := map[string]string{
+		"LEARNER_NAME_PREFIX": learnerName,
+		"REPLICA_GROUP_SIZE":  strconv.Itoa(int(groupReq.GetResources().Learners)),
+		"TRAINING_COMMAND":    groupReq.EnvVars["TRAINING_COMMAND"],
+		learnerIDOffsetEnvVar: strconv.Itoa(learnerIDOffset(groupReq)),
+	}
+	var groupEnvVars []v1core.EnvVar
+	for _, ev := range envVars {
+		if _, overridden := overrides[ev.Name]; overridden || ev.Name == replicaGroupEnvVar {
+			continue
+		}
+		groupEnvVars = append(groupEnvVars, ev)
+	}
+	for _, name := range []string{"LEARNER_NAME_PREFIX", "REPLICA_GROUP_SIZE", "TRAINING_COMMAND", learnerIDOffsetEnvVar} {
+		if overrides[name] != "" {
+			groupEnvVars = append(groupEnvVars, v1core.EnvVar{Name: name, Value: overrides[name]})
+		}
+	}
+	return append(groupEnvVars, v1core.EnvVar{Name: replicaGroupEnvVar, Value: groupReq.EnvVars[replicaGroupEnvVar]})
+}
+
+//learnerIDOffset is the number of learners in the groups before the group of the request, in the order of
+//replicaGroupsEnvValue, so the launcher of an mpi training is learner-1 and its workers follow it
+func learnerIDOffset(groupReq *service.JobDeploymentRequest) int {
+	group := groupReq.EnvVars[replicaGroupEnvVar]
+	if usesMPILauncher(groupReq) {
+		if group == mpiWorkerRole {
+			return 1
+		}
+		return 0
+	}
+	offset := 0
+	for _, g := range groupReq.ReplicaGroups {
+		if g.Name == group {
+			break
+		}
+		offset += int(g.Replicas)
+	}
+	return offset
+}
+
+//learnerExitName is the control file the learner writes its exit code to, the controller only watches the one of the learner
+//so learners outside of the completion roles use their own and do not end the training
+func learnerExitName(req *service.JobDeploymentRequest) string {
+	group := req.EnvVars[replicaGroupEnvVar]
+	if group == "" || contains(completionRoles(req), group) {
+		return learnerContainerName
+	}
+	return fmt.Sprintf("%s-%s", learnerContainerName, group)
+}
+
+//replicaGroupsEnvValue is how the groups are handed to the job monitor, e.g. chief:1,worker:3,ps:2
+func replicaGroupsEnvValue(req *service.JobDeploymentRequest) string {
+	if usesMPILauncher(req) {
+		return fmt.Sprintf("%s:1,%s:%d", mpiLauncherRole, mpiWorkerRole, totalLearners(req))
+	}
+	var groups []string
+	for _, g := range req.ReplicaGroups {
+		groups = append(groups, fmt.Sprintf("%s:%d", g.Name, g.Replicas))
+	}
+	return strings.Join(groups, ",")
+}
+
+//deploys the replica groups of a split training, one statefulset and headless service per group
+func (t splitTraining) startReplicaGroups(logr *logger.LocLoggingEntry) error {
+	bom := &splitTrainingBOM{
+		secrets:              t.learner.secrets,
+		networkPolicy:        t.learner.networkingPolicy,
+		sharedVolumeClaimBOM: t.helper.sharedVolumeClaim,
+		volumeClaims:         t.learner.volumeClaims,
+		helperBOM:            t.deploymentSpecForHelper(),
+		numLearners:          totalLearners(t.req),
+	}
+
+	for _, group := range t.req.ReplicaGroups {
+		groupReq := replicaGroupRequest(t.req, group)
+		learnerName := replicaGroupLearnerName(t.req.Name, group.Name)
+
+		learnerDefn := t.learner
+		learnerDefn.name = learnerName
+		learnerDefn.numberOfLearners = int(group.Replicas)
+		learnerDefn.envVars = replicaGroupEnvVars(t.learner.envVars, groupReq, learnerName)
+
+		serviceSpec := learner.CreateServiceSpec(learnerName, t.req.TrainingId, learner.ServicePorts(distributedAdapterForLearners(groupReq))...)
+		serviceSpec.Spec.Selector["replica_group"] = group.Name
+		statefulSpec, err := t.statefulSetSpecForLearner(groupReq, learnerDefn, serviceSpec.Name)
+		if err != nil {
+			logr.WithError(err).Errorf("Could not create statefulspec for replica group %s", group.Name)
+			return err
+		}
+
+		bom.services = append(bom.services, serviceSpec)
+		bom.learnerBOMs = append(bom.learnerBOMs, statefulSpec)
+	}
+
+	logr.Infof("deploying replica groups %s, completion roles %v", replicaGroupsEnvValue(t.req), completionRoles(t.req))
+	return t.CreateFromBOM(bom)
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func replicaGroupsRequest() *service.JobDeploymentRequest {
+	return &service.JobDeploymentRequest{
+		Name:      "training-job1",
+		Resources: &service.ResourceRequirements{Cpus: 2, Gpus: 1, Learners: 1},
+		EnvVars:   map[string]string{"TRAINING_COMMAND": "python train.py"},
+		ReplicaGroups: []*service.ReplicaGroup{
+			{Name: "chief", Replicas: 1},
+			{Name: "worker", Replicas: 3, Command: "python worker.py"},
+			{Name: "ps", Replicas: 2, Resources: &service.ResourceRequirements{Cpus: 4}},
+		},
+	}
+}
+
+func TestReplicaGroupsValidation(t *testing.T) {
+	req := replicaGroupsRequest()
+	assert.NoError(t, validateReplicaGroups(req))
+
+	req.CompletionRoles = []string{"evaluator"}
+	assert.Error(t, validateReplicaGroups(req))
+
+	req = replicaGroupsRequest()
+	req.ReplicaGroups = append(req.ReplicaGroups, &service.ReplicaGroup{Name: "worker", Replicas: 1})
+	assert.Error(t, validateReplicaGroups(req))
+
+	req = replicaGroupsRequest()
+	req.ReplicaGroups[0].Name = "Chief_0"
+	assert.Error(t, validateReplicaGroups(req))
+
+	req = replicaGroupsRequest()
+	req.ReplicaGroups[1].Replicas = 0
+	assert.Error(t, validateReplicaGroups(req))
+
+	//frameworks without a launcher only get a single learner
+	req = replicaGroupsRequest()
+	req.Framework = "tensorflow"
+	assert.NoError(t, validateDistribution(req))
+	req.Framework = "caffe"
+	assert.Error(t, validateDistribution(req))
+	req.ReplicaGroups = nil
+	assert.NoError(t, validateDistribution(req))
+}
+
+func TestReplicaGroupRequest(t *testing.T) {
+	req := replicaGroupsRequest()
+	assert.Equal(t, 6, totalLearners(req))
+	assert.Equal(t, []string{"chief"}, completionRoles(req))
+	assert.Equal(t, "chief:1,worker:3,ps:2", replicaGroupsEnvValue(req))
+
+	worker := replicaGroupRequest(req, req.ReplicaGroups[1])
+	assert.EqualValues(t, 3, worker.Resources.Learners)
+	assert.EqualValues(t, 1, worker.Resources.Gpus)
+	assert.Equal(t, "python worker.py", worker.EnvVars["TRAINING_COMMAND"])
+	assert.Equal(t, "learner-worker", learnerExitName(worker))
+	//the request of the training is left alone
+	assert.Equal(t, "python train.py", req.EnvVars["TRAINING_COMMAND"])
+	assert.EqualValues(t, 1, req.Resources.Learners)
+
+	ps := replicaGroupRequest(req, req.ReplicaGroups[2])
+	assert.EqualValues(t, 4, ps.Resources.Cpus)
+	assert.EqualValues(t, 2, ps.Resources.Learners)
+
+	chief := replicaGroupRequest(req, req.ReplicaGroups[0])
+	assert.Equal(t, learnerContainerName, learnerExitName(chief))
+	assert.Equal(t, learnerContainerName, learnerExitName(req))
+
+	//the learners of all groups get their own learner-<id>, chief-0 is learner-1, worker-0 learner-2 and ps-0 learner-5
+	assert.Equal(t, 0, learnerIDOffset(chief))
+	assert.Equal(t, 1, learnerIDOffset(worker))
+	assert.Equal(t, 4, learnerIDOffset(ps))
+	for _, ev := range replicaGroupEnvVars(nil, ps, "learner-ps") {
+		if ev.Name == learnerIDOffsetEnvVar {
+			assert.Equal(t, "4", ev.Value)
+		}
+	}
+}
+
+func TestMPILauncherRequest(t *testing.T) {
+	req := &service.JobDeploymentRequest{
+		Name:      "training-job2",
+		Framework: "horovod",
+		Version:   "0.13.10",
+		Resources: &service.ResourceRequirements{Cpus: 8, Gpus: 2, Learners: 3, GpuType: "nvidia-TeslaK80"},
+		EnvVars:   map[string]string{"TRAINING_COMMAND": "python train.py"},
+	}
+	assert.True(t, usesMPILauncher(req))
+	assert.Equal(t, 4, learnerPods(req))
+	assert.Equal(t, []string{"launcher"}, completionRoles(req))
+	assert.Equal(t, "launcher:1,worker:3", replicaGroupsEnvValue(req))
+
+	launcher := mpiLauncherRequest(req)
+	assert.EqualValues(t, 0, launcher.Resources.Gpus)
+	assert.Contains(t, launcher.EnvVars["TRAINING_COMMAND"], "mpirun")
+	assert.Equal(t, learnerContainerName, learnerExitName(launcher))
+
+	worker := replicaGroupRequest(req, &service.ReplicaGroup{Name: "worker", Replicas: 3})
+	assert.Equal(t, "learner-worker", learnerExitName(worker))
+
+	req.ReplicaGroups = []*service.ReplicaGroup{{Name: "chief", Replicas: 1}}
+	assert.Error(t, validateReplicaGroups(req))
+}
@@ -17,6 +17,7 @@
 package lcm
 
 import (
+	"fmt"
 	"github.com/spf13/viper"
 	//"errors"
 	"strconv"
@@ -29,18 +30,20 @@ import (
 
 	"github.com/AISphere/ffdl-commons/logger"
 	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-trainer/client"
 	"github.com/AISphere/ffdl-trainer/trainer/grpc_trainer_v2"
 )
 
 const (
 	devicePlugin = "device_plugin"
+	//trainings that request more than the cluster can allocate are rejected unless this is false, e.g. with a cluster autoscaler
+	resourceAdmissionKey = "resource_admission"
 )
 
-func (s *lcmService) currentResourceSnapshot(jdreq *service.JobDeploymentRequest, numLearners int, logr *logger.LocLoggingEntry) bool {
+//resourcesRequired ... the cpus, gpus and bytes of memory a training requests with its job monitor and helper containers
+func resourcesRequired(jdreq *service.JobDeploymentRequest, numLearners int) (float64, float64, float64) {
 
 	cpusRequired := float64(numLearners) * float64(jdreq.Resources.Cpus)
-	gpusRequired := int64(numLearners) * int64(jdreq.Resources.Gpus)
-	continueDeploy := true
 
 	//Account for Job Monitor
 	cpusRequired++
@@ -50,6 +53,51 @@ func (s *lcmService) currentResourceSnapshot(jdreq *service.JobDeploymentRequest
 		cpusRequired = cpusRequired + float64(jdreq.Resources.Cpus)
 	}
 
+	//Account for the helper containers
+	helperCpus, helperMem := helperResourcesRequired(jdreq)
+	cpusRequired += helperCpus
+	memRequired := float64(numLearners)*calcMemory(jdreq.Resources)*1024*1024 + helperMem
+
+	return cpusRequired, requiredGPUs(jdreq), memRequired
+}
+
+func resourceAdmission() bool {
+	if viper.IsSet(resourceAdmissionKey) {
+		return viper.GetBool(resourceAdmissionKey)
+	}
+	return true
+}
+
+//admitTraining rejects a training that requests more than the nodes of the cluster can allocate, its pods would never be
+//scheduled. A training that needs resources other trainings hold is deployed and waits for them
+func (s *lcmService) admitTraining(jdreq *service.JobDeploymentRequest, numLearners int, logr *logger.LocLoggingEntry) error {
+	if !resourceAdmission() {
+		return nil
+	}
+	cpusRequired, gpusRequired, memRequired := resourcesRequired(jdreq, numLearners)
+
+	k8sConnected, alloc, _, avl := getResources(s, logr)
+	if !k8sConnected {
+		logr.Warnf("(LCM) Cannot connect to kubernetes to check the resources of %s, deploying it anyway", jdreq.TrainingId)
+		return nil
+	}
+
+	logr.Debugf("(LCM) %f CPUs, %f GPUs and %f GB RAM required for Training Job %s", cpusRequired, gpusRequired, memRequired/(1024*1024*1024), jdreq.TrainingId)
+	if cpusRequired > alloc.cpusAllocatable || gpusRequired > alloc.gpusAllocatable || memRequired > alloc.memAllocatable {
+		return rejectDeployment(client.ErrCodeInsufficientResources, fmt.Errorf("training job %s requests %.1f cpus, %.0f gpus and %.1f GB memory, the cluster can allocate %.1f cpus, %.0f gpus and %.1f GB memory",
+			jdreq.TrainingId, cpusRequired, gpusRequired, memRequired/(1024*1024*1024), alloc.cpusAllocatable, alloc.gpusAllocatable, alloc.memAllocatable/(1024*1024*1024)))
+	}
+	if cpusRequired > avl.cpusAvailable || gpusRequired > avl.gpusAvailable || memRequired > avl.memAvailable {
+		logr.Infof("(LCM) Training Job %s waits for resources held by other trainings", jdreq.TrainingId)
+	}
+	return nil
+}
+
+func (s *lcmService) currentResourceSnapshot(jdreq *service.JobDeploymentRequest, numLearners int, logr *logger.LocLoggingEntry) bool {
+
+	cpusRequired, gpusRequired, memRequired := resourcesRequired(jdreq, numLearners)
+	continueDeploy := true
+
 	k8sConnected, alloc, rreq, avl := getResources(s, logr)
 	if !k8sConnected {
 		continueDeploy = false
@@ -62,7 +110,8 @@ func (s *lcmService) currentResourceSnapshot(jdreq *service.JobDeploymentRequest
 	}
 
 	logr.Debugf("(LCM) Logging current resource usage stats before deploying Training Job %s", jdreq.TrainingId)
-	logr.Debugf("(LCM) %f CPUs and %d GPUs required for Training Job %s", cpusRequired, gpusRequired, jdreq.TrainingId)
+	logr.Debugf("(LCM) %f CPUs and %f GPUs required for Training Job %s", cpusRequired, gpusRequired, jdreq.TrainingId)
+	logr.Debugf("(LCM) %f GB RAM required for Training Job %s", memRequired/(1024*1024*1024), jdreq.TrainingId)
 	logr.Debugf("(LCM) %f CPUs and %f GPUs allocatable", alloc.cpusAllocatable, alloc.gpusAllocatable)
 	logr.Debugf("(LCM) %f CPUs and %f GPUs requested already by existing pods", rreq.cpusRequested, rreq.gpusRequested)
 	logr.Debugf("(LCM) %f CPUs and %f GPUs available for Training Job %s", avl.cpusAvailable, avl.gpusAvailable, jdreq.TrainingId)
@@ -150,11 +199,7 @@ func getResources(s *lcmService, logr *logger.LocLoggingEntry) (bool, *allocatab
 		}
 	}
 
-	// Define GPU resource as device plugin or accelerator
-	var resourceGPU v1core.ResourceName = "nvidia.com/gpu"
-	if !getDevicePlugin() {
-		resourceGPU = v1core.ResourceNvidiaGPU
-	}
+	resourceGPU := gpuResourceName()
 
 	//By querying nodes, determine the number of allocatable resources
 	for _, node := range nodes.Items {
@@ -217,6 +262,14 @@ func getResources(s *lcmService, logr *logger.LocLoggingEntry) (bool, *allocatab
 
 }
 
+// Define GPU resource as device plugin or accelerator
+func gpuResourceName() v1core.ResourceName {
+	if !getDevicePlugin() {
+		return v1core.ResourceNvidiaGPU
+	}
+	return "nvidia.com/gpu"
+}
+
 func getDevicePlugin() bool {
 	if viper.IsSet(devicePlugin) {
 		return viper.GetBool(devicePlugin)
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/cenkalti/backoff"
+	"github.com/spf13/viper"
+	v1core "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//when enabled every training gets a service account per pod role, otherwise learners and helpers run with the
+//default service account of the namespace and the job monitor with the one of the lcm
+const jobServiceAccountsKey = "security.job_service_accounts"
+
+//the rules of the role bound to the service account of a pod role, pod roles without rules get no role
+var jobServiceAccountRules = map[string][]rbacv1.PolicyRule{
+	//the job monitor inspects the pods of the training for failures
+	jobMonitorPodRole: {{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}}},
+}
+
+func jobServiceAccountsEnabled() bool {
+	return viper.GetBool(jobServiceAccountsKey)
+}
+
+func jobServiceAccountName(role string, jobName string) string {
+	return role + "-sa-" + jobName
+}
+
+//jobServiceAccounts are the service accounts of the pod roles of a training and the roles and bindings of the roles with rules,
+//labelled with the training id so kill deletes them
+func jobServiceAccounts(req *service.JobDeploymentRequest) ([]*v1core.ServiceAccount, []*rbacv1.Role, []*rbacv1.RoleBinding) {
+	namespace := config.GetLearnerNamespace()
+	var accounts []*v1core.ServiceAccount
+	var roles []*rbacv1.Role
+	var bindings []*rbacv1.RoleBinding
+	for _, podRole := range []string{learnerPodRole, helperPodRole, jobMonitorPodRole} {
+		meta := metav1.ObjectMeta{
+			Name:      jobServiceAccountName(podRole, req.Name),
+			Namespace: namespace,
+			Labels:    map[string]string{"training_id": req.TrainingId, "user_id": req.UserId},
+		}
+		rules, hasRules := jobServiceAccountRules[podRole]
+		automountToken := hasRules
+		accounts = append(accounts, &v1core.ServiceAccount{ObjectMeta: meta, AutomountServiceAccountToken: &automountToken})
+		if !hasRules {
+			continue
+		}
+		roles = append(roles, &rbacv1.Role{ObjectMeta: meta, Rules: rules})
+		bindings = append(bindings, &rbacv1.RoleBinding{
+			ObjectMeta: meta,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: meta.Name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: meta.Name, Namespace: namespace}},
+		})
+	}
+	return accounts, roles, bindings
+}
+
+//createJobServiceAccounts creates the service accounts, roles and bindings of a training if jobs get their own service accounts
+func (s *lcmService) createJobServiceAccounts(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) error {
+	if !jobServiceAccountsEnabled() {
+		return nil
+	}
+	namespace := config.GetLearnerNamespace()
+	accounts, roles, bindings := jobServiceAccounts(req)
+	create := func(kind string, name string, create func() error) error {
+		return backoff.RetryNotify(func() error {
+			err := create()
+			if k8serrors.IsAlreadyExists(err) {
+				logr.WithError(err).Warnf("%s %s already exists", kind, name)
+				return nil
+			}
+			return err
+		}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+			logr.WithError(err).Errorf("Failed in creating %s %s while deploying for training", kind, name)
+			k8sFailureCounter.With(component, kind).Add(1)
+		})
+	}
+	for _, account := range accounts {
+		if err := create("serviceaccount", account.Name, func() error {
+			_, err := s.k8sClient.CoreV1().ServiceAccounts(namespace).Create(account)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	for _, role := range roles {
+		if err := create("role", role.Name, func() error {
+			_, err := s.k8sClient.RbacV1().Roles(namespace).Create(role)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	for _, binding := range bindings {
+		if err := create("rolebinding", binding.Name, func() error {
+			_, err := s.k8sClient.RbacV1().RoleBindings(namespace).Create(binding)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//deleteJobServiceAccounts deletes the service accounts, roles and bindings of a training, they may exist even if
+//the feature was turned off since the training was deployed
+func (s *lcmService) deleteJobServiceAccounts(trainingID string, selector string, deleteOpts *metav1.DeleteOptions, logr *logger.LocLoggingEntry) {
+	namespace := config.GetLearnerNamespace()
+	listOpts := metav1.ListOptions{LabelSelector: selector}
+	if err := s.k8sClient.RbacV1().RoleBindings(namespace).DeleteCollection(deleteOpts, listOpts); err != nil {
+		logr.WithError(err).Errorf("deleting role bindings for '%s' failed", trainingID)
+	}
+	if err := s.k8sClient.RbacV1().Roles(namespace).DeleteCollection(deleteOpts, listOpts); err != nil {
+		logr.WithError(err).Errorf("deleting roles for '%s' failed", trainingID)
+	}
+	if err := s.k8sClient.CoreV1().ServiceAccounts(namespace).DeleteCollection(deleteOpts, listOpts); err != nil {
+		logr.WithError(err).Errorf("deleting service accounts for '%s' failed", trainingID)
+	}
+}
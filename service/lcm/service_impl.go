@@ -17,6 +17,7 @@
 package lcm
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/AISphere/ffdl-commons/metricsmon"
 	"github.com/AISphere/ffdl-lcm/lcmconfig"
 	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/trainingjob"
 	"github.com/AISphere/ffdl-trainer/client"
 	"github.com/AISphere/ffdl-trainer/trainer/grpc_trainer_v2"
 
@@ -51,10 +53,11 @@ import (
 var gerrf = grpc.Errorf
 
 var (
-	//NativeFrameworks which support native distribution
-	NativeFrameworks = []string{"tensorflow", "caffe2", "mxnet", "horovod", "pytorch"}
 	totalTrainingCounter, finishedTrainingCounter,
-	failedToLaunchTrainingsCounter, k8sFailureCounter metrics.Counter
+	failedToLaunchTrainingsCounter, k8sFailureCounter,
+	staticVolumeLeaseCounter metrics.Counter
+
+	staticVolumeSlotsLeasedGauge, staticVolumeSlotsTotalGauge metrics.Gauge
 )
 
 //Service LCM manages the lifecycle of the entire distributed deep learning job
@@ -70,6 +73,9 @@ type lcmService struct {
 	etcdClient coord.Coordinator
 	serverInfo *version.Info
 	clusterEnv string
+	//trainingJobs is only set when lcm runs in TrainingJob CRD mode
+	trainingJobs trainingjob.Interface
+	stopCh       chan struct{}
 }
 
 //NewService is a constructor to initialize LCM
@@ -81,6 +87,9 @@ func NewService() (Service, error) {
 func (s *lcmService) StopLCM() {
 	logr := logger.LocLogger(logger.LogServiceBasic(logger.LogkeyLcmService))
 	logr.Debugf(" ###### shutting down lcm ###### ")
+	if s.stopCh != nil {
+		close(s.stopCh)
+	}
 	s.etcdClient.Close(logr)
 	s.Stop() // stop Service
 }
@@ -96,6 +105,9 @@ func newService() (*lcmService, error) {
 	finishedTrainingCounter = metricsmon.NewCounter("lcm_trainings_killed", "Metrics for lcm trainings that were killed ", []string{outcome, progress})
 	failedToLaunchTrainingsCounter = metricsmon.NewCounter("lcm_trainings_launch_failed", "Metrics for lcm trainings that failed to launch", []string{reason})
 	k8sFailureCounter = metricsmon.NewCounter("k8s_deploy_failures", "metrics for tracking k8s failures when starting trainings", []string{component})
+	staticVolumeLeaseCounter = metricsmon.NewCounter("lcm_static_volume_leases", "Metrics for static volume leases requested, leased, released and refused because the pool is exhausted", []string{outcome})
+	staticVolumeSlotsLeasedGauge = metricsmon.NewGauge("lcm_static_volume_slots_leased", "Metrics for the leased static volume slots per zone", []string{zoneLabel})
+	staticVolumeSlotsTotalGauge = metricsmon.NewGauge("lcm_static_volume_slots_total", "Metrics for the static volume slots per zone", []string{zoneLabel})
 	lcmRestartCounter := metricsmon.NewCounter("lcm_restart_total", "Metrics for lcm restarts because of failures", []string{reason})
 
 	// assert necessary config keys
@@ -142,6 +154,21 @@ func newService() (*lcmService, error) {
 		clusterEnv: clusterEnv,
 	}
 
+	if viper.GetBool(trainingJobCRDModeKey) {
+		trainingJobs, err := trainingjob.NewForConfig(k8sConfig, config.GetLearnerNamespace())
+		if err != nil {
+			logr.WithError(err).Errorf("Failed to create a TrainingJob client using config: %v", k8sConfig)
+			lcmRestartCounter.With(reason, "k8s").Add(1)
+			return nil, err
+		}
+		logr.Infof("LCM is running in TrainingJob CRD mode, watching namespace %s", config.GetLearnerNamespace())
+		s.trainingJobs = trainingJobs
+		s.stopCh = make(chan struct{})
+		go newTrainingJobController(s, trainingJobs).run(s.stopCh)
+	}
+
+	go s.reconcileSuspends(logr)
+
 	s.RegisterService = func() {
 		service.RegisterLifecycleManagerServer(s.Server, s)
 	}
@@ -161,15 +188,106 @@ func (s *lcmService) DeployTrainingJob(ctx context.Context, req *service.JobDepl
 	}))
 
 	totalTrainingCounter.With("framework", req.Framework).Add(1)
+	logr.Debugf("Deploying training job %s with env vars %v", req.TrainingId, redactCredentials(req.EnvVars))
+	if err := s.validateDeploymentRequest(req, logr); err != nil {
+		failedToLaunchTrainingsCounter.With(reason, client.ErrCodeFailedDeploy).Add(1)
+		return nil, err
+	}
+	//the copies of the request in etcd and the TrainingJob resource do without its credentials
+	persisted := req
+	if s.trainingJobs != nil || redeployMaxAttempts() > 0 {
+		var err error
+		if persisted, err = s.saveRequestCredentials(req, logr); err != nil {
+			logr.WithError(err).Errorf("Failed to keep the credentials of training job %s", req.TrainingId)
+			failedToLaunchTrainingsCounter.With(reason, client.ErrCodeK8SConnection).Add(1)
+			return nil, err
+		}
+	}
+	s.persistDeploymentRequest(persisted, logr)
 	err := updateJobStatus(req.TrainingId, grpc_trainer_v2.Status_PENDING, req.UserId, service.StatusMessages_NORMAL_OPERATION.String(), client.ErrCodeNormal, logr)
 	if err != nil {
 		logr.WithError(err).Errorf("(deployDistributedTrainingJob) Before deploying job, error while calling Trainer service client update for trainingID %s , but still carrying on ", req.TrainingId)
 	}
 
+	if s.trainingJobs != nil {
+		if err := s.createTrainingJob(persisted, logr); err != nil {
+			failedToLaunchTrainingsCounter.With(reason, client.ErrCodeK8SConnection).Add(1)
+			handleDeploymentFailure(s, req.Name, req.TrainingId, req.UserId, "TrainingJob resource", client.ErrCodeFailedDeploy, logr)
+			return nil, err
+		}
+		return &service.JobDeploymentResponse{Name: req.Name}, nil
+	}
+
 	go s.deployDistributedTrainingJob(ctx, req, logr)
 	return &service.JobDeploymentResponse{Name: req.Name}, nil
 }
 
+//validateDeploymentRequest rejects requests that can not be deployed, TrainingJob resources created directly
+//in the cluster are checked by the controller as well
+func (s *lcmService) validateDeploymentRequest(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) error {
+	if err := validateReplicaGroups(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with invalid replica groups", req.TrainingId)
+		return err
+	}
+	if err := validateDistribution(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with more learners than its framework supports", req.TrainingId)
+		return err
+	}
+	if err := validateElasticBounds(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with invalid learner bounds", req.TrainingId)
+		return err
+	}
+	if err := validateTensorFlowLayout(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with an invalid tensorflow layout", req.TrainingId)
+		return err
+	}
+	if err := validatePlacement(req, logr); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with invalid placement", req.TrainingId)
+		return err
+	}
+	if err := validateStorage(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with invalid storage", req.TrainingId)
+		return err
+	}
+	if err := validateDataStores(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with unsupported data stores", req.TrainingId)
+		return err
+	}
+	if err := validateDatasetVolumes(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with invalid dataset volumes", req.TrainingId)
+		return err
+	}
+	if err := validateEvaluationMetrics(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with an invalid evaluation metrics spec", req.TrainingId)
+		return err
+	}
+	if err := validateSidecars(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with unknown sidecars", req.TrainingId)
+		return err
+	}
+	if err := validateHelperResources(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with invalid helper resources", req.TrainingId)
+		return err
+	}
+	if err := validateInputDatasets(req); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with invalid input datasets", req.TrainingId)
+		return err
+	}
+	if err := s.checkInputDatasetSecrets(req, logr); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with unusable input dataset secrets", req.TrainingId)
+		return err
+	}
+	if err := s.checkDatasetClaims(req, logr); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with unusable dataset volume claims", req.TrainingId)
+		return err
+	}
+	if err := s.checkImageRegistries(req, logr); err != nil {
+		logr.WithError(err).Errorf("Rejecting training job %s with unusable image registries", req.TrainingId)
+		return err
+	}
+	return nil
+}
+
 //Stops a currently executing training job
 func (s *lcmService) HaltTrainingJob(ctx context.Context, req *service.JobHaltRequest) (*service.JobHaltResponse, error) {
 
@@ -178,26 +296,61 @@ func (s *lcmService) HaltTrainingJob(ctx context.Context, req *service.JobHaltRe
 	logr := logger.LocLogger(InitLogger(req.TrainingId, req.UserId))
 	logr.Infof("Halting training job: %s", req.TrainingId)
 
-	path := req.TrainingId + "/halt"
-	success, error := s.etcdClient.PutIfKeyMissing(path, "", logr)
-	if error != nil {
-		logr.WithError(error).Errorf("Failed to update the halt training job status on path %s for training job %s", path, req.TrainingId)
-		return nil, error
+	if s.trainingJobs != nil {
+		if err := s.updateTrainingJobSpec(req.TrainingId, func(spec *trainingjob.TrainingJobSpec) { spec.Halt = true }); err == nil {
+			return &service.JobHaltResponse{}, nil
+		} else if !k8serrors.IsNotFound(err) {
+			logr.WithError(err).Errorf("Failed to request halt on the TrainingJob resource of training job %s", req.TrainingId)
+			return nil, err
+		}
+		logr.Warnf("No TrainingJob resource found for training job %s, halting it directly", req.TrainingId)
 	}
-	if !success {
-		logr.Warnf("While updating halt for training job %s at path %s , the path already exists", req.TrainingId, path)
+
+	if err := s.haltTrainingJob(req.TrainingId, logr); err != nil {
+		return nil, err
 	}
 	counter.With(progress, "etcdKeysDeleted").Add(1)
 
 	return &service.JobHaltResponse{}, nil
 }
 
+//signals the learners of a training job to store their results and stop
+func (s *lcmService) haltTrainingJob(trainingID string, logr *logger.LocLoggingEntry) error {
+	path := trainingID + "/halt"
+	success, error := s.etcdClient.PutIfKeyMissing(path, "", logr)
+	if error != nil {
+		logr.WithError(error).Errorf("Failed to update the halt training job status on path %s for training job %s", path, trainingID)
+		return error
+	}
+	if !success {
+		logr.Warnf("While updating halt for training job %s at path %s , the path already exists", trainingID, path)
+	}
+	return nil
+}
+
 //default deploy job function.
 func (s *lcmService) deployDistributedTrainingJob(ctx context.Context, req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) {
+	if failedComponent, err := s.deployTrainingJobComponents(ctx, req, logr); err != nil {
+		errorCode := deploymentErrorCode(err)
+		if s.redeployAfterFailure(req.TrainingId, errorCode, fmt.Sprintf("%s failed: %s", failedComponent, err.Error()), logr) {
+			return
+		}
+		handleDeploymentFailure(s, req.Name, req.TrainingId, req.UserId, failedComponent, errorCode, logr)
+		return //short circuit the code here, since the trainer was updated it knows the job was failed
+	}
+}
+
+//creates the etcd nodes, job monitor and learner BOM of a training job, returns the component that failed to deploy
+func (s *lcmService) deployTrainingJobComponents(ctx context.Context, req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) (string, error) {
 
 	// if zone is not already set, add zone related information to deployment request, these labels will be available to jm and learner/helper
 	if z, hasZone := req.Labels["deploy_zone"]; !hasZone || z == "" {
 		logr.Debugf("%s does not have a zone label", req.TrainingId)
+		//the job monitor, helper and learners all read the zone from the labels, as does the static volume lookup
+		if zone := s.selectDeployZone(req, logr); zone != "" {
+			logr.Infof("Selected zone %s for %s", zone, req.TrainingId)
+			req.Labels["deploy_zone"] = zone
+		}
 	} else {
 		logr.Debugf("Deploying %s to zone %s", req.TrainingId, req.Labels["deploy_zone"])
 	}
@@ -206,12 +359,25 @@ func (s *lcmService) deployDistributedTrainingJob(ctx context.Context, req *serv
 	req.Labels["kube_minor"] = strings.Trim(s.serverInfo.Minor, "+")
 	req.Labels["cluster_env"] = s.clusterEnv
 
-	numLearners := int(req.GetResources().Learners)
-	useNativeDistribution := false //always use native since we don't support PS anymore
+	numLearners := learnerPods(req)
+	if err := s.admitTraining(req, numLearners, logr); err != nil {
+		failedToLaunchTrainingsCounter.With(reason, resourceAdmissionFailed).Add(1)
+		logr.WithError(err).Errorf("Rejecting training job that the cluster can not schedule")
+		return "resource admission", err
+	}
 
-	if numLearners < 1 {
-		numLearners = 1
+	delete(req.Labels, staticVolumeLabel)
+	staticVolume, err := s.leaseStaticVolume(req, logr)
+	if err != nil {
+		failedToLaunchTrainingsCounter.With(reason, staticVolumeLeaseFailed).Add(1)
+		logr.WithError(err).Errorf("Failed to lease a static volume for training job")
+		return "static volume lease", err
 	}
+	if staticVolume != "" {
+		req.Labels[staticVolumeLabel] = staticVolume
+	}
+
+	useNativeDistribution := false //parameter servers are deployed as a replica group of the learners
 
 	logr.WithField("learners", numLearners).Infof("starting deployment of training job in lcm")
 
@@ -219,25 +385,40 @@ func (s *lcmService) deployDistributedTrainingJob(ctx context.Context, req *serv
 	if err := createEtcdNodes(s, req.Name, req.UserId, req.TrainingId, numLearners, req.Framework, logr); err != nil {
 		failedToLaunchTrainingsCounter.With(reason, client.ErrCodeEtcdConnection).Add(1)
 		logr.WithError(err).Errorf("Failed to create etcd nodes necessary to deploy a training job")
-		handleDeploymentFailure(s, req.Name, req.TrainingId, req.UserId, "etcd nodes creation", logr)
-		return //short circuit the code here, since the trainer was updated it knows the job was failed
+		return "etcd nodes creation", err
+	}
+
+	if err := s.createJobServiceAccounts(req, logr); err != nil {
+		failedToLaunchTrainingsCounter.With(reason, client.ErrCodeK8SConnection).Add(1)
+		logr.WithError(err).Errorf("Failed to create the service accounts of the training job")
+		return "service accounts", err
+	}
+
+	if err := s.createSidecarService(req, logr); err != nil {
+		failedToLaunchTrainingsCounter.With(reason, client.ErrCodeK8SConnection).Add(1)
+		logr.WithError(err).Errorf("Failed to create the sidecar service of the training job")
+		return "sidecar service", err
 	}
 
+	images := s.newJobImages(req, logr)
+
 	logr.Infof("now starting to deploy job monitor to monitor training job")
-	if err := deployJobMonitor(s, req, req.TrainingId, numLearners, req.Name, req.UserId, useNativeDistribution, logr); err != nil {
+	if err := deployJobMonitor(s, req, req.TrainingId, numLearners, req.Name, req.UserId, useNativeDistribution, images, logr); err != nil {
 		failedToLaunchTrainingsCounter.With(reason, jmLaunchFailed).Add(1)
 		logr.WithError(err).Errorf("Failed to create job monitor for training job")
-		handleDeploymentFailure(s, req.Name, req.TrainingId, req.UserId, "job monitor", logr)
-		return
+		return "job monitor", err
 	}
+	//the lease was granted before the etcd nodes and service accounts, the job monitor refreshes it once it runs
+	s.refreshStaticVolumeLease(req.TrainingId, logr)
 
 	logr.Infof("now starting to deploy learners for training job")
-	if err := NewTraining(ctx, s.k8sClient, req, logr).Start(); err != nil {
+	if err := NewTraining(ctx, s.k8sClient, req, images, logr).Start(); err != nil {
 		//Deploying learner helpers has failed. So update status
 		failedToLaunchTrainingsCounter.With(reason, learnerLaunchFailed).Add(1)
-		handleDeploymentFailure(s, req.Name, req.TrainingId, req.UserId, "learner deployment", logr)
-		return
+		return "learner deployment", err
 	}
+	s.recordImageDigests(req.TrainingId, images, logr)
+	return "", nil
 }
 
 //Kills a currently executing training job and cleans up its zookeeper entries
@@ -247,54 +428,83 @@ func (s *lcmService) KillTrainingJob(ctx context.Context, req *service.JobKillRe
 	logr := logger.LocLogger(InitLogger(req.TrainingId, req.UserId))
 
 	logr.Infof("Killing training job: %s", req.Name)
+	s.forgetDeploymentRequest(req.TrainingId, logr)
+
+	if s.trainingJobs != nil {
+		if err := s.updateTrainingJobSpec(req.TrainingId, func(spec *trainingjob.TrainingJobSpec) { spec.Kill = true }); err == nil {
+			return &service.JobKillResponse{}, nil
+		} else if !k8serrors.IsNotFound(err) {
+			logr.WithError(err).Errorf("Failed to request kill on the TrainingJob resource of training job %s", req.TrainingId)
+			return nil, err
+		}
+		logr.Warnf("No TrainingJob resource found for training job %s, killing it directly", req.TrainingId)
+	}
+
+	s.deleteTrainingJobResources(req.Name, req.TrainingId, counter, logr)
+	return &service.JobKillResponse{}, nil
+}
 
-	selector := "training_id==" + req.TrainingId
+//deletes all the kubernetes objects and etcd keys of a training job, errors are logged and the cleanup carries on.
+//The last error is returned, the cleanup has to be repeated if it is not nil
+func (s *lcmService) deleteTrainingJobResources(jobName string, trainingID string, counter metrics.Counter, logr *logger.LocLoggingEntry) error {
+	var failed error
+	selector := "training_id==" + trainingID
 	backgroundPropagation := metav1.DeletePropagationBackground
 	backgroundDeleteOpts := &metav1.DeleteOptions{
 		PropagationPolicy: &backgroundPropagation,
 	}
 
-	logr.Debugf(" Checking if there are kubernetes services associated with training job %s", req.TrainingId)
+	logr.Debugf(" Checking if there are kubernetes services associated with training job %s", trainingID)
 	svcs, err := s.k8sClient.CoreV1().Services(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: selector})
 	if err == nil {
-		logr.Debugf(" Services for job with name '%s' found by querying kubernetes.", req.Name)
+		logr.Debugf(" Services for job with name '%s' found by querying kubernetes.", jobName)
 		for _, svc := range svcs.Items {
 			logr.Infof(" Deleting service '%s'", svc.ObjectMeta.Name)
 			err := s.k8sClient.CoreV1().Services(config.GetLearnerNamespace()).Delete(svc.ObjectMeta.Name, backgroundDeleteOpts)
 			if err != nil {
 				logr.WithError(err).Errorf(" Deleting kubernetes service '%s' failed", svc.ObjectMeta.Name)
+				failed = err
 			}
 		}
+	} else {
+		failed = err
 	}
 	counter.With(progress, servicesDeletedPhaseComplete).Add(1)
 
-	logr.Debugf(" Checking if there are kubernetes statefulsets associated with training job %s", req.TrainingId)
+	logr.Debugf(" Checking if there are kubernetes statefulsets associated with training job %s", trainingID)
 	sets, err := s.k8sClient.AppsV1beta1().StatefulSets(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: selector})
 	if err == nil {
-		logr.Debugf(" Stateful for job with name '%s' found by querying kubernetes.", req.Name)
+		logr.Debugf(" Stateful for job with name '%s' found by querying kubernetes.", jobName)
 		for _, set := range sets.Items {
 			logr.Infof(" Deleting stateful '%s'", set.ObjectMeta.Name)
 			err := s.k8sClient.AppsV1beta1().StatefulSets(config.GetLearnerNamespace()).Delete(set.ObjectMeta.Name, backgroundDeleteOpts)
 			if err != nil {
 				logr.WithError(err).Errorf(" Deleting kubernetes stateful '%s' failed", set.ObjectMeta.Name)
+				failed = err
 			}
 		}
+	} else {
+		failed = err
 	}
 
-	logr.Debugf(" Checking if there are kubernetes learner persistent volume claims associated with training job %s", req.TrainingId)
+	logr.Debugf(" Checking if there are kubernetes learner persistent volume claims associated with training job %s", trainingID)
 	claims, err := s.k8sClient.CoreV1().PersistentVolumeClaims(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: selector})
 	if err == nil {
 		for _, claim := range claims.Items {
+			s.applyReclaimPolicy(claim, logr)
 			logr.Infof(" Deleting persistent volume claim '%s'", claim.ObjectMeta.Name)
 			err := s.k8sClient.CoreV1().PersistentVolumeClaims(config.GetLearnerNamespace()).Delete(claim.ObjectMeta.Name, backgroundDeleteOpts)
 			if err != nil {
 				logr.WithError(err).Errorf(" Deleting kubernetes persistent volume '%s' failed", claim.ObjectMeta.Name)
+				failed = err
 			}
 		}
+	} else {
+		failed = err
 	}
 	counter.With(progress, pvsDeletedPhaseComplete).Add(1)
 
-	logr.Debugf(" Checking if there are kubernetes learner COS mount secrets associated with training job %s", req.TrainingId)
+	logr.Debugf(" Checking if there are kubernetes learner COS mount secrets associated with training job %s", trainingID)
 	secrets, err := s.k8sClient.CoreV1().Secrets(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: selector})
 	if err == nil {
 		for _, secret := range secrets.Items {
@@ -302,56 +512,84 @@ func (s *lcmService) KillTrainingJob(ctx context.Context, req *service.JobKillRe
 			err := s.k8sClient.CoreV1().Secrets(config.GetLearnerNamespace()).Delete(secret.ObjectMeta.Name, backgroundDeleteOpts)
 			if err != nil {
 				logr.WithError(err).Errorf(" Deleting kubernetes Secret '%s' failed", secret.ObjectMeta.Name)
+				failed = err
 			}
 		}
+	} else {
+		failed = err
 	}
 	counter.With(progress, secretsDeletedPhaseComplete).Add(1)
 
-	logr.Debugf(" Checking if there are kubernetes deployments associated with training job %s", req.TrainingId)
+	logr.Debugf(" Checking if there are kubernetes deployments associated with training job %s", trainingID)
 	deploys, err := s.k8sClient.AppsV1beta1().Deployments(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: selector})
 	if err == nil {
-		logr.Debugf(" Deployments for job with name '%s' found by querying kubernetes.", req.Name)
+		logr.Debugf(" Deployments for job with name '%s' found by querying kubernetes.", jobName)
 		for _, deploy := range deploys.Items {
 			logr.Infof(" Deleting deployment '%s'", deploy.ObjectMeta.Name)
 			err := s.k8sClient.AppsV1beta1().Deployments(config.GetLearnerNamespace()).Delete(deploy.ObjectMeta.Name, backgroundDeleteOpts)
 			if err != nil {
 				logr.WithError(err).Errorf(" Deleting kubernetes deployment '%s' failed", deploy.ObjectMeta.Name)
+				failed = err
 			}
 		}
+	} else {
+		failed = err
 	}
 
 	counter.With(progress, deploymentsDeletedPhaseComplete).Add(1)
 
-	logr.Infof("Deleting network policies for training %s", req.TrainingId)
-	err = s.k8sClient.NetworkingV1().NetworkPolicies(config.GetPodNamespace()).DeleteCollection(backgroundDeleteOpts, metav1.ListOptions{LabelSelector: selector})
+	logr.Debugf(" Checking if there are kubernetes config maps associated with training job %s", trainingID)
+	err = s.k8sClient.CoreV1().ConfigMaps(config.GetLearnerNamespace()).DeleteCollection(backgroundDeleteOpts, metav1.ListOptions{LabelSelector: selector})
 	if err != nil {
-		logr.WithError(err).Errorf("deleting network policies for '%s' failed", req.TrainingId)
+		logr.WithError(err).Errorf("deleting config maps for '%s' failed", trainingID)
+		failed = err
 	}
 
+	logr.Infof("Deleting network policies for training %s", trainingID)
+	err = s.k8sClient.NetworkingV1().NetworkPolicies(config.GetLearnerNamespace()).DeleteCollection(backgroundDeleteOpts, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		logr.WithError(err).Errorf("deleting network policies for '%s' failed", trainingID)
+		failed = err
+	}
+
+	logr.Debugf(" Deleting service accounts and roles of training job %s", trainingID)
+	s.deleteJobServiceAccounts(trainingID, selector, backgroundDeleteOpts, logr)
+
+	s.releaseStaticVolume(trainingID, logr)
+
 	//After Deleting the application, delete the etcd directory.
-	s.etcdClient.DeleteKeyWithOpts(req.TrainingId, logr, clientv3.WithPrefix())
+	if err := s.etcdClient.DeleteKeyWithOpts(trainingID, logr, clientv3.WithPrefix()); err != nil {
+		failed = err
+	}
 	counter.With(progress, etcdKeysDeletedPhaseComplete).Add(1)
-	return &service.JobKillResponse{}, nil
+	return failed
 }
 
-//Wrapper function for LCM's KillTrainingJob
+//Wrapper function for LCM's KillTrainingJob, always deletes the resources directly so a TrainingJob resource is kept around
 func (s *lcmService) killDeployedJob(jobName string, trainingID string, userID string) error {
-	job := &service.JobKillRequest{Name: string(jobName), TrainingId: trainingID, UserId: userID}
-	_, err := s.KillTrainingJob(context.Background(), job)
-	return err
+	counter := finishedTrainingCounter.With(outcome, killed)
+	counter.With(progress, started).Add(1)
+	logr := logger.LocLogger(InitLogger(trainingID, userID))
+	logr.Infof("Killing training job: %s", jobName)
+
+	s.deleteTrainingJobResources(jobName, trainingID, counter, logr)
+	return nil
 }
 
 //manages a DLaaS training job
-func deployJobMonitor(s *lcmService, req *service.JobDeploymentRequest, trainingID string, numLearners int, jobName string, userID string, useNativeDistribution bool, logr *logger.LocLoggingEntry) error {
+func deployJobMonitor(s *lcmService, req *service.JobDeploymentRequest, trainingID string, numLearners int, jobName string, userID string, useNativeDistribution bool, images *jobImages, logr *logger.LocLoggingEntry) error {
 
 	envVars, labels := populateJobMonitorEnvVariablesAndLabels(req, trainingID, jobName, userID, numLearners, useNativeDistribution)
 	var nodeAffinity *v1core.NodeAffinity
-	if isSplitMode(req.Labels["deploy_zone"], logr) {
+	if isSplitMode(req) {
 		if zone, hasZone := labels["deploy_zone"]; hasZone && zone != "" {
 			nodeAffinity = getNodeAffinity(labels)
 		}
 	}
 	deploySpec := defineJobMonitorDeployment(req, envVars, labels, logr, nodeAffinity)
+	if err := images.pinPodSpec(&deploySpec.Spec.Template.Spec); err != nil {
+		return err
+	}
 
 	return backoff.RetryNotify(func() error {
 		_, err := s.k8sClient.AppsV1beta1().Deployments(config.GetLearnerNamespace()).Create(deploySpec)
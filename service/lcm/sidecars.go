@@ -0,0 +1,373 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/lcmconfig"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/configmaps"
+	"github.com/AISphere/ffdl-lcm/service/lcm/policies"
+	"github.com/cenkalti/backoff"
+	log "github.com/sirupsen/logrus"
+	v1core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	v1resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+//sidecarCatalogueConfigPath is mounted from the lcm-sidecars configmap, only the default catalogue is offered if it is absent
+var sidecarCatalogueConfigPath = "/etc/sidecars/sidecars.yaml"
+
+//sidecars run in the learner pods or the helper pod of a training, helper sidecars run in the first learner pod of non split trainings
+const (
+	sidecarInLearner = "learner"
+	sidecarInHelper  = "helper"
+)
+
+//label the statefulset controller sets to the name of each of its pods
+const statefulSetPodNameLabel = "statefulset.kubernetes.io/pod-name"
+
+//the other learners of a non split training run the helper sidecars idle, their command only starts in the first learner
+const firstLearnerOnlyCommand = `if [ "${HOSTNAME##*-}" != 0 ]; then trap "exit 0" TERM; while true; do sleep 60 & wait $!; done; fi; exec "$@"`
+
+//sidecar names are part of container names, port names are limited to 15 characters by kubernetes
+var sidecarNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,38}[a-z0-9])?$`)
+var sidecarPortNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,13}[a-z0-9])?$`)
+
+//sidecarMount ... a directory of the shared job volume mounted into a sidecar
+type sidecarMount struct {
+	//SubPath is relative to the job directory of the training, empty for the whole directory
+	SubPath   string `yaml:"sub_path,omitempty"`
+	MountPath string `yaml:"mount_path"`
+	ReadOnly  bool   `yaml:"read_only,omitempty"`
+}
+
+//sidecarPort ... a port of a sidecar, exposed through the sidecars service of the training
+type sidecarPort struct {
+	Name     string `yaml:"name"`
+	Port     int32  `yaml:"port"`
+	Protocol string `yaml:"protocol,omitempty"`
+}
+
+//sidecarSpec ... a sidecar of the catalogue that trainings request by name
+type sidecarSpec struct {
+	Name    string            `yaml:"name"`
+	Image   string            `yaml:"image"`
+	Command []string          `yaml:"command,omitempty"`
+	Args    []string          `yaml:"args,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	//Pod is learner or helper
+	Pod string `yaml:"pod"`
+	//CPU and Memory are kubernetes quantities, both request and limit
+	CPU    string         `yaml:"cpu,omitempty"`
+	Memory string         `yaml:"memory,omitempty"`
+	Mounts []sidecarMount `yaml:"mounts,omitempty"`
+	Ports  []sidecarPort  `yaml:"ports,omitempty"`
+}
+
+//tensorboard reads the event files the learners write to the logs directory of the job
+func defaultSidecarCatalogue() []sidecarSpec {
+	return []sidecarSpec{
+		{
+			Name:    "tensorboard",
+			Image:   "tensorflow/tensorflow:1.5.0",
+			Command: []string{"tensorboard"},
+			Args:    []string{"--logdir=" + PodLevelJobDir + "/logs/tb", "--port=6006"},
+			Pod:     sidecarInHelper,
+			CPU:     "500m",
+			Memory:  "1Gi",
+			Mounts:  []sidecarMount{{MountPath: PodLevelJobDir, ReadOnly: true}},
+			Ports:   []sidecarPort{{Name: "tensorboard", Port: 6006}},
+		},
+	}
+}
+
+func (s sidecarSpec) validate() error {
+	if !sidecarNameRegexp.MatchString(s.Name) {
+		return fmt.Errorf("sidecar name %s is not a lower case dns label of up to 40 characters", s.Name)
+	}
+	if s.Image == "" {
+		return fmt.Errorf("sidecar %s has no image", s.Name)
+	}
+	if s.Pod != sidecarInLearner && s.Pod != sidecarInHelper {
+		return fmt.Errorf("sidecar %s runs in pod %s, not in the %s or %s pod", s.Name, s.Pod, sidecarInLearner, sidecarInHelper)
+	}
+	if s.Pod == sidecarInHelper && len(s.Command) == 0 {
+		//see firstLearnerOnly
+		return fmt.Errorf("helper sidecar %s has no command", s.Name)
+	}
+	for _, quantity := range []string{s.CPU, s.Memory} {
+		if quantity == "" {
+			continue
+		}
+		if _, err := v1resource.ParseQuantity(quantity); err != nil {
+			return fmt.Errorf("sidecar %s has an invalid resource quantity %s", s.Name, quantity)
+		}
+	}
+	for _, m := range s.Mounts {
+		//sub paths must stay in the job directory
+		if !path.IsAbs(m.MountPath) || path.IsAbs(m.SubPath) || strings.HasPrefix(path.Clean(m.SubPath), "..") {
+			return fmt.Errorf("sidecar %s has an invalid mount of %s at %s", s.Name, m.SubPath, m.MountPath)
+		}
+	}
+	for _, p := range s.Ports {
+		if !sidecarPortNameRegexp.MatchString(p.Name) || p.Port <= 0 || p.Port > 65535 {
+			return fmt.Errorf("sidecar %s has an invalid port %s %d", s.Name, p.Name, p.Port)
+		}
+		if p.Protocol != "" && p.Protocol != string(v1core.ProtocolTCP) && p.Protocol != string(v1core.ProtocolUDP) {
+			return fmt.Errorf("sidecar %s has port %s with protocol %s", s.Name, p.Name, p.Protocol)
+		}
+	}
+	return nil
+}
+
+//loadSidecarCatalogue reads the catalogue from its configmap on every call, a sidecar in the configmap replaces the default
+//of the same name. Invalid sidecars are left out of the catalogue.
+func loadSidecarCatalogue() map[string]sidecarSpec {
+	sidecars := defaultSidecarCatalogue()
+
+	var configured []sidecarSpec
+	if configmaps.Load(sidecarCatalogueConfigPath, "sidecars", &configured) {
+		sidecars = append(sidecars, configured...)
+	}
+
+	catalogue := make(map[string]sidecarSpec)
+	for _, sidecar := range sidecars {
+		if err := sidecar.validate(); err != nil {
+			log.WithError(err).Errorf("leaving sidecar %s out of the catalogue", sidecar.Name)
+			continue
+		}
+		catalogue[sidecar.Name] = sidecar
+	}
+	return catalogue
+}
+
+//validateSidecars rejects sidecars that are not in the catalogue, requested twice or whose ports clash in a pod
+func validateSidecars(req *service.JobDeploymentRequest) error {
+	if len(req.Sidecars) == 0 {
+		return nil
+	}
+	catalogue := loadSidecarCatalogue()
+	requested := make(map[string]bool)
+	ports := make(map[string]string)
+	for _, name := range req.Sidecars {
+		sidecar, ok := catalogue[name]
+		if !ok {
+			return fmt.Errorf("sidecar %s is not in the sidecar catalogue", name)
+		}
+		if requested[name] {
+			return fmt.Errorf("sidecar %s is requested more than once", name)
+		}
+		requested[name] = true
+		//non split trainings run the sidecars of both pods in the learner pods, so ports must be unique across all of them
+		for _, p := range sidecar.Ports {
+			for _, key := range []string{p.Name, fmt.Sprintf("%d/%s", p.Port, sidecarPortProtocol(p))} {
+				if other, clash := ports[key]; clash {
+					return fmt.Errorf("port %s of sidecar %s is also used by sidecar %s", key, name, other)
+				}
+				ports[key] = name
+			}
+		}
+	}
+	return nil
+}
+
+func sidecarPortProtocol(p sidecarPort) v1core.Protocol {
+	if p.Protocol == "" {
+		return v1core.ProtocolTCP
+	}
+	return v1core.Protocol(p.Protocol)
+}
+
+//requestedSidecars are the catalogue entries of the sidecars of the request in the pods, in the order of the request
+func requestedSidecars(req *service.JobDeploymentRequest, pods ...string) []sidecarSpec {
+	if len(req.Sidecars) == 0 {
+		return nil
+	}
+	catalogue := loadSidecarCatalogue()
+	var sidecars []sidecarSpec
+	for _, name := range req.Sidecars {
+		sidecar, ok := catalogue[name] //validated in DeployTrainingJob, the catalogue may have changed since
+		if !ok {
+			log.Errorf("sidecar %s of training %s is no longer in the catalogue", name, req.TrainingId)
+			continue
+		}
+		for _, pod := range pods {
+			if sidecar.Pod == pod {
+				sidecars = append(sidecars, sidecar)
+			}
+		}
+	}
+	return sidecars
+}
+
+func sidecarContainerName(name string) string {
+	return "sidecar-" + name
+}
+
+//sidecarContainers are the containers of the requested sidecars of the pods, their mounts are directories of the shared job volume
+func sidecarContainers(req *service.JobDeploymentRequest, sharedVolumeMount v1core.VolumeMount, pods ...string) []v1core.Container {
+	var containers []v1core.Container
+	for _, sidecar := range requestedSidecars(req, pods...) {
+		vars := []v1core.EnvVar{
+			{Name: "TRAINING_ID", Value: req.TrainingId},
+			{Name: "JOB_STATE_DIR", Value: sharedVolumeMount.MountPath},
+		}
+		var names []string
+		for name := range sidecar.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			vars = append(vars, v1core.EnvVar{Name: name, Value: sidecar.Env[name]})
+		}
+
+		var mounts []v1core.VolumeMount
+		for _, m := range sidecar.Mounts {
+			mounts = append(mounts, v1core.VolumeMount{
+				Name:      sharedVolumeMount.Name,
+				MountPath: m.MountPath,
+				SubPath:   path.Join(sharedVolumeMount.SubPath, m.SubPath),
+				ReadOnly:  m.ReadOnly,
+			})
+		}
+
+		var ports []v1core.ContainerPort
+		for _, p := range sidecar.Ports {
+			ports = append(ports, v1core.ContainerPort{Name: p.Name, ContainerPort: p.Port, Protocol: sidecarPortProtocol(p)})
+		}
+
+		resources := v1core.ResourceList{}
+		if sidecar.CPU != "" {
+			resources[v1core.ResourceCPU] = v1resource.MustParse(sidecar.CPU)
+		}
+		if sidecar.Memory != "" {
+			resources[v1core.ResourceMemory] = v1resource.MustParse(sidecar.Memory)
+		}
+
+		containers = append(containers, v1core.Container{
+			Name:            sidecarContainerName(sidecar.Name),
+			Image:           sidecar.Image,
+			Command:         sidecar.Command,
+			Args:            sidecar.Args,
+			Env:             vars,
+			Ports:           ports,
+			VolumeMounts:    mounts,
+			Resources:       v1core.ResourceRequirements{Requests: resources, Limits: resources},
+			ImagePullPolicy: lcmconfig.GetImagePullPolicy(),
+		})
+	}
+	return containers
+}
+
+//firstLearnerOnly starts the helper sidecars of a non split training in its first learner only, the job directory they work
+//on is local to every learner pod so they would otherwise run once per learner
+func firstLearnerOnly(containers []v1core.Container) []v1core.Container {
+	for i := range containers {
+		containers[i].Args = append(append([]string{}, containers[i].Command...), containers[i].Args...)
+		containers[i].Command = []string{"sh", "-c", firstLearnerOnlyCommand, "sh"}
+	}
+	return containers
+}
+
+func sidecarServiceName(jobName string) string {
+	return "sidecars-" + jobName
+}
+
+//sidecarService exposes the ports of the requested sidecars, nil if they have none. The target ports are named, so
+//every port only reaches the pods its sidecar runs in, whether that is the helper or the learners. The service of a non
+//split training with helper sidecar ports only reaches its first learner, the one running the helper sidecars.
+func sidecarService(req *service.JobDeploymentRequest) *v1core.Service {
+	var ports []v1core.ServicePort
+	for _, sidecar := range requestedSidecars(req, sidecarInLearner, sidecarInHelper) {
+		for _, p := range sidecar.Ports {
+			ports = append(ports, v1core.ServicePort{
+				Name:       p.Name,
+				Protocol:   sidecarPortProtocol(p),
+				Port:       p.Port,
+				TargetPort: intstr.FromString(p.Name),
+			})
+		}
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+	selector := map[string]string{"training_id": req.TrainingId}
+	if nonSplitLearners(req) {
+		for _, sidecar := range requestedSidecars(req, sidecarInHelper) {
+			if len(sidecar.Ports) > 0 {
+				selector[statefulSetPodNameLabel] = fmt.Sprintf("learner-%s-0", req.Name)
+			}
+		}
+	}
+	return &v1core.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sidecarServiceName(req.Name),
+			Namespace: config.GetLearnerNamespace(),
+			Labels:    map[string]string{"training_id": req.TrainingId}, // deleted with the other services of the training
+		},
+		Spec: v1core.ServiceSpec{
+			Selector: selector,
+			Ports:    ports,
+		},
+	}
+}
+
+//sidecarIngressRules let the ports of the requested sidecars through the network policy of the learners
+func sidecarIngressRules(req *service.JobDeploymentRequest) []policies.Rule {
+	var rules []policies.Rule
+	for _, sidecar := range requestedSidecars(req, sidecarInLearner, sidecarInHelper) {
+		if len(sidecar.Ports) == 0 {
+			continue
+		}
+		rule := policies.Rule{Name: sidecarContainerName(sidecar.Name)}
+		for _, p := range sidecar.Ports {
+			rule.Ports = append(rule.Ports, policies.Port{Protocol: string(sidecarPortProtocol(p)), Port: int(p.Port)})
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+//createSidecarService creates the service of the sidecar ports of a training
+func (s *lcmService) createSidecarService(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) error {
+	svc := sidecarService(req)
+	if svc == nil {
+		return nil
+	}
+	return backoff.RetryNotify(func() error {
+		_, err := s.k8sClient.CoreV1().Services(svc.Namespace).Create(svc)
+		if k8serrors.IsAlreadyExists(err) {
+			logr.WithError(err).Warnf("service %s already exists", svc.Name)
+			return nil
+		}
+		return err
+	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed in creating service %s while deploying for training", svc.Name)
+		k8sFailureCounter.With(component, "service").Add(1)
+	})
+}
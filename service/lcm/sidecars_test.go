@@ -0,0 +1,159 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const testSidecarCatalogue = `
+- name: exporter
+  image: prom/node-exporter:v0.16.0
+  pod: learner
+  cpu: 100m
+  memory: 64Mi
+  env:
+    B: "2"
+    A: "1"
+  mounts:
+  - sub_path: logs
+    mount_path: /logs
+    read_only: true
+  ports:
+  - name: metrics
+    port: 9100
+- name: prefetcher
+  image: prefetcher:1
+  command: [prefetch]
+  pod: helper
+- name: idle
+  image: idle:1
+  pod: helper
+- name: broken
+  pod: learner
+- name: escape
+  image: escape:1
+  pod: helper
+  mounts:
+  - sub_path: ../other-training
+    mount_path: /other
+- name: clash
+  image: clash:1
+  command: [clash]
+  pod: helper
+  ports:
+  - name: metrics
+    port: 9200
+`
+
+func TestSidecarCatalogue(t *testing.T) {
+	catalogue := loadSidecarCatalogue()
+	assert.Contains(t, catalogue, "tensorboard")
+
+	defer withConfigMap(t, &sidecarCatalogueConfigPath, testSidecarCatalogue)()
+	catalogue = loadSidecarCatalogue()
+	assert.Contains(t, catalogue, "tensorboard")
+	assert.Contains(t, catalogue, "exporter")
+	assert.Contains(t, catalogue, "prefetcher")
+	assert.NotContains(t, catalogue, "broken")
+	assert.NotContains(t, catalogue, "escape")
+	assert.NotContains(t, catalogue, "idle")
+}
+
+func TestValidateSidecars(t *testing.T) {
+	defer withConfigMap(t, &sidecarCatalogueConfigPath, testSidecarCatalogue)()
+
+	assert.NoError(t, validateSidecars(&service.JobDeploymentRequest{}))
+	assert.NoError(t, validateSidecars(&service.JobDeploymentRequest{Sidecars: []string{"exporter", "tensorboard", "prefetcher"}}))
+	assert.Error(t, validateSidecars(&service.JobDeploymentRequest{Sidecars: []string{"profiler"}}))
+	assert.Error(t, validateSidecars(&service.JobDeploymentRequest{Sidecars: []string{"broken"}}))
+	assert.Error(t, validateSidecars(&service.JobDeploymentRequest{Sidecars: []string{"exporter", "exporter"}}))
+	assert.Error(t, validateSidecars(&service.JobDeploymentRequest{Sidecars: []string{"exporter", "clash"}}))
+}
+
+func TestSidecarContainers(t *testing.T) {
+	defer withConfigMap(t, &sidecarCatalogueConfigPath, testSidecarCatalogue)()
+	req := &service.JobDeploymentRequest{Name: "job-1", TrainingId: "training-1", Sidecars: []string{"tensorboard", "exporter", "prefetcher"}}
+	shared := v1core.VolumeMount{Name: "jobdata", MountPath: PodLevelJobDir, SubPath: "training-1"}
+
+	learnerSidecars := sidecarContainers(req, shared, sidecarInLearner)
+	assert.Len(t, learnerSidecars, 1)
+	exporter := learnerSidecars[0]
+	assert.Equal(t, "sidecar-exporter", exporter.Name)
+	assert.Equal(t, []v1core.EnvVar{
+		{Name: "TRAINING_ID", Value: "training-1"},
+		{Name: "JOB_STATE_DIR", Value: PodLevelJobDir},
+		{Name: "A", Value: "1"},
+		{Name: "B", Value: "2"},
+	}, exporter.Env)
+	assert.Equal(t, []v1core.VolumeMount{{Name: "jobdata", MountPath: "/logs", SubPath: "training-1/logs", ReadOnly: true}}, exporter.VolumeMounts)
+	assert.Equal(t, []v1core.ContainerPort{{Name: "metrics", ContainerPort: 9100, Protocol: v1core.ProtocolTCP}}, exporter.Ports)
+	assert.Equal(t, "100m", exporter.Resources.Limits.Cpu().String())
+	assert.Equal(t, "64Mi", exporter.Resources.Requests.Memory().String())
+
+	helperSidecars := sidecarContainers(req, shared, sidecarInHelper)
+	assert.Len(t, helperSidecars, 2)
+	assert.Equal(t, "sidecar-tensorboard", helperSidecars[0].Name)
+	assert.Equal(t, "training-1", helperSidecars[0].VolumeMounts[0].SubPath)
+	assert.Empty(t, helperSidecars[1].Resources.Limits)
+
+	assert.Len(t, sidecarContainers(req, shared, sidecarInLearner, sidecarInHelper), 3)
+	assert.Empty(t, sidecarContainers(&service.JobDeploymentRequest{}, shared, sidecarInLearner))
+
+	//non split trainings run the helper sidecars in their first learner
+	firstLearner := firstLearnerOnly(sidecarContainers(req, shared, sidecarInHelper))
+	assert.Equal(t, []string{"sh", "-c", firstLearnerOnlyCommand, "sh"}, firstLearner[0].Command)
+	assert.Equal(t, []string{"tensorboard", "--logdir=" + PodLevelJobDir + "/logs/tb", "--port=6006"}, firstLearner[0].Args)
+	assert.Equal(t, []string{"prefetch"}, firstLearner[1].Args)
+}
+
+func TestSidecarService(t *testing.T) {
+	defer withConfigMap(t, &sidecarCatalogueConfigPath, testSidecarCatalogue)()
+	assert.Nil(t, sidecarService(&service.JobDeploymentRequest{Name: "job-1", Sidecars: []string{"prefetcher"}}))
+
+	req := &service.JobDeploymentRequest{Name: "job-1", TrainingId: "training-1", Sidecars: []string{"tensorboard", "exporter"}}
+	svc := sidecarService(req)
+	assert.Equal(t, "sidecars-job-1", svc.Name)
+	assert.Equal(t, "training-1", svc.Labels["training_id"])
+	assert.Equal(t, map[string]string{"training_id": "training-1"}, svc.Spec.Selector)
+	assert.Len(t, svc.Spec.Ports, 2)
+	assert.Equal(t, intstr.FromString("tensorboard"), svc.Spec.Ports[0].TargetPort)
+	assert.Equal(t, int32(9100), svc.Spec.Ports[1].Port)
+
+	viper.Set(config.LcmFluentdEmetricsEnable, true)
+	defer viper.Set(config.LcmFluentdEmetricsEnable, false)
+	assert.Equal(t, map[string]string{"training_id": "training-1", statefulSetPodNameLabel: "learner-job-1-0"}, sidecarService(req).Spec.Selector)
+	assert.Equal(t, map[string]string{"training_id": "training-1"}, sidecarService(&service.JobDeploymentRequest{Name: "job-1", TrainingId: "training-1", Sidecars: []string{"exporter"}}).Spec.Selector)
+
+	policy := networkPolicyForLearners(req)
+	var sidecarPorts []int32
+	for _, rule := range policy.Spec.Ingress {
+		if len(rule.From) == 0 {
+			for _, p := range rule.Ports {
+				sidecarPorts = append(sidecarPorts, p.Port.IntVal)
+			}
+		}
+	}
+	assert.Equal(t, []int32{6006, 9100}, sidecarPorts)
+}
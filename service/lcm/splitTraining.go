@@ -18,6 +18,7 @@ package lcm
 
 import (
 	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-lcm/service"
 	"github.com/cenkalti/backoff"
 
 	"time"
@@ -31,21 +32,32 @@ import (
 
 func (t splitTraining) Start() error {
 
-	serviceSpec := learner.CreateServiceSpec(t.learner.name, t.req.TrainingId)
+	if len(t.req.ReplicaGroups) > 0 {
+		return t.startReplicaGroups(t.logr)
+	}
+	if usesMPILauncher(t.req) {
+		return t.startMPILauncher(t.logr)
+	}
+
+	serviceSpec := learner.CreateServiceSpec(t.learner.name, t.req.TrainingId, learner.ServicePorts(distributedAdapterForLearners(t.req))...)
 
-	numLearners := int(t.req.GetResources().Learners)
-	statefulSpec, err := t.statefulSetSpecForLearner(serviceSpec.Name)
+	//an elastic training needs the headless service as soon as it can grow beyond one learner
+	numLearners := maxLearnerPods(t.req)
+	statefulSpec, err := t.statefulSetSpecForLearner(t.req, t.learner, serviceSpec.Name)
 	if err != nil {
 		t.logr.WithError(err).Errorf("Could not create statefulspec for %s", serviceSpec.Name)
 		return err
 	}
+	addElasticAnnotations(&statefulSpec.ObjectMeta, t.req)
 
 	return t.CreateFromBOM(&splitTrainingBOM{
 		t.learner.secrets,
 		t.learner.networkingPolicy,
-		serviceSpec,
+		[]*v1core.Service{serviceSpec},
+		learnersConfigMaps(t.req),
 		t.helper.sharedVolumeClaim,
-		statefulSpec,
+		t.learner.volumeClaims,
+		[]*v1beta1.StatefulSet{statefulSpec},
 		t.deploymentSpecForHelper(),
 		numLearners,
 	})
@@ -54,55 +66,60 @@ func (t splitTraining) Start() error {
 func (t splitTraining) deploymentSpecForHelper() *v1beta1.Deployment {
 
 	helperDefn := t.helper
-	helperContainers := t.constructAuxillaryContainers(true)
+	helperContainers := append(t.constructAuxillaryContainers(true), sidecarContainers(t.req, helperDefn.sharedVolumeMount, sidecarInHelper)...)
 
 	labelsMap := map[string]string{"training_id": t.req.TrainingId, "user_id": t.req.UserId, "deploy_zone": t.req.Labels["deploy_zone"], "PVC": helperDefn.sharedVolume.PersistentVolumeClaim.ClaimName, "framework": t.req.Framework + t.req.Version, "gpu_type": t.req.Resources.GpuType}
 	podSpec := helper.CreatePodSpec(helperContainers, []v1core.Volume{helperDefn.etcdVolume, helperDefn.sslCertsVolume, helperDefn.sharedVolume}, labelsMap)
+	applyPodSecurity(&podSpec.Spec, helperPodRole, t.req.Name)
 	deploymentSpec := helper.CreateDeploymentForHelper(helperDefn.name, podSpec)
 	return deploymentSpec
 
 }
 
-// this also creates the learner pod spec
-func (t splitTraining) statefulSetSpecForLearner(serviceName string) (*v1beta1.StatefulSet, error) {
+// this also creates the learner pod spec, req is the request of the replica group for trainings with replica groups
+func (t splitTraining) statefulSetSpecForLearner(req *service.JobDeploymentRequest, learnerDefn learnerDefinition, serviceName string) (*v1beta1.StatefulSet, error) {
 
 	gpus := make(map[string]string)
-	if t.req.Resources.Gpus > 0 {
-		gpus["ibm-cloud.kubernetes.io/gpu-type"] = t.req.Resources.GpuType
+	if req.Resources.Gpus > 0 {
+		gpus["ibm-cloud.kubernetes.io/gpu-type"] = req.Resources.GpuType
 	}
-	learnerDefn := t.learner
 	helperDefn := t.helper
 	useLogCollector := useLogCollectors(t.k8sClient, t.logr)
 	helperAndLearnerVolumes := append(learnerDefn.volumes, helperDefn.sharedVolume)
 
-	imagePullSecret, err := learner.GenerateImagePullSecret(t.k8sClient, t.req)
+	imagePullSecret, err := learner.GenerateImagePullSecret(t.k8sClient, req)
 	if err != nil {
 		return nil, err
 	}
 
 	//now create the learner container
-	learnerContainer := constructLearnerContainer(t.req, learnerDefn.envVars, learnerDefn.volumeMounts, helperDefn.sharedVolumeMount, learnerDefn.mountTrainingDataStoreInLearner, learnerDefn.mountResultsStoreInLearner, learnerDefn.mountSSHCertsInLearner, t.logr, useLogCollector) // nil for mounting shared NFS volume since non split mode
+	learnerContainer := constructLearnerContainer(req, learnerDefn.envVars, learnerDefn.volumeMounts, learnerDefn.mountWaitCommand, helperDefn.sharedVolumeMount, learnerDefn.mountTrainingDataStoreInLearner, learnerDefn.mountResultsStoreInLearner, learnerDefn.mountSSHCertsInLearner, t.logr, useLogCollector) // nil for mounting shared NFS volume since non split mode
+	learnerContainers := append(append([]v1core.Container{learnerContainer}, learnerDefn.sidecars...), sidecarContainers(req, helperDefn.sharedVolumeMount, sidecarInLearner)...)
 	labelsMap := map[string]string{
-		"training_id": t.req.TrainingId,
-		"user_id":     t.req.UserId,
-		"deploy_zone": t.req.Labels["deploy_zone"],
+		"training_id": req.TrainingId,
+		"user_id":     req.UserId,
+		"deploy_zone": req.Labels["deploy_zone"],
 		"PVC":         helperDefn.sharedVolume.PersistentVolumeClaim.ClaimName,
-		"framework":   t.req.Framework + t.req.Version,
-		"gpu_type":    t.req.Resources.GpuType,
-		"kube_major":  t.req.Labels["kube_major"],
-		"kube_minor":  t.req.Labels["kube_minor"],
-		"cluster_env": t.req.Labels["cluster_env"],
+		"framework":   req.Framework + req.Version,
+		"gpu_type":    req.Resources.GpuType,
+		"kube_major":  req.Labels["kube_major"],
+		"kube_minor":  req.Labels["kube_minor"],
+		"cluster_env": req.Labels["cluster_env"],
+	}
+	if group := req.EnvVars[replicaGroupEnvVar]; group != "" {
+		labelsMap["replica_group"] = group
 	}
 	nodeAffinity := &v1core.NodeAffinity{}
 	if z, hasZone := labelsMap["deploy_zone"]; hasZone && z != "" {
 		nodeAffinity = getNodeAffinity(labelsMap)
 	}
-	gpuTolerations := getTolerations(t.req.Resources.GpuType, 30)
+	gpuTolerations := getTolerations(req.Resources.GpuType, 30)
 	termGracePeriodSecs := getTermGracePeriodSecs(0)
-	if isCPUOnly(t.req.Resources.GpuType) {
+	if isCPUOnly(req.Resources.GpuType) {
 		gpus["gpu/nvidia"] = "NA"
 	}
-	splitLearnerPodSpec := learner.CreatePodSpec([]v1core.Container{learnerContainer}, helperAndLearnerVolumes, labelsMap, gpus, imagePullSecret, nodeAffinity, gpuTolerations, termGracePeriodSecs)
+	splitLearnerPodSpec := learner.CreatePodSpec(learnerContainers, helperAndLearnerVolumes, labelsMap, gpus, imagePullSecret, nodeAffinity, learnerPlacement(t.req), gpuTolerations, termGracePeriodSecs)
+	applyPodSecurity(&splitLearnerPodSpec.Spec, learnerPodRole, req.Name)
 	statefulSetSpec := learner.CreateStatefulSetSpecForLearner(learnerDefn.name, serviceName, learnerDefn.numberOfLearners, splitLearnerPodSpec)
 
 	return statefulSetSpec, nil
@@ -112,6 +129,16 @@ func (t splitTraining) statefulSetSpecForLearner(serviceName string) (*v1beta1.S
 func (t *splitTraining) CreateFromBOM(bom *splitTrainingBOM) error {
 	logr := t.logr
 
+	//pin the images before anything is created, a training with a missing image fails without leftovers
+	if err := t.images.pinPodSpec(&bom.helperBOM.Spec.Template.Spec); err != nil {
+		return err
+	}
+	for _, learnerBOM := range bom.learnerBOMs {
+		if err := t.images.pinPodSpec(&learnerBOM.Spec.Template.Spec); err != nil {
+			return err
+		}
+	}
+
 	namespace := config.GetLearnerNamespace()
 
 	if bom.networkPolicy != nil {
@@ -145,6 +172,23 @@ func (t *splitTraining) CreateFromBOM(bom *splitTrainingBOM) error {
 		}
 	}
 
+	//create the claims of mounted buckets
+	for _, claim := range bom.volumeClaims {
+		if err := backoff.RetryNotify(func() error {
+			err := helper.CreatePVCFromBOM(claim, t.k8sClient)
+			if k8serrors.IsAlreadyExists(err) {
+				logr.WithError(err).Warnf("volume claim %s already exists", claim.Name)
+				return nil
+			}
+			return err
+		}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+			logr.WithError(err).Errorf("Failed in creating volume claim %s while deploying for training ", claim.Name)
+			k8sFailureCounter.With(component, "volume").Add(1)
+		}); err != nil {
+			return err
+		}
+	}
+
 	//create helper
 	if err := backoff.RetryNotify(func() error {
 		_, err := t.k8sClient.AppsV1beta1().Deployments(namespace).Create(bom.helperBOM)
@@ -179,35 +223,58 @@ func (t *splitTraining) CreateFromBOM(bom *splitTrainingBOM) error {
 	}
 
 	if bom.numLearners > 1 {
-		//create service
+		//create services
+		for _, svc := range bom.services {
+			if err := backoff.RetryNotify(func() error {
+				_, err := t.k8sClient.CoreV1().Services(namespace).Create(svc)
+				if k8serrors.IsAlreadyExists(err) {
+					logr.WithError(err).Warnf("service %s already exists", svc.Name)
+					return nil
+				}
+				return err
+			}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+				logr.WithError(err).Errorf("failed in creating services %s while deploying for training ", svc.Name)
+				k8sFailureCounter.With(component, "service").Add(1)
+			}); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	for _, configMap := range bom.configMaps {
 		if err := backoff.RetryNotify(func() error {
-			_, err := t.k8sClient.CoreV1().Services(namespace).Create(bom.service)
+			_, err := t.k8sClient.CoreV1().ConfigMaps(namespace).Create(configMap)
 			if k8serrors.IsAlreadyExists(err) {
-				logr.WithError(err).Warnf("service %s already exists", bom.service.Name)
+				logr.WithError(err).Warnf("config map %s already exists", configMap.Name)
 				return nil
 			}
 			return err
 		}, k8sInteractionBackoff(), func(err error, window time.Duration) {
-			logr.WithError(err).Errorf("failed in creating services %s while deploying for training ", bom.service.Name)
-			k8sFailureCounter.With(component, "service").Add(1)
+			logr.WithError(err).Errorf("failed in creating config map %s while deploying for training ", configMap.Name)
+			k8sFailureCounter.With(component, "configmap").Add(1)
 		}); err != nil {
 			return err
 		}
-
 	}
 
-	//create the stateful set
-	return backoff.RetryNotify(func() error {
-		_, err := t.k8sClient.AppsV1beta1().StatefulSets(namespace).Create(bom.learnerBOM)
-		if k8serrors.IsAlreadyExists(err) {
-			logr.WithError(err).Warnf("Stateful set %s already exists", bom.learnerBOM.Name)
-			return nil
+	//create the stateful sets
+	for _, learnerBOM := range bom.learnerBOMs {
+		if err := backoff.RetryNotify(func() error {
+			_, err := t.k8sClient.AppsV1beta1().StatefulSets(namespace).Create(learnerBOM)
+			if k8serrors.IsAlreadyExists(err) {
+				logr.WithError(err).Warnf("Stateful set %s already exists", learnerBOM.Name)
+				return nil
+			}
+			return err
+		}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+			logr.WithError(err).Errorf("failed in creating statefulset %s while deploying for training ", learnerBOM.Name)
+			k8sFailureCounter.With(component, "learner").Add(1)
+		}); err != nil {
+			return err
 		}
-		return err
-	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
-		logr.WithError(err).Errorf("failed in creating statefulset %s while deploying for training ", bom.learnerBOM.Name)
-		k8sFailureCounter.With(component, "learner").Add(1)
-	})
+	}
+	return nil
 }
 func getNodeAffinity(labels map[string]string) *v1core.NodeAffinity {
 	return &v1core.NodeAffinity{
@@ -216,7 +283,7 @@ func getNodeAffinity(labels map[string]string) *v1core.NodeAffinity {
 				v1core.NodeSelectorTerm{
 					MatchExpressions: []v1core.NodeSelectorRequirement{
 						v1core.NodeSelectorRequirement{
-							Key:      "failure-domain.beta.kubernetes.io/zone",
+							Key:      zoneNodeLabel,
 							Operator: v1core.NodeSelectorOpIn,
 							Values:   []string{labels["deploy_zone"]},
 						},
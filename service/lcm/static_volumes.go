@@ -0,0 +1,306 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-trainer/client"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	//staticVolumesConfigFile is where the static volumes configmap is mounted into lcm
+	staticVolumesConfigFile = "/etc/static-volumes-v2/PVCs-v2.yaml"
+
+	//static volumes without a status are taken as active, any other status keeps a volume out of the pool
+	staticVolumeActive = "active"
+
+	//how many trainings share a static volume, each in its own sub path, defaults to 1
+	staticVolumeMaxJobsKey = "static_volumes.max_jobs_per_volume"
+	//only static volumes of this storage class are leased if it is set
+	sharedVolumeStorageClassKey = "shared_volume_storage_class"
+
+	//label of the deployment request with the leased static volume, the job monitor and helper read it from there
+	staticVolumeLabel = "static_volume"
+
+	//the slots of a static volume are leased under zkStaticVolumes/<volume>/<slot>
+	zkStaticVolumes     = "staticvolumes"
+	zkStaticVolume      = "static_volume"
+	zkStaticVolumeLease = "static_volume_lease"
+
+	//the job monitor refreshes the lease every minute, a training that is gone releases its volume after the ttl.
+	//lcm refreshes it once more when the job monitor deployment is created, the ttl covers the start of the job monitor
+	staticVolumeLeaseTTL = int64(10 * time.Minute / time.Second)
+
+	//zone label of the static volume gauges for volumes that fit any zone
+	anyZone = "any"
+)
+
+//staticVolume ... a pre-provisioned volume from the static volumes configmap
+type staticVolume struct {
+	Name         string `yaml:"name"`
+	Status       string `yaml:"status"`
+	StorageClass string `yaml:"storage_class"`
+	Zone         string `yaml:"zone"`
+}
+
+//the static volumes configmap is only parsed again when the mounted file changes
+var staticVolumesCache struct {
+	sync.Mutex
+	modTime time.Time
+	volumes []staticVolume
+}
+
+// Return the static volumes of the cluster, none if the configmap is not mounted.
+func loadStaticVolumes(logr *logger.LocLoggingEntry) []staticVolume {
+	info, err := os.Stat(staticVolumesConfigFile)
+	if err != nil {
+		logr.Warnf("Unable to load %s: %s", staticVolumesConfigFile, err)
+		return nil
+	}
+
+	staticVolumesCache.Lock()
+	defer staticVolumesCache.Unlock()
+	if info.ModTime().Equal(staticVolumesCache.modTime) {
+		return staticVolumesCache.volumes
+	}
+
+	type Volumes struct {
+		Volumes []staticVolume `yaml:"static-volumes-v2"`
+	}
+	var staticVolumes Volumes
+	bytes, err := ioutil.ReadFile(staticVolumesConfigFile)
+	if err != nil {
+		logr.Warnf("Unable to load %s: %s", staticVolumesConfigFile, err)
+		return nil
+	}
+	if err := yaml.Unmarshal(bytes, &staticVolumes); err != nil {
+		logr.WithError(err).Errorf("Unable to parse %s", staticVolumesConfigFile)
+		return nil
+	}
+	staticVolumesCache.modTime = info.ModTime()
+	staticVolumesCache.volumes = staticVolumes.Volumes
+	return staticVolumes.Volumes
+}
+
+func staticVolumeMaxJobs() int {
+	if viper.IsSet(staticVolumeMaxJobsKey) {
+		return viper.GetInt(staticVolumeMaxJobsKey)
+	}
+	return 1
+}
+
+func staticVolumeSlotsPath(volume string) string {
+	return zkStaticVolumes + "/" + volume + "/"
+}
+
+//usableStaticVolumes are the active volumes of the storage class in the zone, volumes without zone or class fit any
+func usableStaticVolumes(volumes []staticVolume, zone string, storageClass string) []staticVolume {
+	var usable []staticVolume
+	for _, vol := range volumes {
+		if vol.Status != "" && vol.Status != staticVolumeActive {
+			continue
+		}
+		if storageClass != "" && vol.StorageClass != "" && vol.StorageClass != storageClass {
+			continue
+		}
+		if zone != "" && vol.Zone != "" && vol.Zone != zone {
+			continue
+		}
+		usable = append(usable, vol)
+	}
+	return usable
+}
+
+//leaseCandidates are the volumes with a free slot, least used first
+func leaseCandidates(volumes []staticVolume, leases map[string]int, maxJobs int) []string {
+	var candidates []string
+	for _, vol := range volumes {
+		if leases[vol.Name] < maxJobs {
+			candidates = append(candidates, vol.Name)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if leases[candidates[i]] != leases[candidates[j]] {
+			return leases[candidates[i]] < leases[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates
+}
+
+//leaseStaticVolume leases the least used static volume for a training, empty if the cluster has no static volumes.
+//An error means the volumes exist but all of them are leased
+func (s *lcmService) leaseStaticVolume(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) (string, error) {
+	volumes := loadStaticVolumes(logr)
+	if len(volumes) == 0 || viper.GetBool(config.LcmFluentdEmetricsEnable) {
+		return "", nil
+	}
+	zone := req.Labels["deploy_zone"]
+	usable := usableStaticVolumes(volumes, zone, viper.GetString(sharedVolumeStorageClassKey))
+	defer s.updateStaticVolumeGauges(logr)
+
+	leases, err := s.staticVolumeLeases(usable, logr)
+	if err != nil {
+		return "", err
+	}
+
+	maxJobs := staticVolumeMaxJobs()
+	candidates := leaseCandidates(usable, leases, maxJobs)
+	staticVolumeLeaseCounter.With(outcome, "requested").Add(1)
+	logr.Infof("%d of %d usable static volumes in zone %s have a free slot", len(candidates), len(usable), zone)
+	if len(candidates) == 0 {
+		staticVolumeLeaseCounter.With(outcome, "exhausted").Add(1)
+		return "", rejectDeployment(client.ErrCodeInsufficientResources, fmt.Errorf("static volume pool exhausted: all %d usable volumes in zone %q are leased by %d trainings each", len(usable), zone, maxJobs))
+	}
+
+	lease, err := s.etcdClient.GrantExpiringLease(staticVolumeLeaseTTL, logr)
+	if err != nil {
+		return "", err
+	}
+	for _, volume := range candidates {
+		for slot := 0; slot < maxJobs; slot++ {
+			path := staticVolumeSlotsPath(volume) + strconv.Itoa(slot)
+			leased, err := s.etcdClient.PutIfKeyMissing(path, req.TrainingId, logr, clientv3.WithLease(lease.ID))
+			if err != nil {
+				s.etcdClient.RevokeLease(lease.ID, logr)
+				return "", err
+			}
+			if !leased {
+				continue //another training took the slot in the meantime
+			}
+			if err := s.recordStaticVolumeLease(req.TrainingId, volume, lease.ID, logr); err != nil {
+				s.etcdClient.RevokeLease(lease.ID, logr)
+				return "", err
+			}
+			staticVolumeLeaseCounter.With(outcome, "leased").Add(1)
+			logr.Infof("Leased slot %d of static volume %s for training %s", slot, volume, req.TrainingId)
+			return volume, nil
+		}
+	}
+
+	s.etcdClient.RevokeLease(lease.ID, logr)
+	staticVolumeLeaseCounter.With(outcome, "exhausted").Add(1)
+	return "", rejectDeployment(client.ErrCodeInsufficientResources, fmt.Errorf("static volume pool exhausted: the free slots of zone %q were leased by other trainings", zone))
+}
+
+//staticVolumeLeases ... the number of leased slots of each volume
+func (s *lcmService) staticVolumeLeases(volumes []staticVolume, logr *logger.LocLoggingEntry) (map[string]int, error) {
+	leases := make(map[string]int)
+	for _, vol := range volumes {
+		slots, err := s.etcdClient.Get(staticVolumeSlotsPath(vol.Name), logr, clientv3.WithPrefix())
+		if err != nil {
+			logr.WithError(err).Errorf("Failed to get the leases of static volume %s", vol.Name)
+			return nil, err
+		}
+		leases[vol.Name] = len(slots)
+	}
+	return leases, nil
+}
+
+//slotsPerZone ... the leased and the total static volume slots of the usable volumes per zone
+func slotsPerZone(volumes []staticVolume, leases map[string]int, maxJobs int) (leased map[string]int, total map[string]int) {
+	leased, total = make(map[string]int), make(map[string]int)
+	for _, vol := range volumes {
+		zone := vol.Zone
+		if zone == "" {
+			zone = anyZone
+		}
+		leased[zone] += leases[vol.Name]
+		total[zone] += maxJobs
+	}
+	return leased, total
+}
+
+//updateStaticVolumeGauges sets the leased and total slots of every zone, a lease or release changes the numbers
+func (s *lcmService) updateStaticVolumeGauges(logr *logger.LocLoggingEntry) {
+	volumes := loadStaticVolumes(logr)
+	if len(volumes) == 0 || viper.GetBool(config.LcmFluentdEmetricsEnable) {
+		return
+	}
+	usable := usableStaticVolumes(volumes, "", viper.GetString(sharedVolumeStorageClassKey))
+	leases, err := s.staticVolumeLeases(usable, logr)
+	if err != nil {
+		return
+	}
+	leased, total := slotsPerZone(usable, leases, staticVolumeMaxJobs())
+	for zone := range total {
+		staticVolumeSlotsLeasedGauge.With(zoneLabel, zone).Set(float64(leased[zone]))
+		staticVolumeSlotsTotalGauge.With(zoneLabel, zone).Set(float64(total[zone]))
+	}
+}
+
+//the job monitor keeps the lease alive, it finds it under the training
+func (s *lcmService) recordStaticVolumeLease(trainingID string, volume string, leaseID clientv3.LeaseID, logr *logger.LocLoggingEntry) error {
+	if _, err := s.etcdClient.Put(trainingID+"/"+zkStaticVolume, volume, logr); err != nil {
+		return err
+	}
+	_, err := s.etcdClient.Put(trainingID+"/"+zkStaticVolumeLease, strconv.FormatInt(int64(leaseID), 10), logr)
+	return err
+}
+
+//the static volume lease recorded for a training, false if it has none
+func (s *lcmService) staticVolumeLease(trainingID string, logr *logger.LocLoggingEntry) (clientv3.LeaseID, bool) {
+	response, err := s.etcdClient.Get(trainingID+"/"+zkStaticVolumeLease, logr)
+	if err != nil || len(response) == 0 {
+		return 0, false
+	}
+	leaseID, err := strconv.ParseInt(response[0].Value, 10, 64)
+	if err != nil {
+		logr.WithError(err).Errorf("Invalid static volume lease %s of training %s", response[0].Value, trainingID)
+		return 0, false
+	}
+	return clientv3.LeaseID(leaseID), true
+}
+
+//refreshStaticVolumeLease renews the static volume lease of a training until its job monitor takes over
+func (s *lcmService) refreshStaticVolumeLease(trainingID string, logr *logger.LocLoggingEntry) {
+	leaseID, ok := s.staticVolumeLease(trainingID, logr)
+	if !ok {
+		return
+	}
+	if _, err := s.etcdClient.RefreshLease(leaseID, logr); err != nil {
+		logr.WithError(err).Errorf("Failed to refresh the static volume lease of training %s", trainingID)
+	}
+}
+
+//releaseStaticVolume revokes the static volume lease of a training, the slot it held is free right away
+func (s *lcmService) releaseStaticVolume(trainingID string, logr *logger.LocLoggingEntry) {
+	leaseID, ok := s.staticVolumeLease(trainingID, logr)
+	if !ok {
+		return
+	}
+	if err := s.etcdClient.RevokeLease(leaseID, logr); err != nil {
+		logr.WithError(err).Errorf("Failed to release the static volume of training %s, it is freed when the lease expires", trainingID)
+		return
+	}
+	staticVolumeLeaseCounter.With(outcome, "released").Add(1)
+	s.updateStaticVolumeGauges(logr)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsableStaticVolumes(t *testing.T) {
+	volumes := []staticVolume{
+		{Name: "vol-1", Status: "active", StorageClass: "ibmc-file-gold", Zone: "dal10"},
+		{Name: "vol-2", Status: "maintenance", StorageClass: "ibmc-file-gold", Zone: "dal10"},
+		{Name: "vol-3", StorageClass: "ibmc-file-bronze", Zone: "dal10"},
+		{Name: "vol-4", Status: "active", StorageClass: "ibmc-file-gold", Zone: "dal12"},
+		{Name: "vol-5"},
+	}
+	names := func(volumes []staticVolume) []string {
+		var n []string
+		for _, v := range volumes {
+			n = append(n, v.Name)
+		}
+		return n
+	}
+	assert.Equal(t, []string{"vol-1", "vol-3", "vol-5"}, names(usableStaticVolumes(volumes, "dal10", "")))
+	assert.Equal(t, []string{"vol-1", "vol-5"}, names(usableStaticVolumes(volumes, "dal10", "ibmc-file-gold")))
+	assert.Equal(t, []string{"vol-1", "vol-3", "vol-4", "vol-5"}, names(usableStaticVolumes(volumes, "", "")))
+}
+
+func TestLeaseCandidates(t *testing.T) {
+	volumes := []staticVolume{{Name: "vol-c"}, {Name: "vol-a"}, {Name: "vol-b"}}
+	leases := map[string]int{"vol-a": 1, "vol-b": 0, "vol-c": 2}
+	assert.Equal(t, []string{"vol-b", "vol-a"}, leaseCandidates(volumes, leases, 2))
+	assert.Equal(t, []string{"vol-b"}, leaseCandidates(volumes, leases, 1))
+	assert.Empty(t, leaseCandidates(volumes, map[string]int{"vol-a": 1, "vol-b": 1, "vol-c": 1}, 1))
+}
+
+func TestSlotsPerZone(t *testing.T) {
+	volumes := []staticVolume{{Name: "vol-a", Zone: "dal10"}, {Name: "vol-b", Zone: "dal10"}, {Name: "vol-c"}}
+	leased, total := slotsPerZone(volumes, map[string]int{"vol-a": 2, "vol-b": 1}, 2)
+	assert.Equal(t, map[string]int{"dal10": 3, anyZone: 0}, leased)
+	assert.Equal(t, map[string]int{"dal10": 4, anyZone: 2}, total)
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/configmaps"
+
+	"github.com/spf13/viper"
+	v1core "k8s.io/api/core/v1"
+	v1resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	//label of the deployment request with the storage profile of the shared volume, the default profile if it is not set
+	storageProfileLabel = "storage_profile"
+	//name of the profile used by jobs without the label, defaults to defaultStorageProfileName
+	defaultStorageProfileKey  = "storage.default_profile"
+	defaultStorageProfileName = "default"
+
+	storageClassAnnotation  = "volume.beta.kubernetes.io/storage-class"
+	reclaimPolicyAnnotation = "ffdl.aisphere.io/reclaim-policy"
+)
+
+//storageProfilesConfigPath is mounted from the lcm-storage-profiles configmap, only the default profile exists if it is absent
+var storageProfilesConfigPath = "/etc/storage-profiles/profiles.yaml"
+
+//storageProfile ... how the dynamic shared volume of a training is provisioned
+type storageProfile struct {
+	Name         string `yaml:"name"`
+	StorageClass string `yaml:"storage_class"`
+	//AccessMode of the claim, learners and helper mount the volume at the same time so it defaults to ReadWriteMany
+	AccessMode v1core.PersistentVolumeAccessMode `yaml:"access_mode,omitempty"`
+	//Sizes the storage class provisions, requests are rounded up to the next one. Without sizes the requested
+	//storage is claimed as is, rounded up to MinSize and rejected above MaxSize
+	Sizes   []string `yaml:"sizes,omitempty"`
+	MinSize string   `yaml:"min_size,omitempty"`
+	MaxSize string   `yaml:"max_size,omitempty"`
+	//ClassAnnotation sets the storage class in the beta annotation instead of spec.storageClassName
+	ClassAnnotation bool `yaml:"class_annotation,omitempty"`
+	//ReclaimPolicy recorded on the claim and set on its bound volume before the claim is deleted. With Retain
+	//the volume of a training is kept after its claim is deleted and has to be cleaned up by the cluster admin
+	ReclaimPolicy v1core.PersistentVolumeReclaimPolicy `yaml:"reclaim_policy,omitempty"`
+	Annotations   map[string]string                    `yaml:"annotations,omitempty"`
+}
+
+// from https://github.ibm.com/alchemy-containers/armada-storage-file-plugin/blob/master/armada-storage-classes
+var defaultStorageProfile = storageProfile{
+	Name:            defaultStorageProfileName,
+	StorageClass:    "ibmc-file-gold",
+	AccessMode:      v1core.ReadWriteMany,
+	Sizes:           []string{"20Gi", "40Gi", "80Gi", "100Gi", "250Gi", "500Gi", "1Ti", "2Ti", "4Ti"},
+	ClassAnnotation: true,
+	ReclaimPolicy:   v1core.PersistentVolumeReclaimDelete,
+}
+
+//loadStorageProfiles reads the profiles from their configmap on every call, a profile in the configmap replaces the built-in one of the same name
+func loadStorageProfiles() map[string]storageProfile {
+	profiles := map[string]storageProfile{defaultStorageProfileName: defaultStorageProfile}
+
+	var config struct {
+		Profiles []storageProfile `yaml:"profiles"`
+	}
+	if !configmaps.Load(storageProfilesConfigPath, "storage profiles", &config) {
+		return profiles
+	}
+	for _, p := range config.Profiles {
+		if p.AccessMode == "" {
+			p.AccessMode = v1core.ReadWriteMany
+		}
+		profiles[p.Name] = p
+	}
+	return profiles
+}
+
+//lookupStorageProfile returns the named profile, the configured default profile if name is empty
+func lookupStorageProfile(name string) (storageProfile, error) {
+	if name == "" {
+		name = defaultStorageProfileName
+		if viper.IsSet(defaultStorageProfileKey) {
+			name = viper.GetString(defaultStorageProfileKey)
+		}
+	}
+	profile, ok := loadStorageProfiles()[name]
+	if !ok {
+		return storageProfile{}, fmt.Errorf("unknown storage profile %s", name)
+	}
+	return profile, nil
+}
+
+//storageProfileFor is the profile selected by the storage_profile label of the request
+func storageProfileFor(req *service.JobDeploymentRequest) (storageProfile, error) {
+	return lookupStorageProfile(req.Labels[storageProfileLabel])
+}
+
+//validateStorage rejects unknown storage profiles and storage the profile can not provision
+func validateStorage(req *service.JobDeploymentRequest) error {
+	profile, err := storageProfileFor(req)
+	if err != nil {
+		return err
+	}
+	if volumeSize := getStorageSize(req.Resources); volumeSize > 0 {
+		if _, err := profile.quantity(volumeSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return the storage quantity of the profile for the given volume size (specified in bytes).
+// This will round up to the nearest available quantity
+func (p storageProfile) quantity(volumeSize int64) (*v1resource.Quantity, error) {
+	requested := v1resource.NewQuantity(volumeSize, v1resource.BinarySI)
+	if len(p.Sizes) > 0 {
+		var match *v1resource.Quantity
+		for _, s := range p.Sizes {
+			q, err := v1resource.ParseQuantity(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size %s of storage profile %s: %s", s, p.Name, err)
+			}
+			if q.CmpInt64(volumeSize) >= 0 && (match == nil || q.Cmp(*match) < 0) {
+				match = &q
+			}
+		}
+		if match == nil {
+			return nil, fmt.Errorf("storage of %s is larger than the largest size of storage profile %s", requested, p.Name)
+		}
+		return match, nil
+	}
+
+	if p.MaxSize != "" {
+		maxSize, err := v1resource.ParseQuantity(p.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max size %s of storage profile %s: %s", p.MaxSize, p.Name, err)
+		}
+		if maxSize.CmpInt64(volumeSize) < 0 {
+			return nil, fmt.Errorf("storage of %s is larger than the max size %s of storage profile %s", requested, p.MaxSize, p.Name)
+		}
+	}
+	if p.MinSize != "" {
+		minSize, err := v1resource.ParseQuantity(p.MinSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min size %s of storage profile %s: %s", p.MinSize, p.Name, err)
+		}
+		if minSize.CmpInt64(volumeSize) > 0 {
+			return &minSize, nil
+		}
+	}
+	return requested, nil
+}
+
+//volumeClaim returns a PersistentVolumeClaim struct of the profile for the given volume size (specified in bytes)
+func (p storageProfile) volumeClaim(volumeSize int64) (*v1core.PersistentVolumeClaim, error) {
+	quantity, err := p.quantity(volumeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	claim := &v1core.PersistentVolumeClaim{
+		Spec: v1core.PersistentVolumeClaimSpec{
+			AccessModes: []v1core.PersistentVolumeAccessMode{p.AccessMode},
+			Resources: v1core.ResourceRequirements{
+				Requests: v1core.ResourceList{
+					v1core.ResourceStorage: *quantity,
+				},
+			},
+		},
+	}
+
+	annotations := make(map[string]string)
+	for k, v := range p.Annotations {
+		annotations[k] = v
+	}
+	if p.ReclaimPolicy != "" {
+		annotations[reclaimPolicyAnnotation] = string(p.ReclaimPolicy)
+	}
+	if p.StorageClass != "" {
+		if p.ClassAnnotation {
+			annotations[storageClassAnnotation] = p.StorageClass
+		} else {
+			class := p.StorageClass
+			claim.Spec.StorageClassName = &class
+		}
+	}
+	claim.Annotations = annotations
+	return claim, nil
+}
+
+//applyReclaimPolicy sets the reclaim policy recorded on the claim by its storage profile on the bound volume,
+//the provisioner creates volumes with the policy of the storage class which may differ from the profile
+func (s *lcmService) applyReclaimPolicy(claim v1core.PersistentVolumeClaim, logr *logger.LocLoggingEntry) {
+	policy := v1core.PersistentVolumeReclaimPolicy(claim.Annotations[reclaimPolicyAnnotation])
+	if policy == "" || claim.Spec.VolumeName == "" {
+		return
+	}
+	volume, err := s.k8sClient.CoreV1().PersistentVolumes().Get(claim.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		logr.WithError(err).Errorf(" Getting persistent volume '%s' of claim '%s' failed", claim.Spec.VolumeName, claim.Name)
+		return
+	}
+	if volume.Spec.PersistentVolumeReclaimPolicy == policy {
+		return
+	}
+	logr.Infof(" Setting reclaim policy %s on persistent volume '%s'", policy, volume.Name)
+	volume.Spec.PersistentVolumeReclaimPolicy = policy
+	if _, err := s.k8sClient.CoreV1().PersistentVolumes().Update(volume); err != nil {
+		logr.WithError(err).Errorf(" Setting reclaim policy on persistent volume '%s' failed", volume.Name)
+	}
+}
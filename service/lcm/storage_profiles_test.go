@@ -0,0 +1,117 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const gib = int64(1024 * 1024 * 1024)
+
+//withConfigMap mounts the content as the configmap read from configPath until the returned func is called
+func withConfigMap(t *testing.T, configPath *string, content string) func() {
+	restore, err := testutil.OverrideConfigMap(configPath, content)
+	assert.NoError(t, err)
+	return restore
+}
+
+func TestDefaultStorageProfile(t *testing.T) {
+	claim, err := GetVolumeClaim(30 * gib)
+	assert.NoError(t, err)
+	assert.Equal(t, "ibmc-file-gold", claim.Annotations[storageClassAnnotation])
+	assert.Nil(t, claim.Spec.StorageClassName)
+	assert.Equal(t, []v1core.PersistentVolumeAccessMode{v1core.ReadWriteMany}, claim.Spec.AccessModes)
+	storage := claim.Spec.Resources.Requests[v1core.ResourceStorage]
+	assert.Equal(t, "40Gi", storage.String())
+
+	_, err = GetVolumeClaim(5 * 1024 * gib)
+	assert.Error(t, err)
+}
+
+func TestConfiguredStorageProfile(t *testing.T) {
+	defer withConfigMap(t, &storageProfilesConfigPath, `
+profiles:
+- name: nfs
+  storage_class: nfs-client
+  min_size: 10Gi
+  max_size: 100Gi
+  reclaim_policy: Retain
+  annotations:
+    backup: "false"
+`)()
+
+	req := &service.JobDeploymentRequest{
+		TrainingId: "training-1",
+		Resources:  &service.ResourceRequirements{Storage: 5, StorageUnit: service.ResourceRequirements_GiB},
+		Labels:     map[string]string{storageProfileLabel: "nfs"},
+	}
+	assert.NoError(t, validateStorage(req))
+
+	profile, err := storageProfileFor(req)
+	assert.NoError(t, err)
+	claim, err := profile.volumeClaim(5 * gib)
+	assert.NoError(t, err)
+	assert.Equal(t, "nfs-client", *claim.Spec.StorageClassName)
+	assert.Empty(t, claim.Annotations[storageClassAnnotation])
+	assert.Equal(t, "Retain", claim.Annotations[reclaimPolicyAnnotation])
+	assert.Equal(t, "false", claim.Annotations["backup"])
+	assert.Equal(t, []v1core.PersistentVolumeAccessMode{v1core.ReadWriteMany}, claim.Spec.AccessModes)
+	storage := claim.Spec.Resources.Requests[v1core.ResourceStorage]
+	assert.Equal(t, "10Gi", storage.String())
+
+	claim, err = profile.volumeClaim(50 * gib)
+	assert.NoError(t, err)
+	storage = claim.Spec.Resources.Requests[v1core.ResourceStorage]
+	assert.Equal(t, "50Gi", storage.String())
+
+	req.Resources.Storage = 200
+	assert.Error(t, validateStorage(req))
+
+	req.Resources.Storage = 5
+	req.Labels[storageProfileLabel] = "missing"
+	assert.Error(t, validateStorage(req))
+
+	//the built-in default profile is still there
+	req.Labels[storageProfileLabel] = defaultStorageProfileName
+	assert.NoError(t, validateStorage(req))
+}
+
+func TestApplyReclaimPolicy(t *testing.T) {
+	claim, err := defaultStorageProfile.volumeClaim(30 * gib)
+	assert.NoError(t, err)
+	claim.Name = "training-1"
+	claim.Annotations[reclaimPolicyAnnotation] = string(v1core.PersistentVolumeReclaimRetain)
+	claim.Spec.VolumeName = "pvc-1"
+
+	s := &lcmService{k8sClient: fake.NewSimpleClientset(&v1core.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"},
+		Spec:       v1core.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: v1core.PersistentVolumeReclaimDelete},
+	})}
+	s.applyReclaimPolicy(*claim, logger.LocLogger(InitLogger("training-1", "user-1")))
+
+	volume, err := s.k8sClient.CoreV1().PersistentVolumes().Get("pvc-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, v1core.PersistentVolumeReclaimRetain, volume.Spec.PersistentVolumeReclaimPolicy)
+}
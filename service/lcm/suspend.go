@@ -0,0 +1,285 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/learner"
+
+	"github.com/cenkalti/backoff"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//the replicas a learner statefulset or the helper deployment had before the training was suspended
+const suspendedReplicasAnnotation = "ffdl.aisphere.io/suspended-replicas"
+
+//how long LCM waits for the controller to store the results of a training that is being suspended
+const suspendStoreTimeout = 60 * time.Minute
+
+//SuspendTrainingJob asks the controller to store checkpoints and logs of a training job, its learners and
+//helper are scaled to zero once they are stored. Volumes, secrets and etcd state are kept for ResumeTrainingJob
+func (s *lcmService) SuspendTrainingJob(ctx context.Context, req *service.JobSuspendRequest) (*service.JobSuspendResponse, error) {
+	logr := logger.LocLogger(InitLogger(req.TrainingId, req.UserId))
+	logr.Infof("Suspending training job: %s", req.TrainingId)
+
+	path := req.TrainingId + "/" + zkSuspend
+	success, err := s.etcdClient.PutIfKeyMissing(path, strconv.FormatInt(time.Now().Unix(), 10), logr)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to request suspend on path %s for training job %s", path, req.TrainingId)
+		return nil, err
+	}
+	if !success {
+		logr.Warnf("Training job %s is already suspended, path %s already exists", req.TrainingId, path)
+		return &service.JobSuspendResponse{}, nil
+	}
+
+	go s.scaleDownWhenStored(req.TrainingId, time.Now(), logr)
+	return &service.JobSuspendResponse{}, nil
+}
+
+//waits for the controller to report the results as stored (the STORING_ON_SUSPENDED state) and scales the training to zero.
+//Gives up suspendStoreTimeout after the suspend was requested and withdraws the suspend request, the training keeps running
+func (s *lcmService) scaleDownWhenStored(trainingID string, requested time.Time, logr *logger.LocLoggingEntry) {
+	path := trainingID + "/" + zkSuspended
+	waitForStore := backoff.NewExponentialBackOff()
+	waitForStore.MaxElapsedTime = suspendStoreTimeout - time.Since(requested)
+	waitForStore.MaxInterval = 30 * time.Second
+	if waitForStore.MaxElapsedTime <= 0 {
+		waitForStore.MaxElapsedTime = waitForStore.MaxInterval
+	}
+
+	err := backoff.Retry(func() error {
+		response, err := s.etcdClient.Get(path, logr)
+		if err != nil {
+			return err
+		}
+		if len(response) == 0 {
+			return fmt.Errorf("results of training job %s are not stored yet", trainingID)
+		}
+		return nil
+	}, waitForStore)
+	if err != nil {
+		logr.WithError(err).Errorf("Gave up waiting for training job %s to store its results, withdrawing the suspend request", trainingID)
+		//the controller moves back to PROCESSING once the suspend request is gone, so the training can be suspended again
+		for _, key := range []string{zkSuspend, zkSuspended} {
+			if _, err := s.etcdClient.DeleteKeyIfExists(trainingID+"/"+key, logr); err != nil {
+				logr.WithError(err).Errorf("Failed to delete %s for training job %s", key, trainingID)
+			}
+		}
+		return
+	}
+
+	if err := s.scaleSuspendedTraining(trainingID, false, logr); err != nil {
+		logr.WithError(err).Errorf("Failed to scale down suspended training job %s", trainingID)
+		return
+	}
+	logr.Infof("Training job %s is suspended", trainingID)
+}
+
+//reconcileSuspends resumes waiting for the trainings that were being suspended when LCM restarted, the scale down
+//of a training is only tracked in memory while the etcd suspend request outlives LCM
+func (s *lcmService) reconcileSuspends(logr *logger.LocLoggingEntry) {
+	sets, err := s.k8sClient.AppsV1beta1().StatefulSets(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: "training_id"})
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to list learners to reconcile suspended training jobs")
+		return
+	}
+
+	//a training is still waiting for the scale down while one of its statefulsets was not scaled to zero
+	pending := make(map[string]bool)
+	for _, set := range sets.Items {
+		trainingID := set.Labels["training_id"]
+		if _, isSuspended := set.Annotations[suspendedReplicasAnnotation]; !isSuspended {
+			pending[trainingID] = true
+		}
+	}
+
+	for trainingID := range pending {
+		response, err := s.etcdClient.Get(trainingID+"/"+zkSuspend, logr)
+		if err != nil {
+			logr.WithError(err).Errorf("Failed to check if training job %s is being suspended", trainingID)
+			continue
+		}
+		if len(response) == 0 {
+			continue
+		}
+		requested := time.Now()
+		if seconds, err := strconv.ParseInt(response[0].Value, 10, 64); err == nil {
+			requested = time.Unix(seconds, 0)
+		}
+		jobLogr := logger.LocLogger(InitLogger(trainingID, ""))
+		jobLogr.Infof("Resuming the suspend of training job %s requested at %s", trainingID, requested)
+		go s.scaleDownWhenStored(trainingID, requested, jobLogr)
+	}
+}
+
+//ResumeTrainingJob scales the learners and helper of a suspended training job back up, the learners restart from CHECKPOINT_DIR
+func (s *lcmService) ResumeTrainingJob(ctx context.Context, req *service.JobResumeRequest) (*service.JobResumeResponse, error) {
+	logr := logger.LocLogger(InitLogger(req.TrainingId, req.UserId))
+	logr.Infof("Resuming training job: %s", req.TrainingId)
+
+	response, err := s.etcdClient.Get(req.TrainingId+"/"+zkSuspended, logr)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to check if training job %s is suspended", req.TrainingId)
+		return nil, err
+	}
+	if len(response) == 0 {
+		return nil, gerrf(codes.FailedPrecondition, "training job %s is not suspended or is still storing its results", req.TrainingId)
+	}
+
+	//the training stays suspended until it is scaled up, so a failed resume can be retried
+	if err := s.scaleSuspendedTraining(req.TrainingId, true, logr); err != nil {
+		logr.WithError(err).Errorf("Failed to scale up resumed training job %s", req.TrainingId)
+		return nil, err
+	}
+
+	//the controller of the resumed helper moves on to PROCESSING once the suspend request is gone, the learners wait for it
+	for _, key := range []string{zkSuspend, zkSuspended} {
+		if _, err := s.etcdClient.DeleteKeyIfExists(req.TrainingId+"/"+key, logr); err != nil {
+			logr.WithError(err).Errorf("Failed to delete %s for training job %s", key, req.TrainingId)
+			return nil, err
+		}
+	}
+	return &service.JobResumeResponse{}, nil
+}
+
+//scaleSuspendedTraining scales all learner statefulsets and the helper of a training to zero, or back to the replicas they had before.
+//Non-split trainings run their helper containers in the learner pods and have no helper deployment
+func (s *lcmService) scaleSuspendedTraining(trainingID string, resume bool, logr *logger.LocLoggingEntry) error {
+	namespace := config.GetLearnerNamespace()
+	selector := metav1.ListOptions{LabelSelector: "training_id==" + trainingID}
+	sets, err := s.k8sClient.AppsV1beta1().StatefulSets(namespace).List(selector)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to list the learners of training job %s", trainingID)
+		return err
+	}
+
+	for _, set := range sets.Items {
+		name := set.Name
+		if err := backoff.RetryNotify(func() error {
+			set, err := s.k8sClient.AppsV1beta1().StatefulSets(namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if !suspendReplicas(&set.ObjectMeta, &set.Spec.Replicas, resume) {
+				return nil
+			}
+			if resume {
+				resumeFromCheckpoint(set.Spec.Template.Spec.Containers)
+			}
+			_, err = s.k8sClient.AppsV1beta1().StatefulSets(namespace).Update(set)
+			return err
+		}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+			logr.WithError(err).Errorf("Failed in scaling statefulset %s of suspended training", name)
+			k8sFailureCounter.With(component, "learner").Add(1)
+		}); err != nil {
+			return err
+		}
+	}
+
+	deployments, err := s.k8sClient.AppsV1beta1().Deployments(namespace).List(selector)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to list the helper of training job %s", trainingID)
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		//the job monitor carries the training_id label as well and keeps running
+		if !strings.HasPrefix(deployment.Name, "lhelper-") {
+			continue
+		}
+		helperName := deployment.Name
+		if err := backoff.RetryNotify(func() error {
+			helper, err := s.k8sClient.AppsV1beta1().Deployments(namespace).Get(helperName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if !suspendReplicas(&helper.ObjectMeta, &helper.Spec.Replicas, resume) {
+				return nil
+			}
+			_, err = s.k8sClient.AppsV1beta1().Deployments(namespace).Update(helper)
+			return err
+		}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+			logr.WithError(err).Errorf("Failed in scaling helper %s of suspended training", helperName)
+			k8sFailureCounter.With(component, "helper").Add(1)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//suspendReplicas records the replicas in an annotation and sets them to zero, or restores them on resume.
+//Returns false if there is nothing to change, e.g. a retried suspend of an object that is already suspended
+func suspendReplicas(meta *metav1.ObjectMeta, replicas **int32, resume bool) bool {
+	if resume {
+		previous, err := strconv.Atoi(meta.Annotations[suspendedReplicasAnnotation])
+		if err != nil {
+			return false
+		}
+		restored := int32(previous)
+		*replicas = &restored
+		delete(meta.Annotations, suspendedReplicasAnnotation)
+		return true
+	}
+
+	if _, isSuspended := meta.Annotations[suspendedReplicasAnnotation]; isSuspended {
+		return false
+	}
+	previous := int32(1)
+	if *replicas != nil {
+		previous = **replicas
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[suspendedReplicasAnnotation] = strconv.Itoa(int(previous))
+	zero := int32(0)
+	*replicas = &zero
+	return true
+}
+
+//resumeFromCheckpoint tells the learner containers that the training was resumed
+func resumeFromCheckpoint(containers []v1core.Container) {
+	for i := range containers {
+		if containers[i].Name != learnerContainerName {
+			continue
+		}
+		containers[i].Env = append(withoutEnvVar(containers[i].Env, learner.ResumeFromCheckpointEnvVar),
+			v1core.EnvVar{Name: learner.ResumeFromCheckpointEnvVar, Value: "true"})
+	}
+}
+
+func withoutEnvVar(envVars []v1core.EnvVar, name string) []v1core.EnvVar {
+	var filtered []v1core.EnvVar
+	for _, ev := range envVars {
+		if ev.Name != name {
+			filtered = append(filtered, ev)
+		}
+	}
+	return filtered
+}
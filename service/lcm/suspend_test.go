@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/api/apps/v1beta1"
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSuspendReplicas(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "learner-training-job1"}
+	three := int32(3)
+	replicas := &three
+
+	assert.True(t, suspendReplicas(&meta, &replicas, false))
+	assert.EqualValues(t, 0, *replicas)
+	assert.Equal(t, "3", meta.Annotations[suspendedReplicasAnnotation])
+
+	//suspending twice keeps the replicas of the first suspend
+	assert.False(t, suspendReplicas(&meta, &replicas, false))
+	assert.Equal(t, "3", meta.Annotations[suspendedReplicasAnnotation])
+
+	assert.True(t, suspendReplicas(&meta, &replicas, true))
+	assert.EqualValues(t, 3, *replicas)
+	assert.NotContains(t, meta.Annotations, suspendedReplicasAnnotation)
+	assert.False(t, suspendReplicas(&meta, &replicas, true))
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	containers := []v1core.Container{
+		{Name: learnerContainerName, Env: []v1core.EnvVar{{Name: "RESUME_FROM_CHECKPOINT", Value: "false"}, {Name: "TRAINING_ID", Value: "training-1"}}},
+		{Name: "log-collector"},
+	}
+	resumeFromCheckpoint(containers)
+	assert.Equal(t, []v1core.EnvVar{{Name: "TRAINING_ID", Value: "training-1"}, {Name: "RESUME_FROM_CHECKPOINT", Value: "true"}}, containers[0].Env)
+	assert.Empty(t, containers[1].Env)
+}
+
+func TestScaleSuspendedNonSplitTraining(t *testing.T) {
+	namespace := config.GetLearnerNamespace()
+	labels := map[string]string{"training_id": "training-1"}
+	two := int32(2)
+	one := int32(1)
+	learners := &v1beta1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "learner-job1", Namespace: namespace, Labels: labels}, Spec: v1beta1.StatefulSetSpec{Replicas: &two}}
+	jobMonitor := &v1beta1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "jobmonitor-job1", Namespace: namespace, Labels: labels}, Spec: v1beta1.DeploymentSpec{Replicas: &one}}
+	s := &lcmService{k8sClient: fake.NewSimpleClientset(learners, jobMonitor)}
+	logr := logger.LocLogger(InitLogger("training-1", "user-1"))
+
+	//non-split trainings have no helper deployment to scale
+	assert.NoError(t, s.scaleSuspendedTraining("training-1", false, logr))
+	set, err := s.k8sClient.AppsV1beta1().StatefulSets(namespace).Get("learner-job1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, *set.Spec.Replicas)
+	monitor, err := s.k8sClient.AppsV1beta1().Deployments(namespace).Get("jobmonitor-job1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, *monitor.Spec.Replicas)
+
+	assert.NoError(t, s.scaleSuspendedTraining("training-1", true, logr))
+	set, err = s.k8sClient.AppsV1beta1().StatefulSets(namespace).Get("learner-job1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, *set.Spec.Replicas)
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trainingjob
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+//Interface ... CRUD on TrainingJob objects of one namespace
+type Interface interface {
+	Create(job *TrainingJob) (*TrainingJob, error)
+	Get(name string) (*TrainingJob, error)
+	Update(job *TrainingJob) (*TrainingJob, error)
+	UpdateStatus(job *TrainingJob) (*TrainingJob, error)
+	Delete(name string, options *metav1.DeleteOptions) error
+	NewInformer(resyncPeriod time.Duration, handler cache.ResourceEventHandler) (cache.Store, cache.Controller)
+}
+
+type trainingJobs struct {
+	client    rest.Interface
+	namespace string
+}
+
+//NewForConfig creates a TrainingJob client for the namespace using the kubernetes config of lcm
+func NewForConfig(c *rest.Config, namespace string) (Interface, error) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	config := *c
+	config.GroupVersion = &SchemeGroupVersion
+	config.APIPath = "/apis"
+	config.ContentType = runtime.ContentTypeJSON
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: serializer.NewCodecFactory(scheme)}
+
+	client, err := rest.RESTClientFor(&config)
+	if err != nil {
+		return nil, err
+	}
+	return &trainingJobs{client: client, namespace: namespace}, nil
+}
+
+func (c *trainingJobs) Create(job *TrainingJob) (*TrainingJob, error) {
+	result := &TrainingJob{}
+	err := c.client.Post().
+		Namespace(c.namespace).
+		Resource(Plural).
+		Body(job).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *trainingJobs) Get(name string) (*TrainingJob, error) {
+	result := &TrainingJob{}
+	err := c.client.Get().
+		Namespace(c.namespace).
+		Resource(Plural).
+		Name(name).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *trainingJobs) Update(job *TrainingJob) (*TrainingJob, error) {
+	result := &TrainingJob{}
+	err := c.client.Put().
+		Namespace(c.namespace).
+		Resource(Plural).
+		Name(job.Name).
+		Body(job).
+		Do().
+		Into(result)
+	return result, err
+}
+
+//UpdateStatus writes the status subresource, spec changes in job are ignored by the api server
+func (c *trainingJobs) UpdateStatus(job *TrainingJob) (*TrainingJob, error) {
+	result := &TrainingJob{}
+	err := c.client.Put().
+		Namespace(c.namespace).
+		Resource(Plural).
+		Name(job.Name).
+		SubResource("status").
+		Body(job).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *trainingJobs) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.namespace).
+		Resource(Plural).
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+//NewInformer lists and watches all TrainingJobs of the namespace, every object is redelivered on resync
+func (c *trainingJobs) NewInformer(resyncPeriod time.Duration, handler cache.ResourceEventHandler) (cache.Store, cache.Controller) {
+	listWatch := cache.NewListWatchFromClient(c.client, Plural, c.namespace, fields.Everything())
+	return cache.NewInformer(listWatch, &TrainingJob{}, resyncPeriod, handler)
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trainingjob
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	//GroupName of the TrainingJob custom resource, must match the CRD in the helm chart
+	GroupName = "ffdl.aisphere.io"
+	//Version of the TrainingJob custom resource
+	Version = "v1alpha1"
+	//Kind of the custom resource
+	Kind = "TrainingJob"
+	//Plural is the resource name used in the REST paths (kubectl get trainingjobs)
+	Plural = "trainingjobs"
+)
+
+//SchemeGroupVersion ...
+var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: Version}
+
+var (
+	schemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	//AddToScheme ...
+	AddToScheme = schemeBuilder.AddToScheme
+)
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&TrainingJob{},
+		&TrainingJobList{},
+	)
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
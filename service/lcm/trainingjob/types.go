@@ -0,0 +1,157 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trainingjob
+
+import (
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/golang/protobuf/proto"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+//Phase of a training job as seen by the controller
+type Phase string
+
+const (
+	//PhasePending job was accepted but the controller did not pick it up yet
+	PhasePending Phase = "Pending"
+	//PhaseDeploying controller is creating the job monitor and learner BOM
+	PhaseDeploying Phase = "Deploying"
+	//PhaseRunning all kubernetes objects of the job have been created
+	PhaseRunning Phase = "Running"
+	//PhaseHalting halt was requested and handed over to the learners
+	PhaseHalting Phase = "Halting"
+	//PhaseHalted halt was requested before the job was deployed, nothing was deployed
+	PhaseHalted Phase = "Halted"
+	//PhaseFailed deployment failed, see ErrorCode
+	PhaseFailed Phase = "Failed"
+)
+
+//TrainingJob ... a training job deployed by LCM, spec carries the original deployment request
+type TrainingJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TrainingJobSpec   `json:"spec"`
+	Status TrainingJobStatus `json:"status,omitempty"`
+}
+
+//TrainingJobSpec ...
+type TrainingJobSpec struct {
+	DeploymentRequest *service.JobDeploymentRequest `json:"deploymentRequest"`
+	//Halt asks the learners to store results and stop, same as HaltTrainingJob
+	Halt bool `json:"halt,omitempty"`
+	//Kill tears down all the kubernetes objects of the job, same as KillTrainingJob
+	Kill bool `json:"kill,omitempty"`
+}
+
+//TrainingJobStatus ...
+type TrainingJobStatus struct {
+	Phase         Phase  `json:"phase,omitempty"`
+	LearnersReady int32  `json:"learnersReady"`
+	LearnersTotal int32  `json:"learnersTotal"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	Message       string `json:"message,omitempty"`
+	//Images maps the images of the job to the digests its pods are pinned to
+	Images map[string]string `json:"images,omitempty"`
+}
+
+//TrainingJobList ...
+type TrainingJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TrainingJob `json:"items"`
+}
+
+//DeepCopyInto ...
+func (in *TrainingJob) DeepCopyInto(out *TrainingJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+//DeepCopy ...
+func (in *TrainingJob) DeepCopy() *TrainingJob {
+	if in == nil {
+		return nil
+	}
+	out := new(TrainingJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+//DeepCopyObject ...
+func (in *TrainingJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+//DeepCopyInto ...
+func (in *TrainingJobSpec) DeepCopyInto(out *TrainingJobSpec) {
+	*out = *in
+	if in.DeploymentRequest != nil {
+		out.DeploymentRequest = proto.Clone(in.DeploymentRequest).(*service.JobDeploymentRequest)
+	}
+}
+
+//DeepCopyInto ...
+func (in *TrainingJobStatus) DeepCopyInto(out *TrainingJobStatus) {
+	*out = *in
+	if in.Images != nil {
+		out.Images = make(map[string]string, len(in.Images))
+		for image, digest := range in.Images {
+			out.Images[image] = digest
+		}
+	}
+}
+
+//DeepCopyInto ...
+func (in *TrainingJobList) DeepCopyInto(out *TrainingJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		out.Items = make([]TrainingJob, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+//DeepCopy ...
+func (in *TrainingJobList) DeepCopy() *TrainingJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(TrainingJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+//DeepCopyObject ...
+func (in *TrainingJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
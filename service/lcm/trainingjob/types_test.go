@@ -0,0 +1,52 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trainingjob
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeepCopyDoesNotShareDeploymentRequest(t *testing.T) {
+	job := &TrainingJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "training-1", Labels: map[string]string{"training_id": "training-1"}},
+		Spec: TrainingJobSpec{
+			DeploymentRequest: &service.JobDeploymentRequest{
+				Name:       "job-1",
+				TrainingId: "training-1",
+				Labels:     map[string]string{"deploy_zone": "dal10"},
+			},
+		},
+		Status: TrainingJobStatus{Phase: PhasePending, Images: map[string]string{"learner:1.0": "sha256:aaa"}},
+	}
+
+	copied := job.DeepCopy()
+	copied.Spec.DeploymentRequest.Labels["deploy_zone"] = "dal12"
+	copied.Labels["training_id"] = "training-2"
+	copied.Status.Phase = PhaseRunning
+	copied.Status.Images["learner:1.0"] = "sha256:bbb"
+
+	assert.Equal(t, "dal10", job.Spec.DeploymentRequest.Labels["deploy_zone"])
+	assert.Equal(t, "training-1", job.Labels["training_id"])
+	assert.Equal(t, PhasePending, job.Status.Phase)
+	assert.Equal(t, "sha256:aaa", job.Status.Images["learner:1.0"])
+	assert.Equal(t, "job-1", copied.Spec.DeploymentRequest.Name)
+}
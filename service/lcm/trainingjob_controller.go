@@ -0,0 +1,379 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/AISphere/ffdl-lcm/service/lcm/trainingjob"
+	"github.com/AISphere/ffdl-trainer/client"
+	"github.com/AISphere/ffdl-trainer/trainer/grpc_trainer_v2"
+	"github.com/cenkalti/backoff"
+	"golang.org/x/net/context"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	//when enabled DeployTrainingJob only creates a TrainingJob resource and the controller deploys it
+	trainingJobCRDModeKey = "trainingjob.crd.enabled"
+
+	trainingJobResyncPeriod = 1 * time.Minute
+	trainingJobWorkers      = 4
+
+	//keeps a deleted TrainingJob around until the controller deleted the kubernetes objects and etcd keys of its training
+	trainingJobFinalizer = trainingjob.GroupName + "/teardown"
+)
+
+//reconciles TrainingJob resources into the job monitor and learner BOM of a training job
+type trainingJobController struct {
+	s        *lcmService
+	jobs     trainingjob.Interface
+	queue    workqueue.RateLimitingInterface
+	informer cache.Controller
+	logr     *logger.LocLoggingEntry
+}
+
+func newTrainingJobController(s *lcmService, jobs trainingjob.Interface) *trainingJobController {
+	c := &trainingJobController{
+		s:     s,
+		jobs:  jobs,
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), trainingjob.Plural),
+		logr:  logger.LocLogger(logger.LogServiceBasic(logger.LogkeyLcmService)),
+	}
+	_, c.informer = jobs.NewInformer(trainingJobResyncPeriod, cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueue,
+		UpdateFunc: func(old, new interface{}) {
+			c.enqueue(new)
+		},
+	})
+	return c
+}
+
+//run blocks until stopCh is closed
+func (c *trainingJobController) run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	go c.informer.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, c.informer.HasSynced) {
+		c.logr.Errorf("timed out waiting for the TrainingJob cache to sync")
+		return
+	}
+
+	c.logr.Infof("TrainingJob controller started with %d workers", trainingJobWorkers)
+	for i := 0; i < trainingJobWorkers; i++ {
+		go c.runWorker()
+	}
+	<-stopCh
+	c.logr.Infof("TrainingJob controller stopped")
+}
+
+func (c *trainingJobController) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		c.logr.WithError(err).Errorf("could not compute the key of TrainingJob %v", obj)
+		return
+	}
+	c.queue.Add(key)
+}
+
+//the workqueue makes sure a key is never processed by two workers at the same time
+func (c *trainingJobController) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *trainingJobController) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.reconcile(key.(string)); err != nil {
+		c.logr.WithError(err).Warnf("reconciling TrainingJob %s failed, requeuing", key)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *trainingJobController) reconcile(key string) error {
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil
+	}
+
+	//always work on the latest version from the api server, the informer cache may lag behind our own status updates
+	job, err := c.jobs.Get(name)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if job.DeletionTimestamp != nil {
+		return c.teardown(job)
+	}
+	req := job.Spec.DeploymentRequest
+	if req == nil {
+		c.logr.Errorf("TrainingJob %s has no deployment request, ignoring it", name)
+		return nil
+	}
+	logr := logger.LocLogger(InitLogger(req.TrainingId, req.UserId))
+
+	if !hasFinalizer(job) {
+		//resources created before the finalizer was introduced or with kubectl
+		job.Finalizers = append(job.Finalizers, trainingJobFinalizer)
+		_, err := c.jobs.Update(job)
+		return err
+	}
+
+	if job.Spec.Kill {
+		logr.Infof("kill requested for TrainingJob %s, deleting it", name)
+		backgroundPropagation := metav1.DeletePropagationBackground
+		err := c.jobs.Delete(name, &metav1.DeleteOptions{PropagationPolicy: &backgroundPropagation})
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if job.Spec.Halt && (job.Status.Phase == "" || job.Status.Phase == trainingjob.PhasePending) {
+		return c.haltPending(job, logr)
+	}
+	if job.Spec.Halt && job.Status.Phase != trainingjob.PhaseHalting && job.Status.Phase != trainingjob.PhaseFailed && job.Status.Phase != trainingjob.PhaseHalted {
+		if err := c.s.haltTrainingJob(req.TrainingId, logr); err != nil {
+			return err
+		}
+		job.Status.Phase = trainingjob.PhaseHalting
+		_, err := c.jobs.UpdateStatus(job)
+		return err
+	}
+
+	switch job.Status.Phase {
+	case "", trainingjob.PhasePending:
+		return c.deploy(job, logr)
+	case trainingjob.PhaseDeploying:
+		//lcm went away in the middle of the deployment, start over from a clean slate
+		logr.Warnf("TrainingJob %s was left in phase %s, redeploying it", name, job.Status.Phase)
+		counter := finishedTrainingCounter.With(outcome, killed)
+		if err := c.s.deleteTrainingJobResources(req.Name, req.TrainingId, counter, logr); err != nil {
+			return err
+		}
+		//objects that are still terminating would be taken for the ones of the new deployment
+		c.s.waitForTeardown(req.TrainingId, logr)
+		return c.deploy(job, logr)
+	case trainingjob.PhaseRunning, trainingjob.PhaseHalting:
+		return c.updateLearnersReady(job)
+	}
+	return nil
+}
+
+//haltPending ends a training that was halted before the controller deployed it, there are no learners to store results
+func (c *trainingJobController) haltPending(job *trainingjob.TrainingJob, logr *logger.LocLoggingEntry) error {
+	req := job.Spec.DeploymentRequest
+	logr.Infof("halt requested for TrainingJob %s before it was deployed, not deploying it", job.Name)
+	if err := updateJobStatus(req.TrainingId, grpc_trainer_v2.Status_HALTED, req.UserId, service.StatusMessages_NORMAL_OPERATION.String(), client.ErrCodeNormal, logr); err != nil {
+		return err
+	}
+	c.s.forgetDeploymentRequest(req.TrainingId, logr)
+	job.Status.Phase = trainingjob.PhaseHalted
+	_, err := c.jobs.UpdateStatus(job)
+	return err
+}
+
+func (c *trainingJobController) deploy(job *trainingjob.TrainingJob, logr *logger.LocLoggingEntry) error {
+	//the resource holds the request without its credentials, see saveRequestCredentials
+	req, restoreErr := c.s.restoreRequestCredentials(job.Spec.DeploymentRequest, logr)
+	if _, rejected := restoreErr.(*deploymentRejectedError); restoreErr != nil && !rejected {
+		return restoreErr
+	}
+
+	job.Status.Phase = trainingjob.PhaseDeploying
+	job.Status.LearnersTotal = int32(learnerPods(req))
+	job.Status.LearnersReady = 0
+	job, err := c.jobs.UpdateStatus(job)
+	if err != nil {
+		return err
+	}
+
+	//resources created directly in the cluster did not go through the checks of DeployTrainingJob
+	failedComponent := "deployment request"
+	if restoreErr != nil {
+		failedComponent, err = "request credentials", restoreErr
+	} else if err = c.s.validateDeploymentRequest(req, logr); err != nil {
+		err = rejectDeployment(client.ErrInvalidResourceSpecs, err)
+	} else {
+		failedComponent, err = c.s.deployTrainingJobComponents(context.Background(), req, logr)
+	}
+	if err != nil {
+		errorCode := deploymentErrorCode(err)
+		if c.s.redeployAfterFailure(req.TrainingId, errorCode, fmt.Sprintf("%s failed: %s", failedComponent, err.Error()), logr) {
+			return nil
+		}
+		handleDeploymentFailure(c.s, req.Name, req.TrainingId, req.UserId, failedComponent, errorCode, logr)
+		job.Status.Phase = trainingjob.PhaseFailed
+		job.Status.ErrorCode = errorCode
+		job.Status.Message = fmt.Sprintf("%s failed: %s", failedComponent, err.Error())
+	} else {
+		job.Status.Phase = trainingjob.PhaseRunning
+		job.Status.Images = c.s.imageDigests(req.TrainingId, logr)
+	}
+
+	//the deployment itself is not repeated if only the status update fails, keep trying
+	return backoff.RetryNotify(func() error {
+		latest, err := c.jobs.Get(job.Name)
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		latest.Status = job.Status
+		_, err = c.jobs.UpdateStatus(latest)
+		return err
+	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed to update the status of TrainingJob %s to %s", job.Name, job.Status.Phase)
+	})
+}
+
+//sums up the ready learners of all statefulsets of the training, there is one per replica group.
+//The total follows the deployment request, which is updated when an elastic training is scaled
+func (c *trainingJobController) updateLearnersReady(job *trainingjob.TrainingJob) error {
+	selector := "training_id==" + job.Spec.DeploymentRequest.TrainingId
+	sets, err := c.s.k8sClient.AppsV1beta1().StatefulSets(config.GetLearnerNamespace()).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	var ready int32
+	for _, set := range sets.Items {
+		ready += set.Status.ReadyReplicas
+	}
+	total := int32(learnerPods(job.Spec.DeploymentRequest))
+	if ready == job.Status.LearnersReady && total == job.Status.LearnersTotal {
+		return nil
+	}
+	job.Status.LearnersReady = ready
+	job.Status.LearnersTotal = total
+	_, err = c.jobs.UpdateStatus(job)
+	return err
+}
+
+//a deleted TrainingJob takes all the kubernetes objects and etcd keys of its training job with it, the finalizer is only
+//removed once they are all gone so the teardown is repeated after a failure or an lcm restart
+func (c *trainingJobController) teardown(job *trainingjob.TrainingJob) error {
+	if !hasFinalizer(job) {
+		return nil
+	}
+	if req := job.Spec.DeploymentRequest; req != nil {
+		logr := logger.LocLogger(InitLogger(req.TrainingId, req.UserId))
+		logr.Infof("TrainingJob %s was deleted, killing training job: %s", job.Name, req.Name)
+
+		counter := finishedTrainingCounter.With(outcome, killed)
+		counter.With(progress, started).Add(1)
+		c.s.forgetDeploymentRequest(req.TrainingId, logr)
+		if err := c.s.deleteTrainingJobResources(req.Name, req.TrainingId, counter, logr); err != nil {
+			return err
+		}
+	}
+
+	var finalizers []string
+	for _, finalizer := range job.Finalizers {
+		if finalizer != trainingJobFinalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	job.Finalizers = finalizers
+	_, err := c.jobs.Update(job)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func hasFinalizer(job *trainingjob.TrainingJob) bool {
+	for _, finalizer := range job.Finalizers {
+		if finalizer == trainingJobFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+//creates the TrainingJob resource which is picked up by the controller
+func (s *lcmService) createTrainingJob(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) error {
+	job := &trainingjob.TrainingJob{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       trainingjob.Kind,
+			APIVersion: trainingjob.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: req.TrainingId,
+			Labels: map[string]string{
+				"training_id": req.TrainingId,
+				"user_id":     req.UserId,
+			},
+			Finalizers: []string{trainingJobFinalizer},
+		},
+		Spec: trainingjob.TrainingJobSpec{
+			DeploymentRequest: req,
+		},
+		Status: trainingjob.TrainingJobStatus{
+			Phase:         trainingjob.PhasePending,
+			LearnersTotal: int32(learnerPods(req)),
+		},
+	}
+
+	return backoff.RetryNotify(func() error {
+		_, err := s.trainingJobs.Create(job)
+		if k8serrors.IsAlreadyExists(err) {
+			logr.WithError(err).Warnf("TrainingJob %s already exists", job.Name)
+			return nil
+		}
+		return err
+	}, k8sInteractionBackoff(), func(err error, window time.Duration) {
+		logr.WithError(err).Errorf("Failed to create TrainingJob %s", job.Name)
+		k8sFailureCounter.With(component, "trainingjob").Add(1)
+	})
+}
+
+//applies mutate to the spec of the TrainingJob of trainingID, retrying on conflicting writes of the controller
+func (s *lcmService) updateTrainingJobSpec(trainingID string, mutate func(spec *trainingjob.TrainingJobSpec)) error {
+	var err error
+	for i := 0; i < numRetries; i++ {
+		var job *trainingjob.TrainingJob
+		job, err = s.trainingJobs.Get(trainingID)
+		if err != nil {
+			return err
+		}
+		mutate(&job.Spec)
+		if _, err = s.trainingJobs.Update(job); !k8serrors.IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
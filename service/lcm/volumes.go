@@ -18,69 +18,14 @@
 package lcm
 
 import (
-	"errors"
-
 	v1core "k8s.io/api/core/v1"
-	v1resource "k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// from https://github.ibm.com/alchemy-containers/armada-storage-file-plugin/blob/master/armada-storage-classes
-var supportedVolumeSizes = []v1resource.Quantity{
-	v1resource.MustParse("20Gi"),
-	v1resource.MustParse("20Gi"),
-	v1resource.MustParse("40Gi"),
-	v1resource.MustParse("80Gi"),
-	v1resource.MustParse("100Gi"),
-	v1resource.MustParse("250Gi"),
-	v1resource.MustParse("500Gi"),
-	v1resource.MustParse("1Ti"),
-	v1resource.MustParse("2Ti"),
-	v1resource.MustParse("4Ti"),
-}
-
-// GetVolumeClaim returns a PersistentVolumeClaim struct for the given volume size (specified in bytes).
+// GetVolumeClaim returns a PersistentVolumeClaim struct of the default storage profile for the given volume size (specified in bytes).
 func GetVolumeClaim(volumeSize int64) (*v1core.PersistentVolumeClaim, error) {
-	quantity := getStorageQuantity(volumeSize)
-	if quantity == nil {
-		return nil, errors.New("Unable to find matching storage quantity")
-	}
-
-	class := getStorageClass(volumeSize)
-	if class == "" {
-		return nil, errors.New("Unable to find matching storage class")
-	}
-
-	claim := &v1core.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{
-			Annotations: map[string]string{
-				"volume.beta.kubernetes.io/storage-class": class,
-			},
-		},
-		Spec: v1core.PersistentVolumeClaimSpec{
-			Resources: v1core.ResourceRequirements{
-				Requests: v1core.ResourceList{
-					v1core.ResourceStorage: *quantity,
-				},
-			},
-		},
-	}
-	return claim, nil
-}
-
-// Return the storage class for the given volume size.
-func getStorageClass(volumeSize int64) string {
-	return "ibmc-file-gold"
-}
-
-// Return the storage quantity for the given volume size.
-// This will round up to the nearest available quantity
-func getStorageQuantity(volumeSize int64) *v1resource.Quantity {
-	for _, q := range supportedVolumeSizes {
-		if q.CmpInt64(volumeSize) >= 0 {
-			// Found a match
-			return &q
-		}
+	profile, err := lookupStorageProfile("")
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return profile.volumeClaim(volumeSize)
 }
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/AISphere/ffdl-commons/config"
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/spf13/viper"
+
+	v1core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//zoneNodeLabel is the zone of a node, deploy_zone refers to its values
+const zoneNodeLabel = "failure-domain.beta.kubernetes.io/zone"
+
+//gpuTypeNodeLabel is the gpu type of a node, the learners of a training only run on nodes of its gpu type
+const gpuTypeNodeLabel = "ibm-cloud.kubernetes.io/gpu-type"
+
+//requiredGPUs is the number of gpus all learners of a training request together, an elastic training can grow to its max learners
+func requiredGPUs(req *service.JobDeploymentRequest) float64 {
+	if len(req.ReplicaGroups) == 0 {
+		learners := totalLearners(req)
+		if _, maxLearners, elastic := elasticBounds(req); elastic {
+			learners = maxLearners
+		}
+		return float64(learners) * req.GetResources().GetGpus()
+	}
+	var gpus float64
+	for _, g := range req.ReplicaGroups {
+		gpus += float64(g.Replicas) * replicaGroupRequest(req, g).GetResources().GetGpus()
+	}
+	return gpus
+}
+
+//freeGPUsPerZone ... the gpus allocatable minus the gpus requested by the pods in the learner namespace, like getResources, per zone.
+//Only nodes of gpuType count, any node if it is empty
+func (s *lcmService) freeGPUsPerZone(gpuType string, logr *logger.LocLoggingEntry) (map[string]float64, error) {
+	nodes, err := s.k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := s.k8sClient.CoreV1().Pods(config.GetLearnerNamespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	resourceGPU := gpuResourceName()
+	nodeZones := make(map[string]string)
+	freeGPUs := make(map[string]float64)
+	for _, node := range nodes.Items {
+		zone := node.Labels[zoneNodeLabel]
+		if zone == "" || (gpuType != "" && node.Labels[gpuTypeNodeLabel] != gpuType) {
+			continue
+		}
+		nodeZones[node.Name] = zone
+		gpuQty := node.Status.Allocatable[resourceGPU]
+		gpu, _ := strconv.ParseFloat(gpuQty.AsDec().String(), 64)
+		freeGPUs[zone] += gpu
+	}
+
+	for _, pod := range pods.Items {
+		zone, scheduled := nodeZones[pod.Spec.NodeName]
+		if !scheduled || pod.Status.Phase == v1core.PodSucceeded || pod.Status.Phase == v1core.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			gpuQty := container.Resources.Requests[resourceGPU]
+			gpu, _ := strconv.ParseFloat(gpuQty.AsDec().String(), 64)
+			freeGPUs[zone] -= gpu
+		}
+	}
+	logr.Debugf("free gpus per zone: %v", freeGPUs)
+	return freeGPUs, nil
+}
+
+//freeSlotsPerZone ... the static volume slots that are not leased per zone, nil if a volume without zone fits any zone
+func freeSlotsPerZone(volumes []staticVolume, leases map[string]int, maxJobs int) map[string]float64 {
+	freeSlots := make(map[string]float64)
+	for _, vol := range volumes {
+		if vol.Zone == "" {
+			return nil
+		}
+		free := 0
+		if leases[vol.Name] < maxJobs {
+			free = maxJobs - leases[vol.Name]
+		}
+		freeSlots[vol.Zone] += float64(free)
+	}
+	return freeSlots
+}
+
+//freeStaticVolumeSlotsPerZone ... like leaseStaticVolume looks at the usable static volumes, nil if they do not tie trainings to zones
+func (s *lcmService) freeStaticVolumeSlotsPerZone(logr *logger.LocLoggingEntry) (map[string]float64, error) {
+	volumes := loadStaticVolumes(logr)
+	if len(volumes) == 0 || viper.GetBool(config.LcmFluentdEmetricsEnable) {
+		return nil, nil
+	}
+	usable := usableStaticVolumes(volumes, "", viper.GetString(sharedVolumeStorageClassKey))
+	leases, err := s.staticVolumeLeases(usable, logr)
+	if err != nil {
+		return nil, err
+	}
+	freeSlots := freeSlotsPerZone(usable, leases, staticVolumeMaxJobs())
+	logr.Debugf("free static volume slots per zone: %v", freeSlots)
+	return freeSlots, nil
+}
+
+//pickZone is the zone with the most free capacity among the candidate zones, all zones if there are no candidates.
+//If any zone fits the training this one does
+func pickZone(free map[string]float64, candidates map[string]bool) string {
+	var zones []string
+	for zone := range free {
+		if len(candidates) == 0 || candidates[zone] {
+			zones = append(zones, zone)
+		}
+	}
+	if len(zones) == 0 {
+		return ""
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if free[zones[i]] != free[zones[j]] {
+			return free[zones[i]] > free[zones[j]]
+		}
+		return zones[i] < zones[j]
+	})
+	return zones[0]
+}
+
+//selectDeployZone picks a zone for a training that was submitted without a deploy_zone, empty if the nodes have no zones.
+//The zone has a static volume slot left if the static volumes have zones, trainings without gpus go to the zone with the
+//most free slots and are not tied to a zone otherwise
+func (s *lcmService) selectDeployZone(req *service.JobDeploymentRequest, logr *logger.LocLoggingEntry) string {
+	freeSlots, err := s.freeStaticVolumeSlotsPerZone(logr)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to get the free static volume slots per zone, not selecting a zone for training job %s", req.TrainingId)
+		return ""
+	}
+	volumeZones := make(map[string]bool)
+	for zone, slots := range freeSlots {
+		if slots > 0 {
+			volumeZones[zone] = true
+		}
+	}
+	if len(freeSlots) > 0 && len(volumeZones) == 0 {
+		//the lease fails in any zone, the training fails as soon as it tries
+		logr.Warnf("No zone has a free static volume slot for training job %s", req.TrainingId)
+	}
+
+	gpusRequired := requiredGPUs(req)
+	if gpusRequired == 0 {
+		return pickZone(freeSlots, volumeZones)
+	}
+
+	freeGPUs, err := s.freeGPUsPerZone(req.GetResources().GetGpuType(), logr)
+	if err != nil {
+		logr.WithError(err).Errorf("Failed to get the free gpus per zone, not selecting a zone for training job %s", req.TrainingId)
+		return ""
+	}
+	zone := pickZone(freeGPUs, volumeZones)
+	if zone != "" && freeGPUs[zone] < gpusRequired {
+		logr.Warnf("No zone has %.0f free %s gpus for training job %s, it waits for gpus in zone %s", gpusRequired, req.GetResources().GetGpuType(), req.TrainingId, zone)
+	}
+	return zone
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017-2018 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcm
+
+import (
+	"testing"
+
+	"github.com/AISphere/ffdl-commons/logger"
+	"github.com/AISphere/ffdl-lcm/service"
+	"github.com/stretchr/testify/assert"
+
+	v1core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPickZone(t *testing.T) {
+	freeGPUs := map[string]float64{"dal10": 2, "dal12": 8, "dal13": 4}
+	assert.Equal(t, "dal12", pickZone(freeGPUs, nil))
+	assert.Equal(t, "dal13", pickZone(freeGPUs, map[string]bool{"dal10": true, "dal13": true}))
+	assert.Equal(t, "", pickZone(freeGPUs, map[string]bool{"fra02": true}))
+	assert.Equal(t, "", pickZone(map[string]float64{}, nil))
+
+	//ties go to the first zone by name so the choice is stable
+	assert.Equal(t, "dal10", pickZone(map[string]float64{"dal13": 4, "dal10": 4}, nil))
+}
+
+func TestRequiredGPUs(t *testing.T) {
+	req := &service.JobDeploymentRequest{
+		Framework: "tensorflow",
+		Version:   "1.5",
+		Resources: &service.ResourceRequirements{Gpus: 2, Learners: 3},
+	}
+	assert.Equal(t, float64(6), requiredGPUs(req))
+
+	req.ReplicaGroups = []*service.ReplicaGroup{
+		{Name: "ps", Replicas: 2, Resources: &service.ResourceRequirements{Gpus: 0}},
+		{Name: "worker", Replicas: 4},
+	}
+	assert.Equal(t, float64(8), requiredGPUs(req))
+}
+
+func TestFreeSlotsPerZone(t *testing.T) {
+	volumes := []staticVolume{{Name: "pv-1", Zone: "dal10"}, {Name: "pv-2", Zone: "dal10"}, {Name: "pv-3", Zone: "dal12"}}
+	freeSlots := freeSlotsPerZone(volumes, map[string]int{"pv-1": 2, "pv-2": 1, "pv-3": 2}, 2)
+	assert.Equal(t, map[string]float64{"dal10": 1, "dal12": 0}, freeSlots)
+	assert.Equal(t, "dal10", pickZone(freeSlots, nil))
+
+	//a volume without zone can be leased in any zone
+	assert.Nil(t, freeSlotsPerZone(append(volumes, staticVolume{Name: "pv-4"}), map[string]int{}, 2))
+}
+
+func TestFreeGPUsPerZoneOfGPUType(t *testing.T) {
+	node := func(name, zone, gpuType string, gpus int64) *v1core.Node {
+		return &v1core.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{zoneNodeLabel: zone, gpuTypeNodeLabel: gpuType}},
+			Status:     v1core.NodeStatus{Allocatable: v1core.ResourceList{gpuResourceName(): *resource.NewQuantity(gpus, resource.DecimalSI)}},
+		}
+	}
+	s := &lcmService{k8sClient: fake.NewSimpleClientset(
+		node("node-1", "dal10", "nvidia-TeslaK80", 8),
+		node("node-2", "dal12", "nvidia-TeslaP100", 4),
+		node("node-3", "dal12", "nvidia-TeslaK80", 2),
+	)}
+	logr := logger.LocLogger(InitLogger("training-1", "user-1"))
+
+	freeGPUs, err := s.freeGPUsPerZone("nvidia-TeslaP100", logr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"dal12": 4}, freeGPUs)
+
+	freeGPUs, err = s.freeGPUsPerZone("", logr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"dal10": 8, "dal12": 6}, freeGPUs)
+}